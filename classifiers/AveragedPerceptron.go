@@ -0,0 +1,314 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package classifiers
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"github.com/broosaction/gotext/tokenizers"
+	"github.com/broosaction/gotext/utils/persist"
+	"log"
+	"math"
+	"math/rand"
+	"strings"
+)
+
+type FeatureTemplate uint8
+
+const (
+	// every word, "w=rain"
+	FT_Unigrams FeatureTemplate = 1 << iota
+	// every pair of adjacent words, "b=will rain"
+	FT_Bigrams
+	// the first AffixLength letters of every word, "p=rai"
+	FT_Prefixes
+	// the last AffixLength letters of every word, "s=ain"
+	FT_Suffixes
+)
+
+/**
+ * Averaged Perceptron
+ *
+ * An online multiclass perceptron over sparse string features. Learn updates
+ * the model right away: when the current weights get the class of a text
+ * wrong, the weights of its features move 1 towards the right class and 1
+ * away from the wrong guess. Classify uses the average of the weights over
+ * all the Learn calls, which is far more stable than the last weights. The
+ * average is kept lazily so a Learn call costs only the features of its text.
+ *
+ * Texts are turned into features by Templates. LearnFeatures and
+ * ClassifyFeatures take features built by the caller, for example the
+ * context of a word for a POS tagger.
+ *
+ * @category    Machine Learning
+
+  **usage
+	p := classifiers.NewAveragedPerceptron()
+
+	p.Learn("amazing, awesome movie!! Yeah!! Oh boy.", "positive")
+	p.Learn("terrible, shitty thing. Damn. Sucks!!", "negative")
+
+	fmt.Println(p.Classify("awesome, cool shitty thing"))
+*/
+type AveragedPerceptron struct {
+	// name of the tokenizer, see tokenizers.GetTokenizer
+	Tokenizer string
+
+	// the features taken from a text
+	Templates FeatureTemplate
+
+	// length of the prefixes and suffixes
+	AffixLength int
+
+	// The number of passes over the texts of LearnBatch, shuffled between passes.
+	Epochs int
+
+	// Seed of the LearnBatch shuffling.
+	Seed int64
+
+	// The known classes, in the order they were first learned.
+	Classes []string
+
+	// The current weights by feature and class.
+	Weights map[string]map[string]float64
+
+	// The sum of the weights over all Learn calls up to the last change of
+	// each weight, and the Learn call at which that was.
+	Totals map[string]map[string]float64
+	Stamps map[string]map[string]int
+
+	// The number of Learn calls so far.
+	Instances int
+}
+
+func NewAveragedPerceptron() *AveragedPerceptron {
+	tokenizer := tokenizers.DefaultTokenizer{}
+	return &AveragedPerceptron{
+		Tokenizer:   tokenizer.GetName(),
+		Templates:   FT_Unigrams | FT_Bigrams,
+		AffixLength: 3,
+		Epochs:      5,
+		Weights:     map[string]map[string]float64{},
+		Totals:      map[string]map[string]float64{},
+		Stamps:      map[string]map[string]int{},
+	}
+}
+
+func (p *AveragedPerceptron) getMeta() (string, string) {
+	return "AveragedPerceptron", "01"
+}
+
+// Features returns the template features of a text, plus a bias feature.
+func (p *AveragedPerceptron) Features(text string) []string {
+	words := tokenizers.GetTokenizer(p.Tokenizer).Tokenize(strings.ToLower(text))
+	features := []string{"bias"}
+	for i, w := range words {
+		if p.Templates&FT_Unigrams != 0 {
+			features = append(features, "w="+w)
+		}
+		if p.Templates&FT_Bigrams != 0 && i > 0 {
+			features = append(features, "b="+words[i-1]+" "+w)
+		}
+		runes := []rune(w)
+		if p.Templates&FT_Prefixes != 0 && len(runes) > p.AffixLength {
+			features = append(features, "p="+string(runes[:p.AffixLength]))
+		}
+		if p.Templates&FT_Suffixes != 0 && len(runes) > p.AffixLength {
+			features = append(features, "s="+string(runes[len(runes)-p.AffixLength:]))
+		}
+	}
+	return features
+}
+
+// Learn updates the model with a single labelled text.
+func (p *AveragedPerceptron) Learn(text, class string) {
+	p.LearnFeatures(p.Features(text), class)
+}
+
+// LearnBatch learns the texts Epochs times, in a new random order every time.
+func (p *AveragedPerceptron) LearnBatch(texts []string, labels []string) error {
+	if len(texts) != len(labels) {
+		return errNotEqualDataLength
+	}
+	features := make([][]string, len(texts))
+	for i, text := range texts {
+		features[i] = p.Features(text)
+	}
+	rng := rand.New(rand.NewSource(p.Seed))
+	order := rng.Perm(len(texts))
+	for epoch := 0; epoch < p.Epochs; epoch++ {
+		for _, i := range order {
+			p.LearnFeatures(features[i], labels[i])
+		}
+		rng.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+	}
+	return nil
+}
+
+// LearnFeatures updates the model with the features of a single example.
+func (p *AveragedPerceptron) LearnFeatures(features []string, class string) {
+	classIndex(&p.Classes, class)
+	p.Instances++
+	guess, _ := p.best(p.scores(features, false))
+	if guess == class {
+		return
+	}
+	for _, f := range features {
+		p.update(f, class, 1)
+		if guess != "" {
+			p.update(f, guess, -1)
+		}
+	}
+}
+
+// update changes a weight, first adding its value since its last change to its total.
+// The maps are made on first use, for a zero AveragedPerceptron or one loaded without weights.
+func (p *AveragedPerceptron) update(feature, class string, delta float64) {
+	if p.Weights == nil {
+		p.Weights = map[string]map[string]float64{}
+	}
+	if p.Totals == nil {
+		p.Totals = map[string]map[string]float64{}
+	}
+	if p.Stamps == nil {
+		p.Stamps = map[string]map[string]int{}
+	}
+	if p.Weights[feature] == nil {
+		p.Weights[feature] = map[string]float64{}
+	}
+	if p.Totals[feature] == nil {
+		p.Totals[feature] = map[string]float64{}
+	}
+	if p.Stamps[feature] == nil {
+		p.Stamps[feature] = map[string]int{}
+	}
+	w := p.Weights[feature][class]
+	p.Totals[feature][class] += float64(p.Instances-p.Stamps[feature][class]) * w
+	p.Stamps[feature][class] = p.Instances
+	p.Weights[feature][class] = w + delta
+}
+
+// average of a weight over all the Learn calls so far.
+func (p *AveragedPerceptron) average(feature, class string) float64 {
+	w := p.Weights[feature][class]
+	if p.Instances == 0 {
+		return w
+	}
+	total := p.Totals[feature][class] + float64(p.Instances-p.Stamps[feature][class])*w
+	return total / float64(p.Instances)
+}
+
+func (p *AveragedPerceptron) scores(features []string, averaged bool) map[string]float64 {
+	scores := make(map[string]float64, len(p.Classes))
+	for _, class := range p.Classes {
+		scores[class] = 0
+	}
+	for _, f := range features {
+		for class := range p.Weights[f] {
+			if averaged {
+				scores[class] += p.average(f, class)
+			} else {
+				scores[class] += p.Weights[f][class]
+			}
+		}
+	}
+	return scores
+}
+
+// best class, the first learned one on ties so the result doesn't depend on map order.
+func (p *AveragedPerceptron) best(scores map[string]float64) (string, float64) {
+	chosen, top := "", math.Inf(-1)
+	for _, class := range p.Classes {
+		if scores[class] > top {
+			chosen, top = class, scores[class]
+		}
+	}
+	if chosen == "" {
+		return "", 0
+	}
+	return chosen, top
+}
+
+// Scores returns the averaged score of every class for the features.
+func (p *AveragedPerceptron) Scores(features []string) map[string]float64 {
+	return p.scores(features, true)
+}
+
+// ClassifyFeatures returns the best class for the features and its averaged score.
+func (p *AveragedPerceptron) ClassifyFeatures(features []string) (string, float64) {
+	return p.best(p.scores(features, true))
+}
+
+/**
+ * Determine what class `text` belongs to, and its averaged score. An
+ * untrained model returns an empty class.
+ */
+func (p *AveragedPerceptron) Classify(text string) (string, float64) {
+	return p.ClassifyFeatures(p.Features(text))
+}
+
+// Fit is LearnBatch.
+func (p *AveragedPerceptron) Fit(texts []string, labels []string) error {
+	return p.LearnBatch(texts, labels)
+}
+
+// Predict is Classify.
+func (p *AveragedPerceptron) Predict(text string) (string, error) {
+	class, _ := p.Classify(text)
+	if class == "" {
+		return "", ErrNotClassified
+	}
+	return class, nil
+}
+
+//save to a file
+func (p *AveragedPerceptron) Save(file string) error {
+
+	buf := new(bytes.Buffer)
+	encoder := gob.NewEncoder(buf)
+
+	err := encoder.Encode(p)
+	if err != nil {
+		return fmt.Errorf("error encoding model: %s", err)
+	}
+
+	name, version := p.getMeta()
+	persist.Save(file, persist.Modeldata{
+		Data:    buf.Bytes(),
+		Name:    name,
+		Version: version,
+	})
+	return nil
+}
+
+// Load from the output file, learning can go on where it stopped.
+func (p *AveragedPerceptron) Load(filePath string) error {
+	log.Printf("Loading Classifier from %s...", filePath)
+	meta := persist.Load(filePath)
+	//get the classifier current meta data
+	name, version := p.getMeta()
+	if meta.Name != name {
+		return fmt.Errorf("This file doesn't contain an AveragedPerceptron classifier")
+	}
+	if meta.Version != version {
+		return fmt.Errorf("Can't understand this file format")
+	}
+
+	// decode into a fresh one, gob leaves the fields saved as zero values untouched
+	var loaded AveragedPerceptron
+	decoder := gob.NewDecoder(bytes.NewBuffer(meta.Data))
+	err := decoder.Decode(&loaded)
+	if err != nil {
+		return fmt.Errorf("error decoding checkpoint file: %s", err)
+	}
+	*p = loaded
+
+	checkpointFile = filePath
+	return nil
+}
@@ -0,0 +1,92 @@
+package classifiers
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestAveragedPerceptronClassify(t *testing.T) {
+	p := NewAveragedPerceptron()
+	p.Templates |= FT_Prefixes | FT_Suffixes
+	if err := p.LearnBatch(textTrain, textLabels); err != nil {
+		t.Fatal(err)
+	}
+	for text, want := range textTest {
+		if got, score := p.Classify(text); got != want {
+			t.Errorf("%q: got %s (%f), want %s", text, got, score, want)
+		}
+	}
+	if got, _ := NewAveragedPerceptron().Classify("play a song"); got != "" {
+		t.Errorf("untrained model chose %q", got)
+	}
+}
+
+func TestAveragedPerceptronAverage(t *testing.T) {
+	p := NewAveragedPerceptron()
+	p.LearnFeatures([]string{"a"}, "x")
+	p.LearnFeatures([]string{"a"}, "y")
+	p.LearnFeatures([]string{"a"}, "y")
+	p.LearnFeatures([]string{"a"}, "y")
+
+	// the weight of a for y became 1 at call 2, when x was guessed, and
+	// stayed so through calls 3 and 4 which were right
+	if got := p.Scores([]string{"a"})["y"]; got != 0.5 {
+		t.Errorf("got average %f, want 0.5", got)
+	}
+}
+
+func TestAveragedPerceptronSaveLoad(t *testing.T) {
+	p := NewAveragedPerceptron()
+	p.LearnBatch(textTrain, textLabels)
+	file := filepath.Join(t.TempDir(), "perceptron.joi")
+	if err := p.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	// load over a model of other words, it may not score them any more
+	loaded := NewAveragedPerceptron()
+	loaded.LearnBatch([]string{"cats are good", "dogs are bad"}, []string{"pos", "neg"})
+	if err := loaded.Load(file); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := loaded.Weights["w=cats"]; ok || !reflect.DeepEqual(loaded.Classes, p.Classes) {
+		t.Errorf("the model loaded over kept its weights or classes %v", loaded.Classes)
+	}
+	for text := range textTest {
+		want, s := p.Classify(text)
+		got, r := loaded.Classify(text)
+		if got != want || s != r {
+			t.Errorf("%q: got %s %f, want %s %f", text, got, r, want, s)
+		}
+	}
+	p.Learn("skip to the next track", "music")
+	loaded.Learn("skip to the next track", "music")
+	if p.Instances != loaded.Instances || p.average("w=skip", "music") != loaded.average("w=skip", "music") {
+		t.Error("learning after Load diverged")
+	}
+}
+
+func TestAveragedPerceptronZeroValue(t *testing.T) {
+	var p AveragedPerceptron
+	p.Epochs = 5
+	p.Tokenizer = NewAveragedPerceptron().Tokenizer
+	p.Templates = FT_Unigrams
+	if err := p.LearnBatch(textTrain, textLabels); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := p.Classify("play some jazz"); got != "music" {
+		t.Errorf("got %s", got)
+	}
+
+	// a model saved before learning anything has no maps once loaded
+	file := filepath.Join(t.TempDir(), "empty.joi")
+	if err := NewAveragedPerceptron().Save(file); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewAveragedPerceptron()
+	if err := loaded.Load(file); err != nil {
+		t.Fatal(err)
+	}
+	loaded.Learn("play some jazz", "music")
+	loaded.Learn("will it rain", "weather")
+}
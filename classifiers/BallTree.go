@@ -0,0 +1,160 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package classifiers
+
+import "math"
+
+/**
+ * Ball Tree
+ *
+ * A metric tree where every node is a ball, a center and the radius that
+ * encloses all of its samples. By the triangle inequality no sample of a ball
+ * can be closer to the query than dist(query, center) - radius, so whole balls
+ * are skipped once they can't beat the current k-th neighbor. Unlike the
+ * KD-tree it does not split on single dimensions, which keeps it useful for
+ * data with many dimensions.
+ *
+ * @category    Machine Learning
+ */
+type BallTree struct {
+	samples [][]float64
+	metric  distanceMetric
+	root    *ballNode
+}
+
+type ballNode struct {
+	center []float64
+	radius float64
+
+	// sample positions held by a leaf
+	points      []int
+	left, right *ballNode
+}
+
+func newBallTree(samples [][]float64, m distanceMetric, leafSize int) *BallTree {
+	points := make([]int, len(samples))
+	for i := range points {
+		points[i] = i
+	}
+	t := &BallTree{samples: samples, metric: m}
+	if len(points) > 0 {
+		t.root = t.build(points, leafSize)
+	}
+	return t
+}
+
+func (t *BallTree) build(points []int, leafSize int) *ballNode {
+	n := &ballNode{center: make([]float64, len(t.samples[points[0]]))}
+	for _, p := range points {
+		for d, v := range t.samples[p] {
+			n.center[d] += v
+		}
+	}
+	for d := range n.center {
+		n.center[d] /= float64(len(points))
+	}
+
+	// the sample farthest from the center seeds the first child
+	var a int
+	for _, p := range points {
+		if d := t.metric.dist(n.center, t.samples[p]); d > n.radius {
+			a, n.radius = p, d
+		}
+	}
+	if len(points) <= leafSize || n.radius == 0 {
+		n.points = points
+		return n
+	}
+
+	// and the sample farthest from that one seeds the second
+	b, farthest := a, -1.0
+	for _, p := range points {
+		if d := t.metric.dist(t.samples[a], t.samples[p]); d > farthest {
+			b, farthest = p, d
+		}
+	}
+
+	var left, right []int
+	for _, p := range points {
+		if t.metric.dist(t.samples[a], t.samples[p]) <= t.metric.dist(t.samples[b], t.samples[p]) {
+			left = append(left, p)
+		} else {
+			right = append(right, p)
+		}
+	}
+	if len(left) == 0 || len(right) == 0 {
+		n.points = points
+		return n
+	}
+	n.left = t.build(left, leafSize)
+	n.right = t.build(right, leafSize)
+	return n
+}
+
+// lowerBound is the smallest distance any sample of the ball can have to the query.
+func (t *BallTree) lowerBound(n *ballNode, query []float64) float64 {
+	return math.Max(0, t.metric.dist(query, n.center)-n.radius)
+}
+
+// Nearest returns the k samples closest to the query.
+func (t *BallTree) Nearest(query []float64, k int) []Neighbor {
+	c := &candidates{k: k}
+	if k > 0 && t.root != nil {
+		t.nearest(t.root, query, c)
+	}
+	return c.sorted()
+}
+
+func (t *BallTree) nearest(n *ballNode, query []float64, c *candidates) {
+	if n.left == nil {
+		for _, p := range n.points {
+			c.offer(p, t.metric.dist(query, t.samples[p]))
+		}
+		return
+	}
+
+	// visit the closer ball first so the other one is more likely to be pruned
+	near, far := n.left, n.right
+	nearBound, farBound := t.lowerBound(near, query), t.lowerBound(far, query)
+	if farBound < nearBound {
+		near, far = far, near
+		nearBound, farBound = farBound, nearBound
+	}
+	if nearBound <= c.worst() {
+		t.nearest(near, query, c)
+	}
+	if farBound <= c.worst() {
+		t.nearest(far, query, c)
+	}
+}
+
+// Within returns every sample at most radius away from the query.
+func (t *BallTree) Within(query []float64, radius float64) []Neighbor {
+	var result []Neighbor
+	if t.root != nil {
+		t.within(t.root, query, radius, &result)
+	}
+	sortNeighbors(result)
+	return result
+}
+
+func (t *BallTree) within(n *ballNode, query []float64, radius float64, result *[]Neighbor) {
+	if t.lowerBound(n, query) > radius {
+		return
+	}
+	if n.left == nil {
+		for _, p := range n.points {
+			if d := t.metric.dist(query, t.samples[p]); d <= radius {
+				*result = append(*result, Neighbor{Idx: p, Dist: d})
+			}
+		}
+		return
+	}
+	t.within(n.left, query, radius, result)
+	t.within(n.right, query, radius, result)
+}
@@ -0,0 +1,422 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package classifiers
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"github.com/broosaction/gotext/utils/persist"
+	"log"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+type SplitCriterionType uint8
+
+const (
+	// the chance that two samples drawn from the node have different labels
+	SCT_Gini SplitCriterionType = iota
+	// the Shannon entropy of the labels in the node, in bits
+	SCT_Entropy
+)
+
+/**
+ * Decision Tree
+ *
+ * A CART classification tree over numeric features. Every inner node sends
+ * a sample left when one feature is at most a threshold and right otherwise,
+ * picking the feature and threshold that make the children purest. A leaf
+ * predicts the label distribution of the training samples that reached it.
+ * Since only the order of the values matters, features need no scaling.
+ *
+ * @category    Machine Learning
+
+  **usage
+	tree := classifiers.NewDecisionTree()
+	tree.MaxDepth = 5
+	tree.LearnBatch(train, labels)
+	res, err := tree.Classify(test)
+*/
+type DecisionTree struct {
+	// How the purity of a node is measured.
+	Criterion SplitCriterionType
+
+	// The deepest a leaf can be, the root being at depth 0. 0 means no limit.
+	MaxDepth int
+
+	// The fewest training samples a leaf can hold.
+	MinSamplesLeaf int
+
+	// The number of features, picked at random, a node looks at for its
+	// split. 0 means all of them.
+	MaxFeatures int
+
+	// Seed of the feature sampling.
+	Seed int64
+
+	// The labels, sorted, a node counts them in this order.
+	Classes []string
+
+	// The nodes, the root is the first one.
+	Nodes []TreeNode
+
+	// How much each feature decreased the impurity over the whole tree, summing to 1.
+	Importances []float64
+
+	// The number of features of the training samples.
+	Dim int
+}
+
+// TreeNode is a node of a DecisionTree.
+type TreeNode struct {
+	// The feature tested and the threshold it is compared with, Feature is -1 for a leaf.
+	Feature   int
+	Threshold float64
+
+	// Positions of the children in Nodes.
+	Left, Right int
+
+	// The number of training samples of every class that reached the node.
+	Counts []float64
+}
+
+func NewDecisionTree() *DecisionTree {
+	return &DecisionTree{
+		Criterion:      SCT_Gini,
+		MinSamplesLeaf: 1,
+	}
+}
+
+func (t *DecisionTree) getMeta() (string, string) {
+	return "DecisionTree", "01"
+}
+
+// LearnBatch grows the tree on the samples and their labels.
+func (t *DecisionTree) LearnBatch(train [][]float64, label []string) error {
+	if len(train) != len(label) {
+		return fmt.Errorf("%d samples but %d labels: %w", len(train), len(label), errNotEqualDataLength)
+	}
+	if err := checkSamples(train, nil, 1); err != nil {
+		return err
+	}
+	classes, y := encodeLabels(label)
+	all := make([]int, len(train))
+	for i := range all {
+		all[i] = i
+	}
+	t.fit(train, y, classes, all, rand.New(rand.NewSource(t.Seed)))
+	return nil
+}
+
+// encodeLabels returns the sorted distinct labels and the position of every label among them.
+func encodeLabels(labels []string) ([]string, []int) {
+	seen := make(map[string]int)
+	for _, l := range labels {
+		seen[l] = 0
+	}
+	classes := make([]string, 0, len(seen))
+	for l := range seen {
+		classes = append(classes, l)
+	}
+	sort.Strings(classes)
+	for i, c := range classes {
+		seen[c] = i
+	}
+	y := make([]int, len(labels))
+	for i, l := range labels {
+		y[i] = seen[l]
+	}
+	return classes, y
+}
+
+// fit grows the tree on the given samples, which may repeat as in a bootstrap sample.
+func (t *DecisionTree) fit(train [][]float64, y []int, classes []string, samples []int, rng *rand.Rand) {
+	t.Classes = classes
+	t.Dim = len(train[0])
+	t.Nodes = t.Nodes[:0]
+	t.Importances = make([]float64, t.Dim)
+	b := &treeBuilder{tree: t, train: train, y: y, rng: rng, total: float64(len(samples))}
+	b.grow(samples, 0)
+
+	var sum float64
+	for _, v := range t.Importances {
+		sum += v
+	}
+	if sum > 0 {
+		for f := range t.Importances {
+			t.Importances[f] /= sum
+		}
+	}
+}
+
+type treeBuilder struct {
+	tree  *DecisionTree
+	train [][]float64
+	y     []int
+	rng   *rand.Rand
+	total float64
+}
+
+// grow adds the node for the samples and, unless it is a leaf, its children. It returns its position.
+func (b *treeBuilder) grow(samples []int, depth int) int {
+	t := b.tree
+	counts := make([]float64, len(t.Classes))
+	for _, i := range samples {
+		counts[b.y[i]]++
+	}
+	at := len(t.Nodes)
+	t.Nodes = append(t.Nodes, TreeNode{Feature: -1, Counts: counts})
+
+	impurity := t.impurity(counts, float64(len(samples)))
+	if impurity == 0 || (t.MaxDepth > 0 && depth >= t.MaxDepth) || len(samples) < 2*t.minLeaf() {
+		return at
+	}
+	feature, threshold, gain := b.bestSplit(samples, impurity)
+	if feature < 0 {
+		return at
+	}
+	var left, right []int
+	for _, i := range samples {
+		if b.train[i][feature] <= threshold {
+			left = append(left, i)
+		} else {
+			right = append(right, i)
+		}
+	}
+	t.Importances[feature] += gain * float64(len(samples)) / b.total
+
+	// the children are appended after this node, so set them through the index
+	l := b.grow(left, depth+1)
+	r := b.grow(right, depth+1)
+	t.Nodes[at].Feature, t.Nodes[at].Threshold = feature, threshold
+	t.Nodes[at].Left, t.Nodes[at].Right = l, r
+	return at
+}
+
+// bestSplit returns the feature and threshold that decrease the impurity
+// most, and by how much. The feature is -1 when no split helps.
+func (b *treeBuilder) bestSplit(samples []int, impurity float64) (int, float64, float64) {
+	t := b.tree
+	features := b.rng.Perm(t.Dim)
+	if t.MaxFeatures > 0 && t.MaxFeatures < t.Dim {
+		features = features[:t.MaxFeatures]
+	}
+	n := float64(len(samples))
+	minLeaf := t.minLeaf()
+
+	bestFeature, bestThreshold, bestGain := -1, 0.0, 1e-12
+	sorted := append([]int(nil), samples...)
+	for _, f := range features {
+		sort.SliceStable(sorted, func(i, j int) bool {
+			return b.train[sorted[i]][f] < b.train[sorted[j]][f]
+		})
+		left := make([]float64, len(t.Classes))
+		right := make([]float64, len(t.Classes))
+		for _, i := range sorted {
+			right[b.y[i]]++
+		}
+		for k := 0; k < len(sorted)-1; k++ {
+			left[b.y[sorted[k]]]++
+			right[b.y[sorted[k]]]--
+			x, next := b.train[sorted[k]][f], b.train[sorted[k+1]][f]
+			if x == next || k+1 < minLeaf || len(sorted)-k-1 < minLeaf {
+				continue
+			}
+			nl, nr := float64(k+1), float64(len(sorted)-k-1)
+			gain := impurity - (nl*t.impurity(left, nl)+nr*t.impurity(right, nr))/n
+			if gain > bestGain {
+				bestFeature, bestThreshold, bestGain = f, x+(next-x)/2, gain
+			}
+		}
+	}
+	return bestFeature, bestThreshold, bestGain
+}
+
+func (t *DecisionTree) minLeaf() int {
+	if t.MinSamplesLeaf < 1 {
+		return 1
+	}
+	return t.MinSamplesLeaf
+}
+
+func (t *DecisionTree) impurity(counts []float64, n float64) float64 {
+	if n == 0 {
+		return 0
+	}
+	var impurity float64
+	switch t.Criterion {
+	case SCT_Entropy:
+		for _, c := range counts {
+			if c > 0 {
+				p := c / n
+				impurity -= p * math.Log2(p)
+			}
+		}
+	default:
+		impurity = 1
+		for _, c := range counts {
+			p := c / n
+			impurity -= p * p
+		}
+	}
+	return impurity
+}
+
+// leaf returns the leaf a single test vector ends up in.
+func (t *DecisionTree) leaf(test []float64) (*TreeNode, error) {
+	if len(t.Nodes) == 0 {
+		return nil, errNoSamples
+	}
+	if len(test) != t.Dim {
+		return nil, fmt.Errorf("the test vector has %d dimensions, the samples have %d: %w", len(test), t.Dim, errNotEqualDataLength)
+	}
+	node := &t.Nodes[0]
+	for node.Feature >= 0 {
+		if test[node.Feature] <= node.Threshold {
+			node = &t.Nodes[node.Left]
+		} else {
+			node = &t.Nodes[node.Right]
+		}
+	}
+	return node, nil
+}
+
+// distribution returns the share of every class in the leaf of a single test vector.
+func (t *DecisionTree) distribution(test []float64) ([]float64, error) {
+	node, err := t.leaf(test)
+	if err != nil {
+		return nil, err
+	}
+	var n float64
+	for _, c := range node.Counts {
+		n += c
+	}
+	p := make([]float64, len(node.Counts))
+	for c, count := range node.Counts {
+		p[c] = count / n
+	}
+	return p, nil
+}
+
+/**
+ * Probabilities of every label for each test vector, the share of the
+ * training samples of its leaf that had it.
+ */
+func (t *DecisionTree) Probabilities(test [][]float64) ([]map[string]float64, error) {
+	result := make([]map[string]float64, len(test))
+	for j, _test := range test {
+		p, err := t.distribution(_test)
+		if err != nil {
+			return nil, fmt.Errorf("test data %d: %w", j, err)
+		}
+		result[j] = labelMap(t.Classes, p)
+	}
+	return result, nil
+}
+
+// Classify returns the most frequent label of the leaf of every test vector.
+func (t *DecisionTree) Classify(test [][]float64) ([]string, error) {
+	result := make([]string, len(test))
+	for j, _test := range test {
+		p, err := t.distribution(_test)
+		if err != nil {
+			return nil, fmt.Errorf("test data %d: %w", j, err)
+		}
+		result[j] = t.Classes[argmax(p)]
+	}
+	return result, nil
+}
+
+// FeatureImportances returns how much each feature decreased the impurity, summing to 1.
+func (t *DecisionTree) FeatureImportances() []float64 {
+	return t.Importances
+}
+
+func labelMap(classes []string, p []float64) map[string]float64 {
+	m := make(map[string]float64, len(classes))
+	for c, class := range classes {
+		if p[c] > 0 {
+			m[class] = p[c]
+		}
+	}
+	return m
+}
+
+// argmax returns the first position of the largest value.
+func argmax(values []float64) int {
+	best := 0
+	for i, v := range values {
+		if v > values[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+// Fit is LearnBatch.
+func (t *DecisionTree) Fit(samples [][]float64, labels []string) error {
+	return t.LearnBatch(samples, labels)
+}
+
+// Predict is Classify.
+func (t *DecisionTree) Predict(samples [][]float64) ([]string, error) {
+	return t.Classify(samples)
+}
+
+// PredictProba is Probabilities.
+func (t *DecisionTree) PredictProba(samples [][]float64) ([]map[string]float64, error) {
+	return t.Probabilities(samples)
+}
+
+//save to a file
+func (t *DecisionTree) Save(file string) error {
+
+	buf := new(bytes.Buffer)
+	encoder := gob.NewEncoder(buf)
+
+	err := encoder.Encode(t)
+	if err != nil {
+		return fmt.Errorf("error encoding model: %s", err)
+	}
+
+	name, version := t.getMeta()
+	persist.Save(file, persist.Modeldata{
+		Data:    buf.Bytes(),
+		Name:    name,
+		Version: version,
+	})
+	return nil
+}
+
+// Load from the output file.
+func (t *DecisionTree) Load(filePath string) error {
+	log.Printf("Loading Classifier from %s...", filePath)
+	meta := persist.Load(filePath)
+	//get the classifier current meta data
+	name, version := t.getMeta()
+	if meta.Name != name {
+		return fmt.Errorf("This file doesn't contain a DecisionTree classifier")
+	}
+	if meta.Version != version {
+		return fmt.Errorf("Can't understand this file format")
+	}
+
+	// decode into a fresh one, gob leaves the fields saved as zero values untouched
+	var loaded DecisionTree
+	decoder := gob.NewDecoder(bytes.NewBuffer(meta.Data))
+	err := decoder.Decode(&loaded)
+	if err != nil {
+		return fmt.Errorf("error decoding checkpoint file: %s", err)
+	}
+	*t = loaded
+
+	checkpointFile = filePath
+	return nil
+}
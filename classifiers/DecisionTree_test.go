@@ -0,0 +1,132 @@
+package classifiers
+
+import (
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDecisionTree(t *testing.T) {
+	for _, criterion := range []SplitCriterionType{SCT_Gini, SCT_Entropy} {
+		tree := NewDecisionTree()
+		tree.Criterion = criterion
+		if err := tree.LearnBatch(irisTrain, irisLabels); err != nil {
+			t.Fatal(err)
+		}
+		res, err := tree.Classify(irisTrain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if acc := accuracy(t, res, irisLabels); acc != 1 {
+			t.Errorf("criterion %d: training accuracy %f", criterion, acc)
+		}
+	}
+
+	stump := NewDecisionTree()
+	stump.MaxDepth = 1
+	stump.LearnBatch(irisTrain, irisLabels)
+	if len(stump.Nodes) != 3 {
+		t.Errorf("a stump has %d nodes", len(stump.Nodes))
+	}
+
+	leafy := NewDecisionTree()
+	leafy.MinSamplesLeaf = 4
+	leafy.LearnBatch(irisTrain, irisLabels)
+	for _, node := range leafy.Nodes {
+		var n float64
+		for _, c := range node.Counts {
+			n += c
+		}
+		if n < 4 {
+			t.Errorf("leaf with %f samples", n)
+		}
+	}
+
+	if _, err := leafy.Classify([][]float64{{1}}); err == nil {
+		t.Error("expected a dimension error")
+	}
+}
+
+func TestDecisionTreeFitPredict(t *testing.T) {
+	train, labels := xorData(400, 1)
+	test, testLabels := xorData(200, 2)
+
+	tree := NewDecisionTree()
+	if err := tree.Fit(train, labels); err != nil {
+		t.Fatal(err)
+	}
+	res, err := tree.Predict(test)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acc := accuracy(t, res, testLabels); acc < 0.9 {
+		t.Errorf("test accuracy %f", acc)
+	}
+
+	probs, err := tree.PredictProba(test)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, p := range probs {
+		if math.Abs(p["same"]+p["different"]-1) > 1e-9 {
+			t.Fatalf("probabilities %v", p)
+		}
+		if p[res[i]] < 0.5 {
+			t.Errorf("predicted %s with a probability of %f", res[i], p[res[i]])
+		}
+	}
+
+	// a second Fit replaces the tree
+	tree.Fit(irisTrain, irisLabels)
+	if tree.Dim != len(irisTrain[0]) || !reflect.DeepEqual(tree.Classes, []string{"Setosa", "Versicolor", "Virginica"}) {
+		t.Errorf("refitted tree has dimension %d and classes %v", tree.Dim, tree.Classes)
+	}
+}
+
+func TestDecisionTreeFeatureImportances(t *testing.T) {
+	train, labels := xorData(400, 1)
+	tree := NewDecisionTree()
+	tree.LearnBatch(train, labels)
+
+	imp := tree.FeatureImportances()
+	if len(imp) != 3 {
+		t.Fatalf("%d importances", len(imp))
+	}
+	if math.Abs(imp[0]+imp[1]+imp[2]-1) > 1e-9 {
+		t.Errorf("importances sum to %f", imp[0]+imp[1]+imp[2])
+	}
+	if imp[2] > imp[0] || imp[2] > imp[1] {
+		t.Errorf("the noise feature is the most important: %v", imp)
+	}
+}
+
+func TestDecisionTreeSaveLoad(t *testing.T) {
+	train, labels := xorData(100, 1)
+	tree := NewDecisionTree()
+	tree.LearnBatch(train, labels)
+	file := filepath.Join(t.TempDir(), "tree.joi")
+	if err := tree.Save(file); err != nil {
+		t.Fatal(err)
+	}
+
+	// load over a deeper tree of another problem, nothing of it may remain
+	loaded := NewDecisionTree()
+	loaded.MaxDepth = 2
+	loaded.LearnBatch(irisTrain, irisLabels)
+	if err := loaded.Load(file); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded, tree) {
+		t.Errorf("loaded tree differs:\n%+v\n%+v", loaded, tree)
+	}
+	want, _ := tree.Probabilities(train)
+	got, err := loaded.Probabilities(train)
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded tree predicts differently: %v", err)
+	}
+
+	if err := NewRandomForest(1).Load(file); err == nil {
+		t.Error("expected an error loading a tree as a forest")
+	}
+}
@@ -0,0 +1,279 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package classifiers
+
+import (
+	"errors"
+	"math"
+)
+
+var (
+	errSingularCovariance = errors.New("the covariance of the samples can't be inverted")
+)
+
+//MinkowskiDistance is the p-norm of the weighted difference, p = 1 is the
+//Manhattan distance and p = 2 the Euclidean distance.
+func MinkowskiDistance(X, Y, W []float64, p float64) float64 {
+	var sum float64
+	for i := range X {
+		sum += W[i] * math.Pow(math.Abs(X[i]-Y[i]), p)
+	}
+	return math.Pow(sum, 1/p)
+}
+
+//ChebyshevDistance is the largest weighted difference on any single dimension.
+func ChebyshevDistance(X, Y, W []float64) float64 {
+	var max float64
+	for i := range X {
+		max = math.Max(max, W[i]*math.Abs(X[i]-Y[i]))
+	}
+	return max
+}
+
+//CosineDistance is 1 - the weighted cosine similarity, it ignores the length
+//of the vectors. A zero vector is at distance 1 from everything.
+func CosineDistance(X, Y, W []float64) float64 {
+	var dot, normX, normY float64
+	for i := range X {
+		dot += W[i] * X[i] * Y[i]
+		normX += W[i] * X[i] * X[i]
+		normY += W[i] * Y[i] * Y[i]
+	}
+	if normX == 0 || normY == 0 {
+		return 1
+	}
+	return 1 - dot/(math.Sqrt(normX)*math.Sqrt(normY))
+}
+
+//HammingDistance is the weighted share of the dimensions on which X and Y differ.
+func HammingDistance(X, Y, W []float64) float64 {
+	var differ, total float64
+	for i := range X {
+		if X[i] != Y[i] {
+			differ += W[i]
+		}
+		total += W[i]
+	}
+	if total == 0 {
+		return 0
+	}
+	return differ / total
+}
+
+//MahalanobisDistance measures the difference in units of the spread of the
+//data, given the inverse of its covariance matrix. Correlated dimensions are
+//not counted twice and the scale of each feature doesn't matter.
+func MahalanobisDistance(X, Y []float64, inverseCovariance [][]float64) float64 {
+	var sum float64
+	for i := range X {
+		var row float64
+		for j := range Y {
+			row += inverseCovariance[i][j] * (X[j] - Y[j])
+		}
+		sum += (X[i] - Y[i]) * row
+	}
+	return math.Sqrt(math.Max(0, sum))
+}
+
+// newDistanceMetric describes a distance method to the neighbor indexes.
+// inverseCovariance is only read by the Mahalanobis distance.
+func newDistanceMetric(dm DistanceMethodType, w []float64, p float64, inverseCovariance [][]float64) distanceMetric {
+	switch dm {
+	case DMT_HuffmanMethod:
+		return distanceMetric{
+			dist: func(x, y []float64) float64 {
+				return HuffmanDistance(x, y, w)
+			},
+			axisBound: func(axis int, diff float64) float64 {
+				return math.Sqrt(w[axis] * diff)
+			},
+			triangle: true,
+		}
+	case DMT_MinkowskiMethod:
+		if p == 2 {
+			return newDistanceMetric(DMT_EulerMethod, w, p, inverseCovariance)
+		}
+		return distanceMetric{
+			dist: func(x, y []float64) float64 {
+				return MinkowskiDistance(x, y, w, p)
+			},
+			axisBound: func(axis int, diff float64) float64 {
+				return math.Pow(w[axis], 1/p) * diff
+			},
+			// below 1 the p-norm is no longer a metric
+			triangle: p >= 1,
+		}
+	case DMT_ChebyshevMethod:
+		return distanceMetric{
+			dist: func(x, y []float64) float64 {
+				return ChebyshevDistance(x, y, w)
+			},
+			axisBound: func(axis int, diff float64) float64 {
+				return w[axis] * diff
+			},
+			triangle: true,
+		}
+	case DMT_CosineMethod:
+		return distanceMetric{
+			dist: func(x, y []float64) float64 {
+				return CosineDistance(x, y, w)
+			},
+		}
+	case DMT_HammingMethod:
+		var total float64
+		for _, v := range w {
+			total += v
+		}
+		return distanceMetric{
+			dist: func(x, y []float64) float64 {
+				return HammingDistance(x, y, w)
+			},
+			axisBound: func(axis int, diff float64) float64 {
+				if diff == 0 || total == 0 {
+					return 0
+				}
+				return w[axis] / total
+			},
+			triangle: true,
+		}
+	case DMT_MahalanobisMethod:
+		// with the Cholesky factor inverseCovariance = L * L^T, the distance
+		// is the Euclidean distance between L^T x and L^T y.
+		l := cholesky(inverseCovariance)
+		return distanceMetric{
+			dist: func(x, y []float64) float64 {
+				return MahalanobisDistance(x, y, inverseCovariance)
+			},
+			triangle: true,
+			scale: func(x []float64) []float64 {
+				scaled := make([]float64, len(x))
+				for i := range scaled {
+					for j := i; j < len(x); j++ {
+						scaled[i] += l[j][i] * x[j]
+					}
+				}
+				return scaled
+			},
+		}
+	default:
+		return distanceMetric{
+			dist: func(x, y []float64) float64 {
+				return EulerDistance(x, y, w)
+			},
+			axisBound: func(axis int, diff float64) float64 {
+				return math.Sqrt(w[axis]) * diff
+			},
+			triangle: true,
+			scale: func(x []float64) []float64 {
+				scaled := make([]float64, len(x))
+				for i, v := range x {
+					scaled[i] = math.Sqrt(w[i]) * v
+				}
+				return scaled
+			},
+		}
+	}
+}
+
+// inverseCovariance of the samples, the diagonal gets a small ridge so that
+// constant or perfectly correlated features don't make it singular.
+func inverseCovariance(samples [][]float64) ([][]float64, error) {
+	n, dim := len(samples), len(samples[0])
+	mean := make([]float64, dim)
+	for _, s := range samples {
+		for i, v := range s {
+			mean[i] += v / float64(n)
+		}
+	}
+
+	cov := make([][]float64, dim)
+	for i := range cov {
+		cov[i] = make([]float64, dim)
+	}
+	for _, s := range samples {
+		for i := range cov {
+			for j := range cov[i] {
+				cov[i][j] += (s[i] - mean[i]) * (s[j] - mean[j])
+			}
+		}
+	}
+	for i := range cov {
+		for j := range cov[i] {
+			cov[i][j] /= math.Max(1, float64(n-1))
+		}
+		cov[i][i] += 1e-9
+	}
+	return invert(cov)
+}
+
+// invert a square matrix by Gauss-Jordan elimination with partial pivoting.
+func invert(m [][]float64) ([][]float64, error) {
+	n := len(m)
+	a := make([][]float64, n)
+	inv := make([][]float64, n)
+	for i := range m {
+		a[i] = append([]float64(nil), m[i]...)
+		inv[i] = make([]float64, n)
+		inv[i][i] = 1
+	}
+
+	for col := 0; col < n; col++ {
+		pivot := col
+		for row := col + 1; row < n; row++ {
+			if math.Abs(a[row][col]) > math.Abs(a[pivot][col]) {
+				pivot = row
+			}
+		}
+		if math.Abs(a[pivot][col]) < 1e-12 {
+			return nil, errSingularCovariance
+		}
+		a[col], a[pivot] = a[pivot], a[col]
+		inv[col], inv[pivot] = inv[pivot], inv[col]
+
+		div := a[col][col]
+		for j := 0; j < n; j++ {
+			a[col][j] /= div
+			inv[col][j] /= div
+		}
+		for row := 0; row < n; row++ {
+			if row == col || a[row][col] == 0 {
+				continue
+			}
+			f := a[row][col]
+			for j := 0; j < n; j++ {
+				a[row][j] -= f * a[col][j]
+				inv[row][j] -= f * inv[col][j]
+			}
+		}
+	}
+	return inv, nil
+}
+
+// cholesky returns the lower triangular L with m = L * L^T for a symmetric
+// positive definite m. Rounding noise on the diagonal is clamped to zero.
+func cholesky(m [][]float64) [][]float64 {
+	n := len(m)
+	l := make([][]float64, n)
+	for i := range l {
+		l[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := 0; j <= i; j++ {
+			sum := m[i][j]
+			for k := 0; k < j; k++ {
+				sum -= l[i][k] * l[j][k]
+			}
+			if i == j {
+				l[i][i] = math.Sqrt(math.Max(sum, 0))
+			} else if l[j][j] > 0 {
+				l[i][j] = sum / l[j][j]
+			}
+		}
+	}
+	return l
+}
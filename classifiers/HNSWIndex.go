@@ -0,0 +1,62 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package classifiers
+
+import "github.com/broosaction/gotext/nlp/ann"
+
+// hnswIndex serves KNearestNeighbors queries from an approximate ann.HNSW
+// graph. The samples are scaled so that the graph's Euclidean distance equals
+// the distance of the classifier, which keeps the reported distances exact
+// even though the neighbors themselves are approximate.
+type hnswIndex struct {
+	graph  *ann.HNSW
+	metric distanceMetric
+	size   int
+}
+
+func newHNSWIndex(samples [][]float64, m distanceMetric) *hnswIndex {
+	index := &hnswIndex{
+		graph:  ann.NewHNSW(ann.MT_Euclidean, 16, 200),
+		metric: m,
+		size:   len(samples),
+	}
+	for i, sample := range samples {
+		// samples of a different dimension are skipped, they have no place in the graph
+		_ = index.graph.Insert(i, m.scale(sample))
+	}
+	return index
+}
+
+func (h *hnswIndex) Nearest(query []float64, k int) []Neighbor {
+	results, err := h.graph.Search(h.metric.scale(query), k)
+	if err != nil {
+		return nil
+	}
+	neighbors := make([]Neighbor, len(results))
+	for i, r := range results {
+		neighbors[i] = Neighbor{Idx: r.ID, Dist: r.Distance}
+	}
+	return neighbors
+}
+
+// Within widens the search until the farthest neighbor found lies outside
+// the radius, the graph has no notion of a radius by itself.
+func (h *hnswIndex) Within(query []float64, radius float64) []Neighbor {
+	for k := 16; ; k *= 2 {
+		neighbors := h.Nearest(query, k)
+		if len(neighbors) < k || neighbors[len(neighbors)-1].Dist > radius || k >= h.size {
+			result := neighbors[:0]
+			for _, n := range neighbors {
+				if n.Dist <= radius {
+					result = append(result, n)
+				}
+			}
+			return result
+		}
+	}
+}
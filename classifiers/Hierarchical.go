@@ -0,0 +1,313 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package classifiers
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"github.com/broosaction/gotext/utils/persist"
+	"log"
+	"sort"
+	"strings"
+)
+
+type HierarchyBase uint8
+
+const (
+	// a NaiveBayes model at every node
+	HB_NaiveBayes HierarchyBase = iota
+	// an IntentClassifier at every node
+	HB_IntentClassifier
+)
+
+// the class of the local classifier of a node for the texts whose label ends there
+const hierarchyEnd = "<end>"
+
+func init() {
+	// the node models are encoded as interfaces
+	gob.Register(&IntentClassifier{})
+}
+
+/**
+ * Hierarchical Classifier
+ *
+ * Classifies texts into a taxonomy of labels such as "billing/refund/partial".
+ * Every internal node of the taxonomy gets its own local classifier that
+ * picks one of its children, so a text is classified top-down: first the
+ * top level category, then within it, and so on to a leaf. When the local
+ * classifier of a node is less confident than the threshold of its level,
+ * the prediction stops at that node instead of guessing deeper.
+ *
+ * A label may end at an internal node, "billing/refund" next to
+ * "billing/refund/partial". The local classifier of such a node then also
+ * learns to stop there, as if it had one more child, so a text is only
+ * sent further down when it looks more like one of the children.
+ *
+ * @category    Machine Learning
+
+  **usage
+	h := classifiers.NewHierarchicalClassifier(classifiers.HB_NaiveBayes)
+	h.Thresholds = []float64{0.5, 0.6}
+
+	h.Learn("I want my money back", "billing/refund")
+	h.Learn("refund only one item of my order", "billing/refund/partial")
+	h.Learn("my card was declined", "billing/payment")
+	h.Learn("the app crashes", "tech/crash")
+
+	prediction := h.Classify("give me my money back")
+	fmt.Println(prediction.Path, prediction.Confidences, prediction.Stopped)
+*/
+type HierarchicalClassifier struct {
+	// The kind of local classifier at every node.
+	Base HierarchyBase
+
+	// Separates the levels of a label.
+	Separator string
+
+	// The confidence needed to go down from a node, by level, the root
+	// being level 0. Levels past the end of Thresholds use Threshold.
+	Thresholds []float64
+	Threshold  float64
+
+	// The nodes by path, the root has the empty path.
+	Nodes map[string]*HierarchyNode
+}
+
+// HierarchyNode is a node of the label taxonomy.
+type HierarchyNode struct {
+	// The full label of the node.
+	Path string
+
+	// The last level of the label of every child, sorted.
+	Children []string
+
+	// Some labels end at the node, the local classifier can stop there.
+	Terminal bool
+
+	// The local classifier choosing between the children.
+	Model interface{}
+}
+
+// HierarchyPrediction is the result of a top-down classification.
+type HierarchyPrediction struct {
+	// The full label, empty when not even the first level was certain enough.
+	Path string
+
+	// The levels of Path.
+	Labels []string
+
+	// The confidence of the local classifier in every level of Path.
+	Confidences []float64
+
+	// The product of Confidences.
+	Confidence float64
+
+	// The prediction stopped at an internal node because the next level was uncertain.
+	Stopped bool
+}
+
+func NewHierarchicalClassifier(base HierarchyBase) *HierarchicalClassifier {
+	return &HierarchicalClassifier{
+		Base:      base,
+		Separator: "/",
+		Threshold: 0.5,
+		Nodes:     map[string]*HierarchyNode{},
+	}
+}
+
+func (h *HierarchicalClassifier) getMeta() (string, string) {
+	return "HierarchicalClassifier", "01"
+}
+
+// Learn teaches every node on the path of the label which child the text goes to.
+func (h *HierarchicalClassifier) Learn(text, label string) {
+	levels := strings.Split(strings.Trim(label, h.Separator), h.Separator)
+	path := ""
+	for _, level := range levels {
+		node := h.node(path)
+		node.addChild(level)
+		h.learn(node, text, level)
+		path = h.join(path, level)
+	}
+	node := h.node(path)
+	node.Terminal = true
+	h.learn(node, text, hierarchyEnd)
+}
+
+// learn teaches the local classifier of the node, creating it when needed, that the text goes to the choice.
+func (h *HierarchicalClassifier) learn(node *HierarchyNode, text, choice string) {
+	if node.Model == nil {
+		node.Model = h.newModel()
+	}
+	switch model := node.Model.(type) {
+	case *IntentClassifier:
+		model.Train(text, choice)
+	case *NaiveBayes:
+		model.Learn(text, choice)
+	}
+}
+
+func (h *HierarchicalClassifier) join(path, level string) string {
+	if path == "" {
+		return level
+	}
+	return path + h.Separator + level
+}
+
+// node returns the node of a path, creating it when needed.
+func (h *HierarchicalClassifier) node(path string) *HierarchyNode {
+	if h.Nodes == nil {
+		h.Nodes = map[string]*HierarchyNode{}
+	}
+	node, ok := h.Nodes[path]
+	if !ok {
+		node = &HierarchyNode{Path: path}
+		h.Nodes[path] = node
+	}
+	return node
+}
+
+// newModel returns a local classifier of the Base type, only nodes with children or ending labels get one.
+func (h *HierarchicalClassifier) newModel() interface{} {
+	if h.Base == HB_IntentClassifier {
+		return NewIntentClassifier()
+	}
+	return NewNaiveBayes()
+}
+
+func (n *HierarchyNode) addChild(level string) {
+	at := sort.SearchStrings(n.Children, level)
+	if at < len(n.Children) && n.Children[at] == level {
+		return
+	}
+	n.Children = append(n.Children, "")
+	copy(n.Children[at+1:], n.Children[at:])
+	n.Children[at] = level
+}
+
+// choices returns the children of the node, and hierarchyEnd when labels end there.
+func (n *HierarchyNode) choices() []string {
+	if !n.Terminal {
+		return n.Children
+	}
+	return append(append([]string(nil), n.Children...), hierarchyEnd)
+}
+
+// probabilities of every choice of the node for the text.
+func (n *HierarchyNode) probabilities(text string) map[string]float64 {
+	if choices := n.choices(); len(choices) == 1 {
+		return map[string]float64{choices[0]: 1}
+	}
+	switch model := n.Model.(type) {
+	case *IntentClassifier:
+		return model.Probabilities(text)
+	case *NaiveBayes:
+		return model.Probabilities(text)
+	}
+	return nil
+}
+
+// LevelThreshold returns the confidence needed to go down from a node of the level.
+func (h *HierarchicalClassifier) LevelThreshold(level int) float64 {
+	if level < len(h.Thresholds) {
+		return h.Thresholds[level]
+	}
+	return h.Threshold
+}
+
+/**
+ * Determine the label of `text` top-down, going down while the local
+ * classifiers are confident enough.
+ */
+func (h *HierarchicalClassifier) Classify(text string) HierarchyPrediction {
+	prediction := HierarchyPrediction{Confidence: 1}
+	node, ok := h.Nodes[""]
+	for level := 0; ok && len(node.Children) > 0; level++ {
+		best, confidence := "", 0.0
+		probabilities := node.probabilities(text)
+		for _, choice := range node.choices() {
+			if p := probabilities[choice]; p > confidence {
+				best, confidence = choice, p
+			}
+		}
+		if best == hierarchyEnd {
+			break
+		}
+		if best == "" || confidence < h.LevelThreshold(level) {
+			prediction.Stopped = true
+			break
+		}
+		prediction.Labels = append(prediction.Labels, best)
+		prediction.Confidences = append(prediction.Confidences, confidence)
+		prediction.Confidence *= confidence
+		node, ok = h.Nodes[h.join(node.Path, best)]
+	}
+	prediction.Path = strings.Join(prediction.Labels, h.Separator)
+	return prediction
+}
+
+// Fit learns the texts one by one, see Learn.
+func (h *HierarchicalClassifier) Fit(texts []string, labels []string) error {
+	return fitText(h.Learn, texts, labels)
+}
+
+// Predict returns the Path of Classify, which may stop at an internal node.
+func (h *HierarchicalClassifier) Predict(text string) (string, error) {
+	path := h.Classify(text).Path
+	if path == "" {
+		return "", ErrNotClassified
+	}
+	return path, nil
+}
+
+//save to a file
+func (h *HierarchicalClassifier) Save(file string) error {
+
+	buf := new(bytes.Buffer)
+	encoder := gob.NewEncoder(buf)
+
+	err := encoder.Encode(h)
+	if err != nil {
+		return fmt.Errorf("error encoding model: %s", err)
+	}
+
+	name, version := h.getMeta()
+	persist.Save(file, persist.Modeldata{
+		Data:    buf.Bytes(),
+		Name:    name,
+		Version: version,
+	})
+	return nil
+}
+
+// Load from the output file.
+func (h *HierarchicalClassifier) Load(filePath string) error {
+	log.Printf("Loading Classifier from %s...", filePath)
+	meta := persist.Load(filePath)
+	//get the classifier current meta data
+	name, version := h.getMeta()
+	if meta.Name != name {
+		return fmt.Errorf("This file doesn't contain a HierarchicalClassifier")
+	}
+	if meta.Version != version {
+		return fmt.Errorf("Can't understand this file format")
+	}
+
+	// decode into an empty model, gob leaves out zero values such as a Threshold of 0
+	var loaded HierarchicalClassifier
+	decoder := gob.NewDecoder(bytes.NewBuffer(meta.Data))
+	err := decoder.Decode(&loaded)
+	if err != nil {
+		return fmt.Errorf("error decoding checkpoint file: %s", err)
+	}
+	*h = loaded
+
+	checkpointFile = filePath
+	return nil
+}
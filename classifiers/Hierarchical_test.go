@@ -0,0 +1,94 @@
+package classifiers
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var taxonomyTexts = map[string][]string{
+	"billing/refund/partial": {"refund only one item of my order", "refund part of my order"},
+	"billing/refund/full":    {"refund my whole order", "I want all my money back"},
+	"billing/payment":        {"my card was declined", "the payment failed", "I can not pay with my card"},
+	"tech/crash":             {"the app crashes on start", "the app crashed again"},
+	"tech/login":             {"I can not log in", "my password does not work for login"},
+}
+
+func learnTaxonomy(h *HierarchicalClassifier) {
+	for label, texts := range taxonomyTexts {
+		for _, text := range texts {
+			h.Learn(text, label)
+		}
+	}
+}
+
+func TestHierarchicalClassifier(t *testing.T) {
+	for _, base := range []HierarchyBase{HB_NaiveBayes, HB_IntentClassifier} {
+		h := NewHierarchicalClassifier(base)
+		h.Threshold = 0
+		learnTaxonomy(h)
+
+		got := h.Classify("the app crashes")
+		if got.Path != "tech/crash" || got.Stopped || len(got.Confidences) != 2 {
+			t.Errorf("base %d: got %+v", base, got)
+		}
+		if got := h.Classify("refund part of my order").Path; got != "billing/refund/partial" {
+			t.Errorf("base %d: got %s", base, got)
+		}
+		if !h.Nodes["tech/crash"].Terminal || h.Nodes["tech"].Terminal || len(h.Nodes["billing"].Children) != 2 {
+			t.Errorf("base %d: wrong taxonomy", base)
+		}
+	}
+}
+
+func TestHierarchicalClassifierStops(t *testing.T) {
+	h := NewHierarchicalClassifier(HB_NaiveBayes)
+	learnTaxonomy(h)
+	// sure it is billing, but refund and payment are equally likely
+	h.Thresholds = []float64{0.5, 0.99}
+	got := h.Classify("my order")
+	if got.Path != "billing" || !got.Stopped {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestHierarchicalClassifierInternalLabel(t *testing.T) {
+	for _, base := range []HierarchyBase{HB_NaiveBayes, HB_IntentClassifier} {
+		h := NewHierarchicalClassifier(base)
+		h.Threshold = 0
+		h.Learn("I want my money back", "billing/refund")
+		h.Learn("give me my money back now", "billing/refund")
+		h.Learn("refund only one item of my order", "billing/refund/partial")
+		h.Learn("my card was declined", "billing/payment")
+
+		// refund has a single child, but labels also end at it
+		if got := h.Classify("I want my money back"); got.Path != "billing/refund" || got.Stopped || len(got.Confidences) != 2 {
+			t.Errorf("base %d: got %+v", base, got)
+		}
+		if got := h.Classify("refund only one item").Path; got != "billing/refund/partial" {
+			t.Errorf("base %d: got %s", base, got)
+		}
+		if _, ok := h.Nodes["billing/refund"].probabilities("money back")[hierarchyEnd]; !ok {
+			t.Errorf("base %d: no end class at billing/refund", base)
+		}
+	}
+}
+
+func TestHierarchicalClassifierSaveLoad(t *testing.T) {
+	for _, base := range []HierarchyBase{HB_NaiveBayes, HB_IntentClassifier} {
+		h := NewHierarchicalClassifier(base)
+		learnTaxonomy(h)
+		file := filepath.Join(t.TempDir(), "hierarchy.joi")
+		if err := h.Save(file); err != nil {
+			t.Fatal(err)
+		}
+		loaded := NewHierarchicalClassifier(HB_NaiveBayes)
+		if err := loaded.Load(file); err != nil {
+			t.Fatal(err)
+		}
+		text := "I want my money back for the order"
+		if got, want := loaded.Classify(text), h.Classify(text); !reflect.DeepEqual(got, want) {
+			t.Errorf("base %d: got %+v, want %+v", base, got, want)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package classifiers
+
+import (
+	"math"
+	"sort"
+)
+
+/**
+ * KD-Tree
+ *
+ * A binary space partitioning tree that recursively splits the samples at the
+ * median of the dimension with the widest spread. A query only descends into
+ * the far side of a split when the distance to the splitting plane can still
+ * beat the current k-th neighbor, which makes exact searches much cheaper than
+ * brute force as long as the number of dimensions stays low.
+ *
+ * @category    Machine Learning
+ */
+type KDTree struct {
+	samples [][]float64
+	metric  distanceMetric
+	root    *kdNode
+}
+
+type kdNode struct {
+	// sample positions held by a leaf
+	points []int
+
+	axis        int
+	split       float64
+	left, right *kdNode
+}
+
+func newKDTree(samples [][]float64, m distanceMetric, leafSize int) *KDTree {
+	points := make([]int, len(samples))
+	for i := range points {
+		points[i] = i
+	}
+	t := &KDTree{samples: samples, metric: m}
+	t.root = t.build(points, leafSize)
+	return t
+}
+
+func (t *KDTree) build(points []int, leafSize int) *kdNode {
+	if len(points) <= leafSize {
+		return &kdNode{points: points}
+	}
+
+	// split on the dimension where the samples are spread the most
+	axis, spread := 0, -1.0
+	for d := range t.samples[points[0]] {
+		min, max := math.Inf(1), math.Inf(-1)
+		for _, p := range points {
+			min = math.Min(min, t.samples[p][d])
+			max = math.Max(max, t.samples[p][d])
+		}
+		if max-min > spread {
+			axis, spread = d, max-min
+		}
+	}
+	if spread <= 0 {
+		// every sample is the same point
+		return &kdNode{points: points}
+	}
+
+	sort.Slice(points, func(i, j int) bool {
+		return t.samples[points[i]][axis] < t.samples[points[j]][axis]
+	})
+	mid := len(points) / 2
+	// read the split before the children reorder their halves of points
+	n := &kdNode{axis: axis, split: t.samples[points[mid]][axis]}
+	n.left = t.build(points[:mid], leafSize)
+	n.right = t.build(points[mid:], leafSize)
+	return n
+}
+
+// Nearest returns the k samples closest to the query.
+func (t *KDTree) Nearest(query []float64, k int) []Neighbor {
+	c := &candidates{k: k}
+	if k > 0 {
+		t.nearest(t.root, query, c)
+	}
+	return c.sorted()
+}
+
+func (t *KDTree) nearest(n *kdNode, query []float64, c *candidates) {
+	if n.left == nil {
+		for _, p := range n.points {
+			c.offer(p, t.metric.dist(query, t.samples[p]))
+		}
+		return
+	}
+
+	diff := query[n.axis] - n.split
+	near, far := n.left, n.right
+	if diff >= 0 {
+		near, far = n.right, n.left
+	}
+	t.nearest(near, query, c)
+	if t.metric.axisBound(n.axis, math.Abs(diff)) <= c.worst() {
+		t.nearest(far, query, c)
+	}
+}
+
+// Within returns every sample at most radius away from the query.
+func (t *KDTree) Within(query []float64, radius float64) []Neighbor {
+	var result []Neighbor
+	t.within(t.root, query, radius, &result)
+	sortNeighbors(result)
+	return result
+}
+
+func (t *KDTree) within(n *kdNode, query []float64, radius float64, result *[]Neighbor) {
+	if n.left == nil {
+		for _, p := range n.points {
+			if d := t.metric.dist(query, t.samples[p]); d <= radius {
+				*result = append(*result, Neighbor{Idx: p, Dist: d})
+			}
+		}
+		return
+	}
+
+	diff := query[n.axis] - n.split
+	near, far := n.left, n.right
+	if diff >= 0 {
+		near, far = n.right, n.left
+	}
+	t.within(near, query, radius, result)
+	if t.metric.axisBound(n.axis, math.Abs(diff)) <= radius {
+		t.within(far, query, radius, result)
+	}
+}
@@ -12,9 +12,12 @@ import (
 	"encoding/gob"
 	"errors"
 	"fmt"
+	"github.com/broosaction/gotext/preprocessing"
 	"github.com/broosaction/gotext/utils/persist"
 	"log"
 	"math"
+	"sort"
+	"strings"
 )
 
 type DistanceMethodType uint8
@@ -24,10 +27,28 @@ const (
 	DMT_EulerMethod DistanceMethodType = iota
 	// Huffman
 	DMT_HuffmanMethod
+	// p-norm of the difference, P sets p
+	DMT_MinkowskiMethod
+	// largest difference on a single dimension
+	DMT_ChebyshevMethod
+	// 1 - cosine similarity, only the direction of the vectors counts
+	DMT_CosineMethod
+	// difference scaled by the covariance of the training samples
+	DMT_MahalanobisMethod
+	// share of the dimensions that differ, for categorical data
+	DMT_HammingMethod
+)
 
+type VotingType uint8
 
+const (
+	// every neighbor counts the same
+	VT_Majority VotingType = iota
+	// closer neighbors count more, with weight 1 / distance
+	VT_InverseDistance
 )
 
+
 /**
  * K Nearest Neighbors
  *
@@ -35,6 +56,8 @@ const (
  * training set and uses a majority vote to classify the unknown sample. K
  * Nearest Neighbors is considered a lazy learning Estimator because it does all
  * of its computation at prediction time.
+ * With VT_InverseDistance voting closer neighbors get a bigger say, and
+ * Probabilities tells how the votes were shared between the labels.
  *
  * @category    Machine Learning
  * @author      Bruce Mubangwa
@@ -87,7 +110,7 @@ train := [][]float64{
 
 		knn := classifiers.NewKNearestNeighbors(i, dm, w)
 		knn.LearnBatch(train, labels)
-		res := knn.Classify(test)
+		res, _ := knn.Classify(test)
 		if res[0] != "Setosa" {
 			fmt.Printf("k = %d failed", i)
 			fmt.Println()
@@ -111,7 +134,8 @@ type KNearestNeighbors struct {
 	/**
 	 * Should we use the inverse distances as confidence scores when
 	 * making predictions?
-	 * Weight define the weight vector for multi-dimension data
+	 * Weight define the weight vector for multi-dimension data,
+	 * an empty Weight counts every dimension the same.
 	 */
 	Weight         []float64
 
@@ -121,6 +145,30 @@ type KNearestNeighbors struct {
 
 	//The memoized labels of the training set.
 	Labels  []string
+
+	/**
+	 * How the nearest neighbors are searched for, the index is
+	 * built once at LearnBatch time.
+	 */
+	Algorithm KNNAlgorithmType
+
+	// The p of DMT_MinkowskiMethod, 2 when not set.
+	P float64
+
+	// How the K neighbors are turned into a prediction.
+	Voting VotingType
+
+	// Inverse covariance of the samples, computed at LearnBatch time for DMT_MahalanobisMethod.
+	InverseCovariance [][]float64
+
+	/**
+	 * Transformers the samples go through before they are stored, fitted at
+	 * LearnBatch time and applied to every test vector. Saved with the model.
+	 * Weight applies to the transformed dimensions.
+	 */
+	Preprocess *preprocessing.Pipeline
+
+	index NeighborIndex
 }
 
 //SortedDistance
@@ -133,6 +181,8 @@ type SortedDistance struct {
 
 var(
 	errNotEqualDataLength = errors.New("The data length is not equal.")
+	errNoSamples          = errors.New("there are no training samples")
+	errInvalidK           = errors.New("K must be at least 1")
 )
 
 
@@ -151,11 +201,191 @@ func (k *KNearestNeighbors) getMeta() (string, string) {
 /**
  * Store the sample and outcome arrays. No other work to be done as this is
  * a lazy learning algorithm.
- *
+ * An error is returned when the samples don't all have the same dimension,
+ * or it doesn't match the weight vector.
  */
-func (k *KNearestNeighbors) LearnBatch(train [][]float64, label[]string){
+func (k *KNearestNeighbors) LearnBatch(train [][]float64, label[]string) error {
+	if len(train) != len(label) {
+		return fmt.Errorf("%d samples but %d labels: %w", len(train), len(label), errNotEqualDataLength)
+	}
+	train, err := fitPreprocess(k.Preprocess, train)
+	if err != nil {
+		return err
+	}
+	if err := checkSamples(train, k.Weight, k.K); err != nil {
+		return err
+	}
 	k.Samples = train
 	k.Labels = label
+	return k.fit()
+}
+
+// fit prepares everything a prediction needs from the samples.
+func (k *KNearestNeighbors) fit() error {
+	k.InverseCovariance = nil
+	if k.DistanceMethod == DMT_MahalanobisMethod {
+		inv, err := inverseCovariance(k.Samples)
+		if err != nil {
+			return err
+		}
+		k.InverseCovariance = inv
+	}
+	k.buildIndex()
+	return nil
+}
+
+// checkSamples makes sure the training samples can be compared with each other.
+func checkSamples(train [][]float64, w []float64, K int) error {
+	if K < 1 {
+		return errInvalidK
+	}
+	if len(train) == 0 {
+		return errNoSamples
+	}
+	dim := len(train[0])
+	for i, sample := range train {
+		if len(sample) != dim {
+			return fmt.Errorf("sample %d has %d dimensions, sample 0 has %d: %w", i, len(sample), dim, errNotEqualDataLength)
+		}
+	}
+	if len(w) > 0 && len(w) != dim {
+		return fmt.Errorf("the weight vector has %d dimensions, the samples have %d: %w", len(w), dim, errNotEqualDataLength)
+	}
+	return nil
+}
+
+// fitPreprocess fits the pipeline, when there is one, and returns the transformed samples.
+func fitPreprocess(p *preprocessing.Pipeline, train [][]float64) ([][]float64, error) {
+	if p == nil {
+		return train, nil
+	}
+	return p.FitTransform(train)
+}
+
+// preprocess runs a single test vector through the pipeline, when there is one.
+func preprocess(p *preprocessing.Pipeline, test []float64) ([]float64, error) {
+	if p == nil {
+		return test, nil
+	}
+	out, err := p.Transform([][]float64{test})
+	if err != nil {
+		return nil, err
+	}
+	return out[0], nil
+}
+
+// checkTest makes sure a test vector can be compared with the training samples.
+func checkTest(samples [][]float64, test []float64) error {
+	if len(samples) == 0 {
+		return errNoSamples
+	}
+	if len(test) != len(samples[0]) {
+		return fmt.Errorf("the test vector has %d dimensions, the samples have %d: %w", len(test), len(samples[0]), errNotEqualDataLength)
+	}
+	return nil
+}
+
+// buildIndex prepares the neighbor search structure over the current samples.
+func (k *KNearestNeighbors) buildIndex() {
+	k.index = newNeighborIndex(k.Algorithm, k.Samples, k.metric())
+}
+
+func (k *KNearestNeighbors) metric() distanceMetric {
+	p := k.P
+	if p == 0 {
+		p = 2
+	}
+	return newDistanceMetric(k.DistanceMethod, uniformWhenEmpty(k.Weight, k.Samples), p, k.InverseCovariance)
+}
+
+// uniformWhenEmpty gives every dimension a weight of 1 when no weights were set.
+func uniformWhenEmpty(w []float64, samples [][]float64) []float64 {
+	if len(w) > 0 || len(samples) == 0 {
+		return w
+	}
+	w = make([]float64, len(samples[0]))
+	for i := range w {
+		w[i] = 1
+	}
+	return w
+}
+
+// Neighbors returns the K training samples nearest to a single test vector.
+func (k *KNearestNeighbors) Neighbors(test []float64) ([]Neighbor, error) {
+	test, err := preprocess(k.Preprocess, test)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTest(k.Samples, test); err != nil {
+		return nil, err
+	}
+	if k.index == nil {
+		k.buildIndex()
+	}
+	return k.index.Nearest(test, k.K), nil
+}
+
+// RadiusNeighbors returns every training sample at most radius away from a single test vector.
+func (k *KNearestNeighbors) RadiusNeighbors(test []float64, radius float64) ([]Neighbor, error) {
+	test, err := preprocess(k.Preprocess, test)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTest(k.Samples, test); err != nil {
+		return nil, err
+	}
+	if k.index == nil {
+		k.buildIndex()
+	}
+	return k.index.Within(test, radius), nil
+}
+
+// votes of the neighbors, by label.
+func (k *KNearestNeighbors) votes(neighbors []Neighbor) map[string]float64 {
+	votes := make(map[string]float64, k.K)
+	for _, neighbor := range neighbors {
+		votes[k.Labels[neighbor.Idx]] += voteWeight(k.Voting, neighbor, neighbors)
+	}
+	return votes
+}
+
+// voteWeight of one neighbor. With inverse distance voting, neighbors at
+// distance 0 are exact matches and outvote all others.
+func voteWeight(voting VotingType, n Neighbor, neighbors []Neighbor) float64 {
+	if voting != VT_InverseDistance {
+		return 1
+	}
+	if neighbors[0].Dist == 0 {
+		if n.Dist == 0 {
+			return 1
+		}
+		return 0
+	}
+	return 1 / n.Dist
+}
+
+/**
+ * Probabilities of every label for each test vector, the share of the
+ * votes of the K nearest neighbors that went to it.
+ */
+func (k *KNearestNeighbors) Probabilities(test [][]float64) ([]map[string]float64, error) {
+	result := make([]map[string]float64, len(test))
+	for j, _test := range test {
+		neighbors, err := k.Neighbors(_test)
+		if err != nil {
+			return nil, fmt.Errorf("test data %d: %w", j, err)
+		}
+		votes := k.votes(neighbors)
+		var total float64
+		for _, v := range votes {
+			total += v
+		}
+		for label := range votes {
+			votes[label] /= total
+		}
+		result[j] = votes
+	}
+	return result, nil
 }
 
 
@@ -164,67 +394,40 @@ func (k *KNearestNeighbors) LearnBatch(train [][]float64, label[]string){
  * Classify, train is an nxp matrix, where n denotes the n training data,
  * and p represents the number of attributes. The test is an mxp matrix.
  */
-func (k *KNearestNeighbors) Classify(test [][]float64) []string {
+func (k *KNearestNeighbors) Classify(test [][]float64) ([]string, error) {
 
-	// computing the distance between one testing data and all the training data
-	var sortedDistance *SortedDistance
+	// find the K training data nearest to every testing data
 	result := make([]string, len(test))
 	for j, _test := range test {
 
-		sortedDistance = NewSortedDistance(len(k.Samples))
-		for i, _train := range k.Samples {
-			var _dist float64
-			switch k.DistanceMethod {
-			case DMT_EulerMethod:
-				_dist = EulerDistance(_test, _train, k.Weight)
-				break
-			case DMT_HuffmanMethod:
-				_dist = HuffmanDistance(_test, _train, k.Weight)
-				break
-			default:
-				_dist = EulerDistance(_test, _train, k.Weight)
-			}
-			sortedDistance.Put(i, _dist)
+		topK, err := k.Neighbors(_test)
+		if err != nil {
+			return nil, fmt.Errorf("test data %d: %w", j, err)
 		}
-		topKIdx := sortedDistance.SelectTopKIdx(k.K)
-		//statistic the frequent of every labels
-		freqLabels := make(map[string]int, k.K)
-		var maxFreq int = 0
-		for _, idx := range topKIdx {
-			_label := k.Labels[idx]
-			v, ok := freqLabels[_label]
-			if ok {
-				freqLabels[_label] = v + 1
-			} else {
-				freqLabels[_label] = 1
-			}
-
-			if maxFreq < v+1 {
-				maxFreq = v + 1
+		//statistic the votes of every labels
+		votes := k.votes(topK)
+		var maxVotes float64 = 0
+		for _, v := range votes {
+			if maxVotes < v {
+				maxVotes = v
 			}
-
 		}
-		//get the label with maximum frequent
+		//get the label with maximum votes
 		_out := make([]string, 0)
-		for k, v := range freqLabels {
-			if v == maxFreq {
+		for k, v := range votes {
+			if v == maxVotes {
 				_out = append(_out, k)
 			}
 		}
+		sort.Strings(_out)
 		if len(_out) == 1 {
 			result[j] = _out[0]
 		} else {
-			fmt.Printf("the %d test data has %d classification results.", j, len(_out))
-			 fmt.Println()
-			_str := ""
-			for _, v := range _out {
-				_str += v + "#"
-			}
-			result[j] = _str[0:len(_str)]
+			result[j] = strings.Join(_out, "#")
 		}
 
 	}
-	return result
+	return result, nil
 }
 
 
@@ -272,8 +475,6 @@ func HuffmanDistance(X, Y, W []float64) float64 {
 
 //NewSortedDistance initial the SortedDistance with the size
 func NewSortedDistance(size int) *SortedDistance {
-	fmt.Printf("size: %d", size)
-	fmt.Println()
 	s := &SortedDistance{
 		Idx:  make([]int, size),
 		Dist: make([]float64, size),
@@ -325,6 +526,21 @@ func (s *SortedDistance) GetIdx() []int {
 	return s.Idx
 }
 
+// Fit is LearnBatch.
+func (k *KNearestNeighbors) Fit(samples [][]float64, labels []string) error {
+	return k.LearnBatch(samples, labels)
+}
+
+// Predict is Classify.
+func (k *KNearestNeighbors) Predict(samples [][]float64) ([]string, error) {
+	return k.Classify(samples)
+}
+
+// PredictProba is Probabilities.
+func (k *KNearestNeighbors) PredictProba(samples [][]float64) ([]map[string]float64, error) {
+	return k.Probabilities(samples)
+}
+
 //save to a file
 func (k *KNearestNeighbors) Save(file string) error {
 
@@ -358,11 +574,15 @@ func (k *KNearestNeighbors) Load(filePath string) error {
 		return fmt.Errorf("Can't understand this file format")
 	}
 
+	// decode into a fresh one, gob leaves the fields saved as zero values untouched
+	var loaded KNearestNeighbors
 	decoder := gob.NewDecoder(bytes.NewBuffer(meta.Data))
-	err := decoder.Decode(&k)
+	err := decoder.Decode(&loaded)
 	if err != nil {
 		return  fmt.Errorf("error decoding RNN checkpoint file: %s", err)
 	}
+	*k = loaded
+	k.buildIndex()
 
 	checkpointFile = filePath
 	return nil
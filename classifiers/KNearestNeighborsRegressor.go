@@ -0,0 +1,187 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package classifiers
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"github.com/broosaction/gotext/preprocessing"
+	"github.com/broosaction/gotext/utils/persist"
+	"log"
+)
+
+/**
+ * K Nearest Neighbors Regressor
+ *
+ * The numeric twin of K Nearest Neighbors, the prediction for an unknown
+ * sample is the average target of its K nearest training samples. With
+ * VT_InverseDistance voting the average is weighted by 1 / distance.
+ *
+ * @category    Machine Learning
+
+  **usage
+	knn := classifiers.NewKNearestNeighborsRegressor(3, classifiers.DMT_EulerMethod, nil)
+	knn.LearnBatch(train, prices)
+	predictions, err := knn.Predict(test)
+*/
+type KNearestNeighborsRegressor struct {
+	// The number of neighbors to consider when making a prediction.
+	K int
+
+	// The distance function to use when computing the distances.
+	DistanceMethod DistanceMethodType
+
+	// Weight define the weight vector for multi-dimension data,
+	// an empty Weight counts every dimension the same.
+	Weight []float64
+
+	// The p of DMT_MinkowskiMethod, 2 when not set.
+	P float64
+
+	// How the targets of the K neighbors are averaged.
+	Voting VotingType
+
+	// How the nearest neighbors are searched for.
+	Algorithm KNNAlgorithmType
+
+	// The training samples that make up the neighborhood of the problem space.
+	Samples [][]float64
+
+	//The memoized targets of the training set.
+	Targets []float64
+
+	// Inverse covariance of the samples, computed at LearnBatch time for DMT_MahalanobisMethod.
+	InverseCovariance [][]float64
+
+	// Transformers the samples go through before they are stored, saved with the model.
+	Preprocess *preprocessing.Pipeline
+
+	index NeighborIndex
+}
+
+func NewKNearestNeighborsRegressor(k int, dm DistanceMethodType, w []float64) *KNearestNeighborsRegressor {
+	return &KNearestNeighborsRegressor{
+		K:              k,
+		DistanceMethod: dm,
+		Weight:         w,
+	}
+}
+
+func (k *KNearestNeighborsRegressor) getMeta() (string, string) {
+	return "KNearestNeighborsRegressor", "01"
+}
+
+// LearnBatch stores the samples and their numeric targets.
+func (k *KNearestNeighborsRegressor) LearnBatch(train [][]float64, targets []float64) error {
+	if len(train) != len(targets) {
+		return fmt.Errorf("%d samples but %d targets: %w", len(train), len(targets), errNotEqualDataLength)
+	}
+	train, err := fitPreprocess(k.Preprocess, train)
+	if err != nil {
+		return err
+	}
+	if err := checkSamples(train, k.Weight, k.K); err != nil {
+		return err
+	}
+	k.Samples = train
+	k.Targets = targets
+	k.InverseCovariance = nil
+	if k.DistanceMethod == DMT_MahalanobisMethod {
+		inv, err := inverseCovariance(train)
+		if err != nil {
+			return err
+		}
+		k.InverseCovariance = inv
+	}
+	k.buildIndex()
+	return nil
+}
+
+func (k *KNearestNeighborsRegressor) buildIndex() {
+	p := k.P
+	if p == 0 {
+		p = 2
+	}
+	m := newDistanceMetric(k.DistanceMethod, uniformWhenEmpty(k.Weight, k.Samples), p, k.InverseCovariance)
+	k.index = newNeighborIndex(k.Algorithm, k.Samples, m)
+}
+
+// Predict the target of every test vector.
+func (k *KNearestNeighborsRegressor) Predict(test [][]float64) ([]float64, error) {
+	result := make([]float64, len(test))
+	for j, _test := range test {
+		_test, err := preprocess(k.Preprocess, _test)
+		if err != nil {
+			return nil, fmt.Errorf("test data %d: %w", j, err)
+		}
+		if err := checkTest(k.Samples, _test); err != nil {
+			return nil, fmt.Errorf("test data %d: %w", j, err)
+		}
+		if k.index == nil {
+			k.buildIndex()
+		}
+		neighbors := k.index.Nearest(_test, k.K)
+
+		var sum, total float64
+		for _, n := range neighbors {
+			w := voteWeight(k.Voting, n, neighbors)
+			sum += w * k.Targets[n.Idx]
+			total += w
+		}
+		result[j] = sum / total
+	}
+	return result, nil
+}
+
+//save to a file
+func (k *KNearestNeighborsRegressor) Save(file string) error {
+
+	buf := new(bytes.Buffer)
+	encoder := gob.NewEncoder(buf)
+
+	err := encoder.Encode(k)
+	if err != nil {
+		return fmt.Errorf("error encoding model: %s", err)
+	}
+
+	name, version := k.getMeta()
+	persist.Save(file, persist.Modeldata{
+		Data:    buf.Bytes(),
+		Name:    name,
+		Version: version,
+	})
+	return nil
+}
+
+// Load from the output file.
+func (k *KNearestNeighborsRegressor) Load(filePath string) error {
+	log.Printf("Loading Regressor from %s...", filePath)
+	meta := persist.Load(filePath)
+	//get the regressor current meta data
+	name, version := k.getMeta()
+	if meta.Name != name {
+		return fmt.Errorf("This file doesn't contain a KNearestNeighborsRegressor")
+	}
+	if meta.Version != version {
+		return fmt.Errorf("Can't understand this file format")
+	}
+
+	// decode into a fresh one, gob leaves the fields saved as zero values untouched
+	var loaded KNearestNeighborsRegressor
+	decoder := gob.NewDecoder(bytes.NewBuffer(meta.Data))
+	err := decoder.Decode(&loaded)
+	if err != nil {
+		return fmt.Errorf("error decoding checkpoint file: %s", err)
+	}
+	*k = loaded
+	k.buildIndex()
+
+	checkpointFile = filePath
+	return nil
+}
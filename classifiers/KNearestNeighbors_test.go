@@ -0,0 +1,172 @@
+package classifiers
+
+import (
+	"errors"
+	"github.com/broosaction/gotext/preprocessing"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+var irisTrain = [][]float64{
+	{5.3, 3.7}, {5.1, 3.8}, {7.2, 3}, {5.4, 3.4}, {5.1, 3.3},
+	{5.4, 3.9}, {7.4, 2.8}, {6.1, 2.8}, {7.3, 2.9}, {6, 2.7},
+	{5.8, 2.8}, {6.3, 2.3}, {5.1, 2.5}, {6.3, 2.5}, {5.5, 2.4},
+}
+
+var irisLabels = []string{
+	"Setosa", "Setosa", "Virginica", "Setosa", "Setosa",
+	"Setosa", "Virginica", "Versicolor", "Virginica", "Versicolor",
+	"Virginica", "Versicolor", "Versicolor", "Versicolor", "Versicolor",
+}
+
+func TestKNearestNeighborsDistanceMethods(t *testing.T) {
+	methods := []DistanceMethodType{DMT_EulerMethod, DMT_HuffmanMethod, DMT_MinkowskiMethod,
+		DMT_ChebyshevMethod, DMT_CosineMethod, DMT_MahalanobisMethod}
+	for _, dm := range methods {
+		for _, voting := range []VotingType{VT_Majority, VT_InverseDistance} {
+			knn := NewKNearestNeighbors(3, dm, nil)
+			knn.Voting = voting
+			if err := knn.LearnBatch(irisTrain, irisLabels); err != nil {
+				t.Fatal(err)
+			}
+			res, err := knn.Classify([][]float64{{5.2, 3.6}, {7.3, 2.95}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res[0] != "Setosa" || res[1] != "Virginica" {
+				t.Errorf("method %d, voting %d: got %v", dm, voting, res)
+			}
+		}
+	}
+}
+
+func TestKNearestNeighborsProbabilities(t *testing.T) {
+	knn := NewKNearestNeighbors(4, DMT_EulerMethod, []float64{1, 1})
+	knn.LearnBatch(irisTrain, irisLabels)
+	probs, err := knn.Probabilities([][]float64{{5.2, 3.6}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total float64
+	for _, p := range probs[0] {
+		total += p
+	}
+	if math.Abs(total-1) > 1e-9 || probs[0]["Setosa"] != 1 {
+		t.Errorf("got %v", probs[0])
+	}
+}
+
+func TestKNearestNeighborsHamming(t *testing.T) {
+	knn := NewKNearestNeighbors(1, DMT_HammingMethod, nil)
+	knn.LearnBatch([][]float64{{0, 1, 1, 0}, {1, 0, 0, 1}}, []string{"a", "b"})
+	if res, _ := knn.Classify([][]float64{{0, 1, 1, 1}}); res[0] != "a" {
+		t.Errorf("got %v", res)
+	}
+}
+
+func TestKNearestNeighborsValidation(t *testing.T) {
+	knn := NewKNearestNeighbors(3, DMT_EulerMethod, []float64{0.5, 0.5})
+	if err := knn.LearnBatch(irisTrain, irisLabels[:3]); !errors.Is(err, errNotEqualDataLength) {
+		t.Errorf("labels mismatch: got %v", err)
+	}
+	if err := knn.LearnBatch([][]float64{{1, 2}, {1, 2, 3}}, []string{"a", "b"}); !errors.Is(err, errNotEqualDataLength) {
+		t.Errorf("ragged samples: got %v", err)
+	}
+	knn.Weight = []float64{1, 1, 1}
+	if err := knn.LearnBatch(irisTrain, irisLabels); !errors.Is(err, errNotEqualDataLength) {
+		t.Errorf("weight mismatch: got %v", err)
+	}
+	knn.Weight = nil
+	if err := knn.LearnBatch(irisTrain, irisLabels); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := knn.Classify([][]float64{{5.2, 3.1, 1}}); !errors.Is(err, errNotEqualDataLength) {
+		t.Errorf("test mismatch: got %v", err)
+	}
+	knn.K = 0
+	if err := knn.LearnBatch(irisTrain, irisLabels); err != errInvalidK {
+		t.Errorf("K = 0: got %v", err)
+	}
+}
+
+func TestKNearestNeighborsRegressor(t *testing.T) {
+	train := [][]float64{{1}, {2}, {3}, {10}, {11}}
+	targets := []float64{1, 2, 3, 10, 11}
+
+	knn := NewKNearestNeighborsRegressor(2, DMT_EulerMethod, nil)
+	if err := knn.LearnBatch(train, targets); err != nil {
+		t.Fatal(err)
+	}
+	pred, err := knn.Predict([][]float64{{1.5}, {10}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pred[0] != 1.5 || pred[1] != 10.5 {
+		t.Errorf("mean of neighbors: got %v", pred)
+	}
+
+	knn.Voting = VT_InverseDistance
+	pred, _ = knn.Predict([][]float64{{10}, {2.25}})
+	if pred[0] != 10 || pred[1] != 2.25 {
+		t.Errorf("inverse distance: got %v", pred)
+	}
+}
+
+func TestKNearestNeighborsPreprocess(t *testing.T) {
+	// income in dollars swamps age in years unless the features are scaled
+	train := [][]float64{{25, 50000}, {30, 51000}, {60, 50500}, {65, 49500}}
+	labels := []string{"young", "young", "old", "old"}
+
+	knn := NewKNearestNeighbors(1, DMT_EulerMethod, nil)
+	knn.Preprocess = preprocessing.NewPipeline(preprocessing.NewStandardScaler())
+	if err := knn.LearnBatch(train, labels); err != nil {
+		t.Fatal(err)
+	}
+	if res, _ := knn.Classify([][]float64{{62, 50900}}); res[0] != "old" {
+		t.Errorf("got %v", res)
+	}
+
+	file := filepath.Join(t.TempDir(), "knn.joi")
+	if err := knn.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	loaded := &KNearestNeighbors{}
+	if err := loaded.Load(file); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := loaded.Classify([][]float64{{27, 49600}}); err != nil || res[0] != "young" {
+		t.Errorf("loaded model: got %v, %v", res, err)
+	}
+
+	// a model saved without pipeline doesn't keep the one of the model it is loaded over
+	plain := NewKNearestNeighbors(1, DMT_EulerMethod, nil)
+	plain.LearnBatch(train, labels)
+	test := [][]float64{{62, 50900}}
+	want, _ := plain.Classify(test)
+	if err := plain.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	if err := knn.Load(file); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := knn.Classify(test); err != nil || knn.Preprocess != nil || got[0] != want[0] {
+		t.Errorf("loaded over a pipeline: got %v, want %v, %v", got, want, err)
+	}
+
+	regressor := NewKNearestNeighborsRegressor(1, DMT_EulerMethod, nil)
+	regressor.LearnBatch(train, []float64{25, 30, 60, 65})
+	wantAge, _ := regressor.Predict(test)
+	if err := regressor.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	scaled := NewKNearestNeighborsRegressor(1, DMT_EulerMethod, nil)
+	scaled.Preprocess = preprocessing.NewPipeline(preprocessing.NewStandardScaler())
+	scaled.LearnBatch(train, []float64{25, 30, 60, 65})
+	if err := scaled.Load(file); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := scaled.Predict(test); err != nil || scaled.Preprocess != nil || got[0] != wantAge[0] {
+		t.Errorf("regressor loaded over a pipeline: got %v, want %v, %v", got, wantAge, err)
+	}
+}
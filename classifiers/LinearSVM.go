@@ -0,0 +1,284 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package classifiers
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"github.com/broosaction/gotext/utils/persist"
+	"github.com/broosaction/gotext/utils/sparse"
+	"log"
+	"math"
+	"math/rand"
+)
+
+/**
+ * Linear SVM
+ *
+ * A linear support vector machine over sparse text features, either vocabulary
+ * indexed or hashed, see TextFeatures. Every class gets its own binary
+ * machine separating it from all the others (one-vs-rest), trained with
+ * Pegasos: stochastic sub-gradient descent on the L2 regularized hinge loss
+ * with step size 1 / (Lambda * t). The bias is regularized like a weight on a
+ * constant feature. The class of a text is the one whose machine scores it highest.
+ *
+ * Like LogisticRegression, Learn only stores a text and Train fits the
+ * machines, from scratch, on everything learned so far.
+ *
+ * @category    Machine Learning
+
+  **usage
+	svm := classifiers.NewLinearSVM()
+	svm.Features.HashBits = 18
+
+	svm.Learn("win a free prize now", "spam")
+	svm.Learn("are we still meeting for lunch", "ham")
+	svm.Train()
+
+	fmt.Println(svm.Classify("claim your free prize"))
+	fmt.Println(svm.DecisionFunction("claim your free prize"))
+*/
+type LinearSVM struct {
+	// How texts become feature vectors.
+	Features *TextFeatures
+
+	// The known classes, in the order they were first learned.
+	Classes []string
+
+	// Weights of the machine of every class, one per feature.
+	Weights [][]float64
+
+	// The intercept of the machine of every class.
+	Bias []float64
+
+	// Strength of the L2 regularization, larger values give a wider margin
+	// and more training errors.
+	Lambda float64
+
+	// The number of passes over the training texts.
+	Epochs int
+
+	// Multiplies the hinge loss of the texts of a class, classes not in the map weigh 1.
+	ClassWeights map[string]float64
+
+	// Weigh every class inversely to its number of texts. ClassWeights still overrides it.
+	Balanced bool
+
+	// Seed of the shuffling, the same seed and texts give the same model.
+	Seed int64
+
+	// the learned texts and the index of their class, not saved with the model
+	samples []sparse.Vector
+	labels  []int
+}
+
+func NewLinearSVM() *LinearSVM {
+	return &LinearSVM{
+		Features: NewTextFeatures(),
+		Lambda:   1e-4,
+		Epochs:   20,
+	}
+}
+
+func (svm *LinearSVM) getMeta() (string, string) {
+	return "LinearSVM", "01"
+}
+
+// Learn stores a labelled text for the next Train.
+func (svm *LinearSVM) Learn(text, class string) {
+	svm.samples = append(svm.samples, svm.Features.Vector(text, true))
+	svm.labels = append(svm.labels, classIndex(&svm.Classes, class))
+}
+
+// LearnVector stores a labelled feature vector for the next Train, see LogisticRegression.LearnVector.
+func (svm *LinearSVM) LearnVector(x sparse.Vector, class string) {
+	svm.samples = append(svm.samples, x)
+	svm.labels = append(svm.labels, classIndex(&svm.Classes, class))
+}
+
+// LearnBatch learns all the texts and trains on them.
+func (svm *LinearSVM) LearnBatch(texts []string, labels []string) error {
+	if len(texts) != len(labels) {
+		return errNotEqualDataLength
+	}
+	for i, text := range texts {
+		svm.Learn(text, labels[i])
+	}
+	return svm.Train()
+}
+
+// Train fits one machine per class on all the learned texts.
+func (svm *LinearSVM) Train() error {
+	if len(svm.samples) == 0 {
+		return errNoSamples
+	}
+	if len(svm.Classes) < 2 {
+		return errOneClass
+	}
+	classWeights := weighClasses(svm.labels, svm.Classes, svm.ClassWeights, svm.Balanced)
+
+	svm.Weights = make([][]float64, len(svm.Classes))
+	svm.Bias = make([]float64, len(svm.Classes))
+	for c := range svm.Classes {
+		// every machine sees the texts in the same order
+		rng := rand.New(rand.NewSource(svm.Seed))
+		svm.Weights[c], svm.Bias[c] = svm.pegasos(c, rng, classWeights)
+	}
+	return nil
+}
+
+// pegasos trains the machine that separates class c from the others.
+func (svm *LinearSVM) pegasos(c int, rng *rand.Rand, classWeights []float64) ([]float64, float64) {
+	// the weights and bias are stored divided by scale, see sgdState
+	w := make([]float64, featureSize(svm.Features, svm.samples))
+	var b float64
+	scale := 1.0
+
+	order := rng.Perm(len(svm.samples))
+	t := 0
+	for epoch := 0; epoch < svm.Epochs; epoch++ {
+		rng.Shuffle(len(order), func(i, j int) { order[i], order[j] = order[j], order[i] })
+		for _, i := range order {
+			t++
+			// t + 1 rather than t, so the first step doesn't shrink everything to 0
+			eta := 1 / (svm.Lambda * float64(t+1))
+			x, y := svm.samples[i], -1.0
+			if svm.labels[i] == c {
+				y = 1
+			}
+			margin := y * scale * (x.Dot(w) + b)
+
+			scale *= 1 - eta*svm.Lambda
+			if margin < 1 {
+				step := eta * y * classWeights[svm.labels[i]] / scale
+				x.AddTo(w, step)
+				b += step
+			}
+			if scale < 1e-9 {
+				for f := range w {
+					w[f] *= scale
+				}
+				b *= scale
+				scale = 1
+			}
+		}
+	}
+	for f := range w {
+		w[f] *= scale
+	}
+	return w, b * scale
+}
+
+// DecisionFunction returns the score of the machine of every class, positive
+// when the machine puts the text on the side of its class.
+func (svm *LinearSVM) DecisionFunction(text string) map[string]float64 {
+	return svm.DecisionFunctionVector(svm.Features.Vector(text, false))
+}
+
+// DecisionFunctionVector is DecisionFunction for a feature vector, see LearnVector.
+func (svm *LinearSVM) DecisionFunctionVector(x sparse.Vector) map[string]float64 {
+	scores := make(map[string]float64, len(svm.Classes))
+	for c, score := range svm.scores(x) {
+		scores[svm.Classes[c]] = score
+	}
+	return scores
+}
+
+func (svm *LinearSVM) scores(x sparse.Vector) []float64 {
+	scores := make([]float64, len(svm.Weights))
+	for c, w := range svm.Weights {
+		scores[c] = x.Dot(w) + svm.Bias[c]
+	}
+	return scores
+}
+
+/**
+ * Determine what class `text` belongs to, and the decision score of its
+ * machine. An untrained model returns an empty class.
+ */
+func (svm *LinearSVM) Classify(text string) (string, float64) {
+	return svm.ClassifyVector(svm.Features.Vector(text, false))
+}
+
+// ClassifyVector is Classify for a feature vector, see LearnVector.
+func (svm *LinearSVM) ClassifyVector(x sparse.Vector) (string, float64) {
+	if len(svm.Weights) == 0 {
+		return "", 0
+	}
+	best, bestScore := 0, math.Inf(-1)
+	for c, score := range svm.scores(x) {
+		if score > bestScore {
+			best, bestScore = c, score
+		}
+	}
+	return svm.Classes[best], bestScore
+}
+
+// Fit is LearnBatch.
+func (svm *LinearSVM) Fit(texts []string, labels []string) error {
+	return svm.LearnBatch(texts, labels)
+}
+
+// Predict is Classify.
+func (svm *LinearSVM) Predict(text string) (string, error) {
+	class, _ := svm.Classify(text)
+	if class == "" {
+		return "", ErrNotClassified
+	}
+	return class, nil
+}
+
+//save to a file
+func (svm *LinearSVM) Save(file string) error {
+
+	buf := new(bytes.Buffer)
+	encoder := gob.NewEncoder(buf)
+
+	err := encoder.Encode(svm)
+	if err != nil {
+		return fmt.Errorf("error encoding model: %s", err)
+	}
+
+	name, version := svm.getMeta()
+	persist.Save(file, persist.Modeldata{
+		Data:    buf.Bytes(),
+		Name:    name,
+		Version: version,
+	})
+	return nil
+}
+
+// Load from the output file.
+func (svm *LinearSVM) Load(filePath string) error {
+	log.Printf("Loading Classifier from %s...", filePath)
+	meta := persist.Load(filePath)
+	//get the classifier current meta data
+	name, version := svm.getMeta()
+	if meta.Name != name {
+		return fmt.Errorf("This file doesn't contain a LinearSVM classifier")
+	}
+	if meta.Version != version {
+		return fmt.Errorf("Can't understand this file format")
+	}
+
+	// decode into a fresh one, gob leaves the fields saved as zero values untouched
+	var loaded LinearSVM
+	decoder := gob.NewDecoder(bytes.NewBuffer(meta.Data))
+	err := decoder.Decode(&loaded)
+	if err != nil {
+		return fmt.Errorf("error decoding checkpoint file: %s", err)
+	}
+	if loaded.Features == nil {
+		loaded.Features = NewTextFeatures()
+	}
+	*svm = loaded
+
+	checkpointFile = filePath
+	return nil
+}
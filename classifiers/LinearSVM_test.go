@@ -0,0 +1,62 @@
+package classifiers
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestLinearSVMClassify(t *testing.T) {
+	for _, bits := range []int{0, 14} {
+		svm := NewLinearSVM()
+		svm.Features.HashBits = bits
+		if err := svm.LearnBatch(textTrain, textLabels); err != nil {
+			t.Fatal(err)
+		}
+		for text, want := range textTest {
+			got, score := svm.Classify(text)
+			if got != want {
+				t.Errorf("hash bits %d, %q: got %s (%f), want %s", bits, text, got, score, want)
+			}
+			if scores := svm.DecisionFunction(text); scores[got] != score || len(scores) != 3 {
+				t.Errorf("hash bits %d, %q: got scores %v", bits, text, scores)
+			}
+		}
+	}
+}
+
+func TestLinearSVMClassWeights(t *testing.T) {
+	texts := []string{"free prize", "free money now", "lunch at noon", "meeting at noon",
+		"see you at lunch", "call me later", "dinner tonight"}
+	labels := []string{"spam", "spam", "ham", "ham", "ham", "ham", "ham"}
+
+	svm := NewLinearSVM()
+	svm.ClassWeights = map[string]float64{"spam": 10}
+	svm.LearnBatch(texts, labels)
+	weighted := svm.DecisionFunction("free lunch")["spam"]
+
+	svm = NewLinearSVM()
+	svm.LearnBatch(texts, labels)
+	if plain := svm.DecisionFunction("free lunch")["spam"]; weighted <= plain {
+		t.Errorf("weighting spam scored %f, without %f", weighted, plain)
+	}
+}
+
+func TestLinearSVMSaveLoad(t *testing.T) {
+	svm := NewLinearSVM()
+	svm.LearnBatch(textTrain, textLabels)
+	file := filepath.Join(t.TempDir(), "svm.joi")
+	if err := svm.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewLinearSVM()
+	if err := loaded.Load(file); err != nil {
+		t.Fatal(err)
+	}
+	for text := range textTest {
+		want, p := svm.Classify(text)
+		got, q := loaded.Classify(text)
+		if got != want || p != q {
+			t.Errorf("%q: got %s %f, want %s %f", text, got, q, want, p)
+		}
+	}
+}
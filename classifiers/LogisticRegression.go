@@ -0,0 +1,496 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package classifiers
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"github.com/broosaction/gotext/utils/persist"
+	"github.com/broosaction/gotext/utils/sparse"
+	"log"
+	"math"
+	"math/rand"
+)
+
+var (
+	errOneClass = errors.New("at least two classes are needed to train")
+)
+
+/**
+ * Logistic Regression
+ *
+ * A multinomial logistic regression, also known as a maximum entropy
+ * classifier, over sparse text features. Every class has a weight per
+ * feature and the probability of a class is the softmax of the weighted sums.
+ * The weights are trained with stochastic gradient descent on the log loss,
+ * with optional L1 (sparse weights) and L2 (small weights) regularization.
+ *
+ * Unlike NaiveBayes, learning a text only stores it, Train fits the weights
+ * on everything learned so far. Training again after more Learn calls starts
+ * from the current weights.
+ *
+ * @category    Machine Learning
+
+  **usage
+	lr := classifiers.NewLogisticRegression()
+
+	lr.Learn("amazing, awesome movie!! Yeah!! Oh boy.", "positive")
+	lr.Learn("Sweet, this is incredibly, amazing, perfect, great!!", "positive")
+	lr.Learn("terrible, shitty thing. Damn. Sucks!!", "negative")
+	lr.Train()
+
+	fmt.Println(lr.Classify("awesome, cool shitty thing"))
+*/
+type LogisticRegression struct {
+	// How texts become feature vectors.
+	Features *TextFeatures
+
+	// The known classes, in the order they were first learned.
+	Classes []string
+
+	// Weights of every class, one per feature.
+	Weights [][]float64
+
+	// The intercept of every class.
+	Bias []float64
+
+	// The step size of the first epoch, it decays with 1 / sqrt(epoch).
+	LearningRate float64
+
+	// The maximum number of passes over the training texts.
+	Epochs int
+
+	// Strength of the L1 and L2 penalties on the weights, 0 turns a penalty off.
+	L1, L2 float64
+
+	// Multiplies the loss of the texts of a class, classes not in the map weigh 1.
+	ClassWeights map[string]float64
+
+	// Weigh every class inversely to its number of texts, so rare classes count
+	// as much as frequent ones. ClassWeights still overrides it.
+	Balanced bool
+
+	// Hold out ValidationFraction of the texts and stop once their log loss
+	// stops improving, keeping the best weights. Without it training stops
+	// once the training loss stops improving.
+	EarlyStopping      bool
+	ValidationFraction float64
+
+	// Stop after Patience epochs that improve the monitored loss by less than Tolerance.
+	Patience  int
+	Tolerance float64
+
+	// Seed of the shuffling, the same seed and texts give the same model.
+	Seed int64
+
+	// the learned texts and the index of their class, not saved with the model
+	samples []sparse.Vector
+	labels  []int
+}
+
+func NewLogisticRegression() *LogisticRegression {
+	return &LogisticRegression{
+		Features:           NewTextFeatures(),
+		LearningRate:       0.5,
+		Epochs:             50,
+		L2:                 1e-4,
+		ValidationFraction: 0.1,
+		Patience:           5,
+		Tolerance:          1e-4,
+	}
+}
+
+func (lr *LogisticRegression) getMeta() (string, string) {
+	return "LogisticRegression", "01"
+}
+
+// Learn stores a labelled text for the next Train.
+func (lr *LogisticRegression) Learn(text, class string) {
+	lr.samples = append(lr.samples, lr.Features.Vector(text, true))
+	lr.labels = append(lr.labels, classIndex(&lr.Classes, class))
+}
+
+/**
+ * LearnVector stores a labelled feature vector for the next Train, made by a
+ * vectorizer for instance. The vectors replace Features, so a model should
+ * learn either texts or vectors and classify the same kind.
+ */
+func (lr *LogisticRegression) LearnVector(x sparse.Vector, class string) {
+	lr.samples = append(lr.samples, x)
+	lr.labels = append(lr.labels, classIndex(&lr.Classes, class))
+}
+
+// LearnBatch learns all the texts and trains on them.
+func (lr *LogisticRegression) LearnBatch(texts []string, labels []string) error {
+	if len(texts) != len(labels) {
+		return errNotEqualDataLength
+	}
+	for i, text := range texts {
+		lr.Learn(text, labels[i])
+	}
+	return lr.Train()
+}
+
+// Train fits the weights on all the learned texts.
+func (lr *LogisticRegression) Train() error {
+	if len(lr.samples) == 0 {
+		return errNoSamples
+	}
+	if len(lr.Classes) < 2 {
+		return errOneClass
+	}
+	lr.Weights, lr.Bias = growWeights(lr.Weights, lr.Bias, len(lr.Classes), featureSize(lr.Features, lr.samples))
+
+	rng := rand.New(rand.NewSource(lr.Seed))
+	train, valid := splitValidation(rng, len(lr.samples), lr.EarlyStopping, lr.ValidationFraction)
+	classWeights := weighClasses(lr.labels, lr.Classes, lr.ClassWeights, lr.Balanced)
+
+	s := &sgdState{scale: 1}
+	if lr.L1 > 0 {
+		s.penalties = make([][]float64, len(lr.Weights))
+		for c := range s.penalties {
+			s.penalties[c] = make([]float64, len(lr.Weights[c]))
+		}
+	}
+
+	best, stale := math.Inf(1), 0
+	var bestWeights [][]float64
+	var bestBias []float64
+	for epoch := 0; epoch < lr.Epochs; epoch++ {
+		rng.Shuffle(len(train), func(i, j int) { train[i], train[j] = train[j], train[i] })
+		eta := lr.LearningRate / math.Sqrt(float64(epoch+1))
+
+		var loss float64
+		for _, i := range train {
+			loss += lr.step(s, lr.samples[i], lr.labels[i], eta, classWeights[lr.labels[i]])
+		}
+		lr.flush(s)
+
+		monitored := loss / float64(len(train))
+		if valid != nil {
+			monitored = lr.logLoss(valid)
+		}
+		if monitored < best-lr.Tolerance {
+			best, stale = monitored, 0
+			if valid != nil {
+				bestWeights, bestBias = copyWeights(lr.Weights), append([]float64(nil), lr.Bias...)
+			}
+		} else if stale++; stale >= lr.Patience {
+			break
+		}
+	}
+	if bestWeights != nil {
+		lr.Weights, lr.Bias = bestWeights, bestBias
+	}
+	return nil
+}
+
+/**
+ * sgdState holds the bookkeeping that keeps a step proportional to the
+ * features of one text instead of to all the weights.
+ *
+ * The L2 penalty shrinks every weight by the same factor, so the weights are
+ * stored divided by scale and shrinking only updates scale. The L1 penalty
+ * follows Tsuruoka et al. (2009): total is the penalty every weight should
+ * have received so far and penalties what each one actually received, the
+ * difference is applied whenever a feature is touched.
+ */
+type sgdState struct {
+	scale     float64
+	total     float64
+	penalties [][]float64
+}
+
+// step does one gradient step on a text and returns its weighted loss.
+func (lr *LogisticRegression) step(s *sgdState, x sparse.Vector, y int, eta, weight float64) float64 {
+	p := softmax(lr.scores(x, s.scale))
+	loss := -math.Log(math.Max(p[y], 1e-15)) * weight
+
+	if lr.L2 > 0 {
+		s.scale *= math.Max(0, 1-eta*lr.L2)
+		if s.scale < 1e-9 {
+			lr.flush(s)
+		}
+	}
+	for c := range lr.Weights {
+		g := p[c]
+		if c == y {
+			g--
+		}
+		g *= weight
+		if g == 0 {
+			continue
+		}
+		x.AddTo(lr.Weights[c], -eta*g/s.scale)
+		lr.Bias[c] -= eta * g
+	}
+	if lr.L1 > 0 {
+		s.total += eta * lr.L1
+		for c := range lr.Weights {
+			for _, f := range x.Indices {
+				lr.clip(s, c, f)
+			}
+		}
+	}
+	return loss
+}
+
+// clip applies the L1 penalty weight f of class c still owes, without crossing 0.
+func (lr *LogisticRegression) clip(s *sgdState, c, f int) {
+	w := s.scale * lr.Weights[c][f]
+	clipped := w
+	if w > 0 {
+		clipped = math.Max(0, w-(s.total+s.penalties[c][f]))
+	} else if w < 0 {
+		clipped = math.Min(0, w+(s.total-s.penalties[c][f]))
+	}
+	s.penalties[c][f] += clipped - w
+	lr.Weights[c][f] = clipped / s.scale
+}
+
+// flush folds scale back into the weights and settles the L1 penalty of every weight.
+func (lr *LogisticRegression) flush(s *sgdState) {
+	for c := range lr.Weights {
+		for f := range lr.Weights[c] {
+			lr.Weights[c][f] *= s.scale
+		}
+	}
+	s.scale = 1
+	if lr.L1 > 0 {
+		for c := range lr.Weights {
+			for f := range lr.Weights[c] {
+				lr.clip(s, c, f)
+			}
+		}
+	}
+}
+
+func (lr *LogisticRegression) scores(x sparse.Vector, scale float64) []float64 {
+	scores := make([]float64, len(lr.Weights))
+	for c, w := range lr.Weights {
+		scores[c] = scale*x.Dot(w) + lr.Bias[c]
+	}
+	return scores
+}
+
+// logLoss is the mean unweighted log loss of the given texts.
+func (lr *LogisticRegression) logLoss(samples []int) float64 {
+	var loss float64
+	for _, i := range samples {
+		p := softmax(lr.scores(lr.samples[i], 1))
+		loss -= math.Log(math.Max(p[lr.labels[i]], 1e-15))
+	}
+	return loss / float64(len(samples))
+}
+
+// Probabilities returns the probability of every class for the text.
+func (lr *LogisticRegression) Probabilities(text string) map[string]float64 {
+	return lr.ProbabilitiesVector(lr.Features.Vector(text, false))
+}
+
+// ProbabilitiesVector returns the probability of every class for a feature vector, see LearnVector.
+func (lr *LogisticRegression) ProbabilitiesVector(x sparse.Vector) map[string]float64 {
+	probabilities := make(map[string]float64, len(lr.Classes))
+	if len(lr.Weights) == 0 {
+		return probabilities
+	}
+	p := softmax(lr.scores(x, 1))
+	for c, class := range lr.Classes {
+		probabilities[class] = p[c]
+	}
+	return probabilities
+}
+
+/**
+ * Determine what class `text` belongs to, and its probability. An untrained
+ * model returns an empty class.
+ */
+func (lr *LogisticRegression) Classify(text string) (string, float64) {
+	return lr.ClassifyVector(lr.Features.Vector(text, false))
+}
+
+// ClassifyVector is Classify for a feature vector, see LearnVector.
+func (lr *LogisticRegression) ClassifyVector(x sparse.Vector) (string, float64) {
+	if len(lr.Weights) == 0 {
+		return "", 0
+	}
+	p := softmax(lr.scores(x, 1))
+	best := 0
+	for c := range p {
+		if p[c] > p[best] {
+			best = c
+		}
+	}
+	return lr.Classes[best], p[best]
+}
+
+// Fit is LearnBatch.
+func (lr *LogisticRegression) Fit(texts []string, labels []string) error {
+	return lr.LearnBatch(texts, labels)
+}
+
+// Predict is Classify.
+func (lr *LogisticRegression) Predict(text string) (string, error) {
+	class, _ := lr.Classify(text)
+	if class == "" {
+		return "", ErrNotClassified
+	}
+	return class, nil
+}
+
+// PredictProba is Probabilities.
+func (lr *LogisticRegression) PredictProba(text string) (map[string]float64, error) {
+	return lr.Probabilities(text), nil
+}
+
+//save to a file
+func (lr *LogisticRegression) Save(file string) error {
+
+	buf := new(bytes.Buffer)
+	encoder := gob.NewEncoder(buf)
+
+	err := encoder.Encode(lr)
+	if err != nil {
+		return fmt.Errorf("error encoding model: %s", err)
+	}
+
+	name, version := lr.getMeta()
+	persist.Save(file, persist.Modeldata{
+		Data:    buf.Bytes(),
+		Name:    name,
+		Version: version,
+	})
+	return nil
+}
+
+// Load from the output file.
+func (lr *LogisticRegression) Load(filePath string) error {
+	log.Printf("Loading Classifier from %s...", filePath)
+	meta := persist.Load(filePath)
+	//get the classifier current meta data
+	name, version := lr.getMeta()
+	if meta.Name != name {
+		return fmt.Errorf("This file doesn't contain a LogisticRegression classifier")
+	}
+	if meta.Version != version {
+		return fmt.Errorf("Can't understand this file format")
+	}
+
+	// decode into a fresh one, gob leaves the fields saved as zero values untouched
+	var loaded LogisticRegression
+	decoder := gob.NewDecoder(bytes.NewBuffer(meta.Data))
+	err := decoder.Decode(&loaded)
+	if err != nil {
+		return fmt.Errorf("error decoding checkpoint file: %s", err)
+	}
+	if loaded.Features == nil {
+		loaded.Features = NewTextFeatures()
+	}
+	*lr = loaded
+
+	checkpointFile = filePath
+	return nil
+}
+
+// softmax turns scores into probabilities, shifted by the largest score so exp can't overflow.
+func softmax(scores []float64) []float64 {
+	max := math.Inf(-1)
+	for _, s := range scores {
+		max = math.Max(max, s)
+	}
+	p := make([]float64, len(scores))
+	var sum float64
+	for c, s := range scores {
+		p[c] = math.Exp(s - max)
+		sum += p[c]
+	}
+	for c := range p {
+		p[c] /= sum
+	}
+	return p
+}
+
+// featureSize is the number of weights per class, enough for the features
+// and for every learned vector.
+func featureSize(features *TextFeatures, samples []sparse.Vector) int {
+	size := features.Size()
+	for _, x := range samples {
+		if x.MaxIndex() >= size {
+			size = x.MaxIndex() + 1
+		}
+	}
+	return size
+}
+
+// classIndex returns the position of class in classes, appending it when it is new.
+func classIndex(classes *[]string, class string) int {
+	for i, c := range *classes {
+		if c == class {
+			return i
+		}
+	}
+	*classes = append(*classes, class)
+	return len(*classes) - 1
+}
+
+// growWeights makes room for new classes and features, keeping the weights learned so far.
+func growWeights(weights [][]float64, bias []float64, classes, features int) ([][]float64, []float64) {
+	for len(weights) < classes {
+		weights = append(weights, nil)
+		bias = append(bias, 0)
+	}
+	for c := range weights {
+		if len(weights[c]) < features {
+			weights[c] = append(weights[c], make([]float64, features-len(weights[c]))...)
+		}
+	}
+	return weights, bias
+}
+
+func copyWeights(weights [][]float64) [][]float64 {
+	out := make([][]float64, len(weights))
+	for c, w := range weights {
+		out[c] = append([]float64(nil), w...)
+	}
+	return out
+}
+
+// weighClasses returns the loss weight of every class.
+func weighClasses(labels []int, classes []string, explicit map[string]float64, balanced bool) []float64 {
+	counts := make([]int, len(classes))
+	for _, y := range labels {
+		counts[y]++
+	}
+	weights := make([]float64, len(classes))
+	for c, class := range classes {
+		weights[c] = 1
+		if balanced && counts[c] > 0 {
+			weights[c] = float64(len(labels)) / float64(len(classes)*counts[c])
+		}
+		if w, ok := explicit[class]; ok {
+			weights[c] = w
+		}
+	}
+	return weights
+}
+
+// splitValidation shuffles the sample indexes and holds out a fraction of them
+// for validation. Nothing is held out when there would be nothing left to train on.
+func splitValidation(rng *rand.Rand, n int, hold bool, fraction float64) (train, valid []int) {
+	order := rng.Perm(n)
+	if !hold {
+		return order, nil
+	}
+	held := int(math.Ceil(float64(n) * fraction))
+	if held < 1 || held >= n {
+		return order, nil
+	}
+	return order[held:], order[:held]
+}
@@ -0,0 +1,124 @@
+package classifiers
+
+import (
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var textTrain = []string{
+	"what is the weather like today", "will it rain tomorrow", "is it sunny outside",
+	"how cold is it this morning", "weather forecast for the weekend", "do I need an umbrella",
+	"play some music", "put on my favourite song", "play the new album",
+	"turn the music up", "skip this song", "play some jazz for me",
+	"set an alarm for seven", "wake me up at six", "cancel my alarm",
+	"set a timer for ten minutes", "remind me in an hour", "turn off the alarm",
+}
+
+var textLabels = []string{
+	"weather", "weather", "weather", "weather", "weather", "weather",
+	"music", "music", "music", "music", "music", "music",
+	"alarm", "alarm", "alarm", "alarm", "alarm", "alarm",
+}
+
+var textTest = map[string]string{
+	"is it going to rain today":    "weather",
+	"play a song":                  "music",
+	"set an alarm for the morning": "alarm",
+}
+
+func TestLogisticRegressionClassify(t *testing.T) {
+	lr := NewLogisticRegression()
+	if err := lr.LearnBatch(textTrain, textLabels); err != nil {
+		t.Fatal(err)
+	}
+	for text, want := range textTest {
+		got, p := lr.Classify(text)
+		if got != want {
+			t.Errorf("%q: got %s (%f), want %s", text, got, p, want)
+		}
+		var total float64
+		for _, p := range lr.Probabilities(text) {
+			total += p
+		}
+		if math.Abs(total-1) > 1e-9 {
+			t.Errorf("%q: probabilities sum to %f", text, total)
+		}
+	}
+}
+
+func TestLogisticRegressionL1(t *testing.T) {
+	dense := NewLogisticRegression()
+	sparse := NewLogisticRegression()
+	sparse.L1 = 0.01
+	dense.LearnBatch(textTrain, textLabels)
+	sparse.LearnBatch(textTrain, textLabels)
+
+	zeros := func(lr *LogisticRegression) int {
+		n := 0
+		for _, w := range lr.Weights {
+			for _, x := range w {
+				if x == 0 {
+					n++
+				}
+			}
+		}
+		return n
+	}
+	if zeros(sparse) <= zeros(dense) {
+		t.Errorf("L1 left %d zero weights, without it %d", zeros(sparse), zeros(dense))
+	}
+}
+
+func TestLogisticRegressionOptions(t *testing.T) {
+	lr := NewLogisticRegression()
+	lr.EarlyStopping = true
+	lr.ValidationFraction = 0.2
+	lr.Balanced = true
+	lr.ClassWeights = map[string]float64{"alarm": 2}
+	lr.Features.MaxN = 2
+	lr.Features.HashBits = 12
+	if err := lr.LearnBatch(textTrain, textLabels); err != nil {
+		t.Fatal(err)
+	}
+	if len(lr.Weights[0]) != 1<<12 {
+		t.Errorf("got %d hashed features", len(lr.Weights[0]))
+	}
+	if got, _ := lr.Classify("play some music"); got != "music" {
+		t.Errorf("got %s", got)
+	}
+
+	one := NewLogisticRegression()
+	one.Learn("hello", "greeting")
+	if err := one.Train(); err != errOneClass {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestLogisticRegressionSaveLoad(t *testing.T) {
+	lr := NewLogisticRegression()
+	lr.LearnBatch(textTrain, textLabels)
+	file := filepath.Join(t.TempDir(), "lr.joi")
+	if err := lr.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	// load over a model trained on other texts with other settings, nothing of it may remain
+	loaded := NewLogisticRegression()
+	loaded.L1 = 0.5
+	loaded.ClassWeights = map[string]float64{"music": 3}
+	loaded.LearnBatch([]string{"cats are good", "dogs are bad"}, []string{"pos", "neg"})
+	if err := loaded.Load(file); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.L1 != 0 || loaded.ClassWeights != nil || !reflect.DeepEqual(loaded.Features.Vocabulary, lr.Features.Vocabulary) {
+		t.Errorf("the model loaded over kept L1 %f, class weights %v or its vocabulary", loaded.L1, loaded.ClassWeights)
+	}
+	for text := range textTest {
+		want, p := lr.Classify(text)
+		got, q := loaded.Classify(text)
+		if got != want || p != q {
+			t.Errorf("%q: got %s %f, want %s %f", text, got, q, want, p)
+		}
+	}
+}
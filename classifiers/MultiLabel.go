@@ -0,0 +1,320 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package classifiers
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"github.com/broosaction/gotext/utils/persist"
+	"log"
+	"math"
+	"sort"
+)
+
+type BinaryRelevanceBase uint8
+
+const (
+	// NaiveBayes, scored by the probability of the label
+	BRB_NaiveBayes BinaryRelevanceBase = iota
+	// LogisticRegression, scored by the probability of the label
+	BRB_LogisticRegression
+	// LinearSVM, scored by the decision function of the label
+	BRB_LinearSVM
+	// AveragedPerceptron, scored by how much the label outscores its absence
+	BRB_AveragedPerceptron
+)
+
+// the two classes of the binary classifier of a label
+const (
+	labelIn  = "in"
+	labelOut = "out"
+)
+
+func init() {
+	// the label models are encoded as interfaces
+	gob.Register(&NaiveBayes{})
+	gob.Register(&LogisticRegression{})
+	gob.Register(&LinearSVM{})
+	gob.Register(&AveragedPerceptron{})
+}
+
+/**
+ * Multi-Label Classifier
+ *
+ * Assigns a set of labels to a text, for texts that belong to several
+ * categories at once. It uses binary relevance: every label gets its own
+ * binary classifier, of the Base type, telling whether the label applies,
+ * and a text gets every label whose score reaches its threshold.
+ *
+ * Learn only stores the text, since every label model must also learn the
+ * texts without the label, Train builds the label models from scratch.
+ *
+ * @category    Machine Learning
+
+  **usage
+	ml := classifiers.NewMultiLabelClassifier(classifiers.BRB_LogisticRegression)
+
+	ml.Learn("I was charged twice and the app crashes", []string{"billing", "tech"})
+	ml.Learn("refund my last invoice", []string{"billing"})
+	ml.Learn("the app crashes on start", []string{"tech"})
+	ml.Train()
+
+	fmt.Println(ml.Classify("the app crashed while paying my invoice"))
+*/
+type MultiLabelClassifier struct {
+	// The kind of binary classifier learned for every label.
+	Base BinaryRelevanceBase
+
+	// The score a label needs to be assigned, 0.5 for the probabilistic bases
+	// and 0 for the others by default.
+	Threshold float64
+
+	// Thresholds of single labels, overriding Threshold.
+	Thresholds map[string]float64
+
+	// The known labels, sorted.
+	Labels []string
+
+	// The binary classifier of every label.
+	Models map[string]interface{}
+
+	// Labels every training text had, they need no model and are always assigned.
+	Always []string
+
+	// The number of training texts.
+	Texts int
+
+	// The mean number of labels of a training text.
+	Cardinality float64
+
+	// Cardinality divided by the number of labels.
+	Density float64
+
+	// The number of training texts with every label.
+	Frequencies map[string]int
+
+	// the learned texts and their labels, not saved with the model
+	texts     []string
+	labelSets [][]string
+}
+
+func NewMultiLabelClassifier(base BinaryRelevanceBase) *MultiLabelClassifier {
+	threshold := 0.5
+	if base == BRB_LinearSVM || base == BRB_AveragedPerceptron {
+		threshold = 0
+	}
+	return &MultiLabelClassifier{
+		Base:       base,
+		Threshold:  threshold,
+		Thresholds: map[string]float64{},
+	}
+}
+
+func (m *MultiLabelClassifier) getMeta() (string, string) {
+	return "MultiLabelClassifier", "01"
+}
+
+// Learn stores a text and all of its labels for the next Train.
+func (m *MultiLabelClassifier) Learn(text string, labels []string) {
+	set := make([]string, 0, len(labels))
+	seen := make(map[string]bool, len(labels))
+	for _, l := range labels {
+		if !seen[l] {
+			seen[l] = true
+			set = append(set, l)
+		}
+	}
+	m.texts = append(m.texts, text)
+	m.labelSets = append(m.labelSets, set)
+}
+
+// LearnBatch learns all the texts and trains on them.
+func (m *MultiLabelClassifier) LearnBatch(texts []string, labels [][]string) error {
+	if len(texts) != len(labels) {
+		return errNotEqualDataLength
+	}
+	for i, text := range texts {
+		m.Learn(text, labels[i])
+	}
+	return m.Train()
+}
+
+// Train builds the model of every label from all the learned texts.
+func (m *MultiLabelClassifier) Train() error {
+	if len(m.texts) == 0 {
+		return errNoSamples
+	}
+	m.Texts = len(m.texts)
+	m.Frequencies = map[string]int{}
+	var assigned int
+	for _, set := range m.labelSets {
+		for _, l := range set {
+			m.Frequencies[l]++
+		}
+		assigned += len(set)
+	}
+	m.Labels = make([]string, 0, len(m.Frequencies))
+	for l := range m.Frequencies {
+		m.Labels = append(m.Labels, l)
+	}
+	sort.Strings(m.Labels)
+	m.Cardinality = float64(assigned) / float64(m.Texts)
+	m.Density = 0
+	if len(m.Labels) > 0 {
+		m.Density = m.Cardinality / float64(len(m.Labels))
+	}
+
+	m.Models = make(map[string]interface{}, len(m.Labels))
+	m.Always = nil
+	classes := make([]string, len(m.texts))
+	for _, label := range m.Labels {
+		if m.Frequencies[label] == m.Texts {
+			m.Always = append(m.Always, label)
+			continue
+		}
+		for i, set := range m.labelSets {
+			classes[i] = labelOut
+			for _, l := range set {
+				if l == label {
+					classes[i] = labelIn
+				}
+			}
+		}
+		model, err := m.trainLabel(classes)
+		if err != nil {
+			return fmt.Errorf("label %q: %w", label, err)
+		}
+		m.Models[label] = model
+	}
+	return nil
+}
+
+// trainLabel trains a binary classifier of the Base type.
+func (m *MultiLabelClassifier) trainLabel(classes []string) (interface{}, error) {
+	switch m.Base {
+	case BRB_LogisticRegression:
+		model := NewLogisticRegression()
+		return model, model.LearnBatch(m.texts, classes)
+	case BRB_LinearSVM:
+		model := NewLinearSVM()
+		return model, model.LearnBatch(m.texts, classes)
+	case BRB_AveragedPerceptron:
+		model := NewAveragedPerceptron()
+		return model, model.LearnBatch(m.texts, classes)
+	default:
+		model := NewNaiveBayes()
+		for i, text := range m.texts {
+			model.Learn(text, classes[i])
+		}
+		return model, nil
+	}
+}
+
+// labelScore is how strongly a label model says its label applies to the text.
+func labelScore(model interface{}, text string) float64 {
+	switch model := model.(type) {
+	case *NaiveBayes:
+		return model.Probabilities(text)[labelIn]
+	case *LogisticRegression:
+		return model.Probabilities(text)[labelIn]
+	case *LinearSVM:
+		return model.DecisionFunction(text)[labelIn]
+	case *AveragedPerceptron:
+		scores := model.Scores(model.Features(text))
+		return scores[labelIn] - scores[labelOut]
+	}
+	return math.Inf(-1)
+}
+
+// Scores returns the score of every label for the text, labels in Always score +Inf.
+func (m *MultiLabelClassifier) Scores(text string) map[string]float64 {
+	scores := make(map[string]float64, len(m.Labels))
+	for _, label := range m.Always {
+		scores[label] = math.Inf(1)
+	}
+	for label, model := range m.Models {
+		scores[label] = labelScore(model, text)
+	}
+	return scores
+}
+
+// LabelThreshold returns the score the label needs to be assigned.
+func (m *MultiLabelClassifier) LabelThreshold(label string) float64 {
+	if t, ok := m.Thresholds[label]; ok {
+		return t
+	}
+	return m.Threshold
+}
+
+/**
+ * Determine the labels of `text`, those whose score reaches their
+ * threshold, from the highest score down.
+ */
+func (m *MultiLabelClassifier) Classify(text string) []string {
+	scores := m.Scores(text)
+	labels := make([]string, 0, len(scores))
+	for label, score := range scores {
+		if score >= m.LabelThreshold(label) {
+			labels = append(labels, label)
+		}
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if scores[labels[i]] != scores[labels[j]] {
+			return scores[labels[i]] > scores[labels[j]]
+		}
+		return labels[i] < labels[j]
+	})
+	return labels
+}
+
+//save to a file
+func (m *MultiLabelClassifier) Save(file string) error {
+
+	buf := new(bytes.Buffer)
+	encoder := gob.NewEncoder(buf)
+
+	err := encoder.Encode(m)
+	if err != nil {
+		return fmt.Errorf("error encoding model: %s", err)
+	}
+
+	name, version := m.getMeta()
+	persist.Save(file, persist.Modeldata{
+		Data:    buf.Bytes(),
+		Name:    name,
+		Version: version,
+	})
+	return nil
+}
+
+// Load from the output file.
+func (m *MultiLabelClassifier) Load(filePath string) error {
+	log.Printf("Loading Classifier from %s...", filePath)
+	meta := persist.Load(filePath)
+	//get the classifier current meta data
+	name, version := m.getMeta()
+	if meta.Name != name {
+		return fmt.Errorf("This file doesn't contain a MultiLabelClassifier")
+	}
+	if meta.Version != version {
+		return fmt.Errorf("Can't understand this file format")
+	}
+
+	// decode into an empty model, gob leaves out zero values such as a Threshold of 0
+	var loaded MultiLabelClassifier
+	decoder := gob.NewDecoder(bytes.NewBuffer(meta.Data))
+	err := decoder.Decode(&loaded)
+	if err != nil {
+		return fmt.Errorf("error decoding checkpoint file: %s", err)
+	}
+	*m = loaded
+
+	checkpointFile = filePath
+	return nil
+}
@@ -0,0 +1,117 @@
+package classifiers
+
+import (
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var ticketTexts = []string{
+	"I was charged twice for my subscription",
+	"please refund my last invoice",
+	"my invoice shows the wrong amount",
+	"the app crashes when I open it",
+	"the login page shows an error",
+	"the app is very slow today",
+	"my parcel has not arrived yet",
+	"the delivery address on my order is wrong",
+	"where is my parcel",
+	"the app crashed while I was paying my invoice",
+	"I was charged for a parcel that never arrived",
+	"the app shows an error on my delivery address",
+}
+
+var ticketLabels = [][]string{
+	{"billing"}, {"billing"}, {"billing"},
+	{"tech"}, {"tech"}, {"tech"},
+	{"shipping"}, {"shipping"}, {"shipping"},
+	{"billing", "tech"}, {"billing", "shipping"}, {"tech", "shipping", "tech"},
+}
+
+func TestMultiLabelClassifier(t *testing.T) {
+	bases := []BinaryRelevanceBase{BRB_NaiveBayes, BRB_LogisticRegression, BRB_LinearSVM, BRB_AveragedPerceptron}
+	for _, base := range bases {
+		ml := NewMultiLabelClassifier(base)
+		if err := ml.LearnBatch(ticketTexts, ticketLabels); err != nil {
+			t.Fatal(err)
+		}
+		if got := ml.Classify("refund my invoice"); !reflect.DeepEqual(got, []string{"billing"}) {
+			t.Errorf("base %d: got %v", base, got)
+		}
+		got := ml.Classify("the app crashed while I was paying my invoice")
+		if len(got) != 2 || !contains(got, "billing") || !contains(got, "tech") {
+			t.Errorf("base %d: got %v", base, got)
+		}
+	}
+}
+
+func contains(labels []string, label string) bool {
+	for _, l := range labels {
+		if l == label {
+			return true
+		}
+	}
+	return false
+}
+
+func TestMultiLabelStatistics(t *testing.T) {
+	ml := NewMultiLabelClassifier(BRB_NaiveBayes)
+	ml.LearnBatch(ticketTexts, ticketLabels)
+	// the duplicate tech label of the last ticket counts once
+	if ml.Texts != 12 || math.Abs(ml.Cardinality-15.0/12) > 1e-9 || math.Abs(ml.Density-15.0/36) > 1e-9 {
+		t.Errorf("texts %d, cardinality %f, density %f", ml.Texts, ml.Cardinality, ml.Density)
+	}
+	if ml.Frequencies["tech"] != 5 {
+		t.Errorf("frequencies %v", ml.Frequencies)
+	}
+
+	ml.Thresholds["billing"] = 1.1
+	if contains(ml.Classify("refund my invoice"), "billing") {
+		t.Error("the billing threshold was ignored")
+	}
+
+	always := NewMultiLabelClassifier(BRB_LogisticRegression)
+	always.LearnBatch([]string{"a b", "c d"}, [][]string{{"x", "y"}, {"x"}})
+	if !contains(always.Classify("e"), "x") {
+		t.Errorf("got %v", always.Classify("e"))
+	}
+}
+
+func TestMultiLabelSaveLoad(t *testing.T) {
+	ml := NewMultiLabelClassifier(BRB_LinearSVM)
+	ml.LearnBatch(ticketTexts, ticketLabels)
+	file := filepath.Join(t.TempDir(), "multilabel.joi")
+	if err := ml.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewMultiLabelClassifier(BRB_NaiveBayes)
+	if err := loaded.Load(file); err != nil {
+		t.Fatal(err)
+	}
+	text := "the app crashes and my invoice is wrong"
+	if !reflect.DeepEqual(loaded.Scores(text), ml.Scores(text)) || loaded.Threshold != 0 {
+		t.Errorf("got %v, want %v", loaded.Scores(text), ml.Scores(text))
+	}
+}
+
+func TestNaiveBayesProbabilities(t *testing.T) {
+	nb := NewNaiveBayes()
+	for i, text := range textTrain {
+		nb.Learn(text, textLabels[i])
+	}
+	for text, want := range textTest {
+		probs := nb.Probabilities(text)
+		var total float64
+		best := ""
+		for class, p := range probs {
+			total += p
+			if best == "" || p > probs[best] {
+				best = class
+			}
+		}
+		if best != want || math.Abs(total-1) > 1e-9 {
+			t.Errorf("%q: got %v", text, probs)
+		}
+	}
+}
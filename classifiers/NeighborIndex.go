@@ -0,0 +1,189 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package classifiers
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+)
+
+type KNNAlgorithmType uint8
+
+const (
+	// compare the query with every training sample
+	KNNA_BruteForce KNNAlgorithmType = iota
+	// k-dimensional tree, best for low dimensional data
+	KNNA_KDTree
+	// ball tree, keeps working in higher dimensions
+	KNNA_BallTree
+	// KD-tree up to autoKDTreeMaxDim dimensions, ball tree above that
+	KNNA_Auto
+	// approximate search over a HNSW graph, for large sets of dense vectors
+	KNNA_HNSW
+)
+
+const (
+	// number of samples below which a tree node is not split any further
+	defaultLeafSize = 16
+	// above this many dimensions a KD-tree prunes too little to beat a ball tree
+	autoKDTreeMaxDim = 16
+)
+
+// Neighbor is a training sample found by a nearest neighbor search.
+type Neighbor struct {
+	// position of the sample in KNearestNeighbors.Samples
+	Idx int
+	// distance between the sample and the query
+	Dist float64
+}
+
+/**
+ * NeighborIndex answers exact nearest neighbor queries over a fixed set of
+ * samples. Results are always ordered from the nearest to the farthest.
+ */
+type NeighborIndex interface {
+	// Nearest returns the k samples closest to the query.
+	Nearest(query []float64, k int) []Neighbor
+
+	// Within returns every sample at most radius away from the query.
+	Within(query []float64, radius float64) []Neighbor
+}
+
+// distanceMetric bundles a distance function with what an index needs to know
+// to prune the search space with it.
+type distanceMetric struct {
+	dist func(x, y []float64) float64
+
+	// axisBound is a lower bound of the distance between two points that are
+	// diff apart on the given axis. nil when the metric can't be bounded per axis.
+	axisBound func(axis int, diff float64) float64
+
+	// the metric obeys the triangle inequality
+	triangle bool
+
+	// scale maps a point into a space where the metric is the plain Euclidean
+	// distance. nil when there is no such mapping.
+	scale func(x []float64) []float64
+}
+
+// newNeighborIndex builds the index of the given algorithm over the samples.
+func newNeighborIndex(algorithm KNNAlgorithmType, samples [][]float64, m distanceMetric) NeighborIndex {
+	if algorithm == KNNA_Auto {
+		algorithm = KNNA_BallTree
+		if len(samples) > 0 && len(samples[0]) <= autoKDTreeMaxDim && m.axisBound != nil {
+			algorithm = KNNA_KDTree
+		}
+	}
+	switch {
+	case algorithm == KNNA_HNSW && m.scale != nil:
+		return newHNSWIndex(samples, m)
+	case algorithm == KNNA_HNSW:
+		// the graph only knows a few metrics, fall back to an exact index
+		return newNeighborIndex(KNNA_Auto, samples, m)
+	case algorithm == KNNA_KDTree && m.axisBound != nil:
+		return newKDTree(samples, m, defaultLeafSize)
+	case algorithm == KNNA_BallTree && m.triangle:
+		return newBallTree(samples, m, defaultLeafSize)
+	}
+	return &bruteForceIndex{samples: samples, metric: m}
+}
+
+// bruteForceIndex computes the distance to every sample for every query.
+type bruteForceIndex struct {
+	samples [][]float64
+	metric  distanceMetric
+}
+
+func (b *bruteForceIndex) Nearest(query []float64, k int) []Neighbor {
+	sortedDistance := NewSortedDistance(len(b.samples))
+	for i, sample := range b.samples {
+		sortedDistance.Put(i, b.metric.dist(query, sample))
+	}
+	sort.Stable(sortedDistance)
+
+	if k > len(b.samples) {
+		k = len(b.samples)
+	}
+	result := make([]Neighbor, k)
+	for i, idx := range sortedDistance.SelectTopKIdx(k) {
+		result[i] = Neighbor{Idx: idx, Dist: sortedDistance.Dist[i]}
+	}
+	return result
+}
+
+func (b *bruteForceIndex) Within(query []float64, radius float64) []Neighbor {
+	var result []Neighbor
+	for i, sample := range b.samples {
+		if d := b.metric.dist(query, sample); d <= radius {
+			result = append(result, Neighbor{Idx: i, Dist: d})
+		}
+	}
+	sortNeighbors(result)
+	return result
+}
+
+// neighborHeap is a max-heap on distance that keeps the best k candidates,
+// the current worst candidate sits on top.
+type neighborHeap []Neighbor
+
+func (h neighborHeap) Len() int            { return len(h) }
+func (h neighborHeap) Less(i, j int) bool  { return fartherThan(h[i], h[j]) }
+func (h neighborHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *neighborHeap) Push(x interface{}) { *h = append(*h, x.(Neighbor)) }
+func (h *neighborHeap) Pop() interface{} {
+	old := *h
+	n := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return n
+}
+
+// candidates collects the k nearest samples seen so far during a tree search.
+type candidates struct {
+	k    int
+	heap neighborHeap
+}
+
+func (c *candidates) offer(idx int, dist float64) {
+	if c.heap.Len() < c.k {
+		heap.Push(&c.heap, Neighbor{Idx: idx, Dist: dist})
+	} else if n := (Neighbor{Idx: idx, Dist: dist}); fartherThan(c.heap[0], n) {
+		c.heap[0] = n
+		heap.Fix(&c.heap, 0)
+	}
+}
+
+// worst is the distance a subtree has to beat to still be worth visiting.
+func (c *candidates) worst() float64 {
+	if c.heap.Len() < c.k {
+		return math.Inf(1)
+	}
+	return c.heap[0].Dist
+}
+
+func (c *candidates) sorted() []Neighbor {
+	result := make([]Neighbor, len(c.heap))
+	copy(result, c.heap)
+	sortNeighbors(result)
+	return result
+}
+
+// fartherThan orders neighbors by distance, ties are broken by sample position
+// so every index returns the same neighbors as the brute force search.
+func fartherThan(a, b Neighbor) bool {
+	if a.Dist == b.Dist {
+		return a.Idx > b.Idx
+	}
+	return a.Dist > b.Dist
+}
+
+func sortNeighbors(n []Neighbor) {
+	sort.Slice(n, func(i, j int) bool {
+		return fartherThan(n[j], n[i])
+	})
+}
@@ -0,0 +1,100 @@
+package classifiers
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func randomSamples(r *rand.Rand, n, dim int) [][]float64 {
+	samples := make([][]float64, n)
+	for i := range samples {
+		samples[i] = make([]float64, dim)
+		for d := range samples[i] {
+			samples[i][d] = r.Float64() * 10
+		}
+	}
+	return samples
+}
+
+func uniformWeight(dim int) []float64 {
+	w := make([]float64, dim)
+	for i := range w {
+		w[i] = 1
+	}
+	return w
+}
+
+func sameNeighbors(a, b []Neighbor) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Idx != b[i].Idx {
+			return false
+		}
+	}
+	return true
+}
+
+func TestNeighborIndexMatchesBruteForce(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, dim := range []int{2, 8, 32} {
+		samples := randomSamples(r, 1000, dim)
+		queries := randomSamples(r, 50, dim)
+		for _, dm := range []DistanceMethodType{DMT_EulerMethod, DMT_HuffmanMethod, DMT_MinkowskiMethod, DMT_ChebyshevMethod, DMT_MahalanobisMethod} {
+			knn := NewKNearestNeighbors(7, dm, uniformWeight(dim))
+			knn.P = 3
+			if err := knn.LearnBatch(samples, make([]string, len(samples))); err != nil {
+				t.Fatal(err)
+			}
+			brute := newNeighborIndex(KNNA_BruteForce, samples, knn.metric())
+
+			for _, algorithm := range []KNNAlgorithmType{KNNA_KDTree, KNNA_BallTree} {
+				index := newNeighborIndex(algorithm, samples, knn.metric())
+				for _, q := range queries {
+					if !sameNeighbors(index.Nearest(q, 7), brute.Nearest(q, 7)) {
+						t.Fatalf("algorithm %d, dim %d, method %d: nearest neighbors differ from brute force", algorithm, dim, dm)
+					}
+					radius := brute.Nearest(q, 20)[19].Dist
+					if !sameNeighbors(index.Within(q, radius), brute.Within(q, radius)) {
+						t.Fatalf("algorithm %d, dim %d, method %d: radius neighbors differ from brute force", algorithm, dim, dm)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestNeighborIndexSmallTrainingSet(t *testing.T) {
+	samples := [][]float64{{1, 1}, {2, 2}}
+	for _, algorithm := range []KNNAlgorithmType{KNNA_BruteForce, KNNA_KDTree, KNNA_BallTree, KNNA_HNSW} {
+		knn := NewKNearestNeighbors(5, DMT_EulerMethod, []float64{1, 1})
+		knn.Algorithm = algorithm
+		knn.LearnBatch(samples, []string{"a", "b"})
+		if n, _ := knn.Neighbors([]float64{0, 0}); len(n) != 2 || n[0].Idx != 0 {
+			t.Errorf("algorithm %d: got %v", algorithm, n)
+		}
+	}
+}
+
+func benchmarkNeighborIndex(b *testing.B, algorithm KNNAlgorithmType, dim int) {
+	r := rand.New(rand.NewSource(1))
+	samples := randomSamples(r, 20000, dim)
+	queries := randomSamples(r, 100, dim)
+	knn := NewKNearestNeighbors(10, DMT_EulerMethod, uniformWeight(dim))
+	knn.Algorithm = algorithm
+	knn.LearnBatch(samples, make([]string, len(samples)))
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		knn.Neighbors(queries[i%len(queries)])
+	}
+}
+
+func BenchmarkBruteForce3D(b *testing.B) { benchmarkNeighborIndex(b, KNNA_BruteForce, 3) }
+func BenchmarkKDTree3D(b *testing.B)     { benchmarkNeighborIndex(b, KNNA_KDTree, 3) }
+func BenchmarkBallTree3D(b *testing.B)   { benchmarkNeighborIndex(b, KNNA_BallTree, 3) }
+
+func BenchmarkBruteForce32D(b *testing.B) { benchmarkNeighborIndex(b, KNNA_BruteForce, 32) }
+func BenchmarkKDTree32D(b *testing.B)     { benchmarkNeighborIndex(b, KNNA_KDTree, 32) }
+func BenchmarkBallTree32D(b *testing.B)   { benchmarkNeighborIndex(b, KNNA_BallTree, 32) }
@@ -0,0 +1,316 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package classifiers
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"github.com/broosaction/gotext/utils/persist"
+	"log"
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+)
+
+var (
+	errNoTrees = errors.New("a forest needs at least one tree")
+)
+
+/**
+ * Random Forest
+ *
+ * An ensemble of decision trees, each grown on a bootstrap sample of the
+ * training set (drawn with replacement) and looking at a random subset of the
+ * features at every split. The trees are grown in parallel and a prediction
+ * averages their label distributions.
+ *
+ * Every tree leaves about a third of the samples out of its bootstrap sample.
+ * Classifying each sample with only the trees that never saw it gives the
+ * out-of-bag error, an estimate of the test error that needs no held out data.
+ *
+ * @category    Machine Learning
+
+  **usage
+	forest := classifiers.NewRandomForest(100)
+	forest.LearnBatch(train, labels)
+	res, err := forest.Classify(test)
+	fmt.Println(forest.OOBError, forest.FeatureImportances())
+*/
+type RandomForest struct {
+	// The number of trees.
+	NTrees int
+
+	// How the trees measure the purity of a node.
+	Criterion SplitCriterionType
+
+	// The deepest a leaf can be, 0 means no limit.
+	MaxDepth int
+
+	// The fewest training samples a leaf can hold.
+	MinSamplesLeaf int
+
+	// The number of features a split looks at, 0 means the square root of the number of features.
+	MaxFeatures int
+
+	// Grow every tree on a bootstrap sample, otherwise on the whole training set.
+	Bootstrap bool
+
+	// The number of trees grown at the same time, 0 means one per CPU.
+	Workers int
+
+	// Seed of the sampling, tree i uses Seed + i so the forest doesn't depend on Workers.
+	Seed int64
+
+	// The labels, sorted.
+	Classes []string
+
+	// The trees.
+	Trees []*DecisionTree
+
+	// The share of the training samples misclassified by the trees that
+	// didn't see them, NaN when no sample was left out of every bootstrap.
+	OOBError float64
+
+	// The mean feature importance of the trees, summing to 1.
+	Importances []float64
+}
+
+func NewRandomForest(trees int) *RandomForest {
+	return &RandomForest{
+		NTrees:         trees,
+		Criterion:      SCT_Gini,
+		MinSamplesLeaf: 1,
+		Bootstrap:      true,
+	}
+}
+
+func (f *RandomForest) getMeta() (string, string) {
+	return "RandomForest", "01"
+}
+
+// LearnBatch grows the trees on the samples and their labels.
+func (f *RandomForest) LearnBatch(train [][]float64, label []string) error {
+	if len(train) != len(label) {
+		return fmt.Errorf("%d samples but %d labels: %w", len(train), len(label), errNotEqualDataLength)
+	}
+	if err := checkSamples(train, nil, 1); err != nil {
+		return err
+	}
+	if f.NTrees < 1 {
+		return errNoTrees
+	}
+	classes, y := encodeLabels(label)
+	f.Classes = classes
+
+	maxFeatures := f.MaxFeatures
+	if maxFeatures == 0 {
+		maxFeatures = int(math.Max(1, math.Sqrt(float64(len(train[0])))))
+	}
+
+	f.Trees = make([]*DecisionTree, f.NTrees)
+	inBag := make([][]bool, f.NTrees)
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < f.workers(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				f.Trees[i], inBag[i] = f.growTree(train, y, maxFeatures, f.Seed+int64(i))
+			}
+		}()
+	}
+	for i := range f.Trees {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	f.OOBError = f.outOfBagError(train, y, inBag)
+	f.Importances = make([]float64, len(train[0]))
+	for _, tree := range f.Trees {
+		for j, v := range tree.Importances {
+			f.Importances[j] += v / float64(len(f.Trees))
+		}
+	}
+	return nil
+}
+
+func (f *RandomForest) workers() int {
+	if f.Workers > 0 {
+		return f.Workers
+	}
+	return runtime.NumCPU()
+}
+
+// growTree grows one tree and returns it with the samples of its bootstrap sample.
+func (f *RandomForest) growTree(train [][]float64, y []int, maxFeatures int, seed int64) (*DecisionTree, []bool) {
+	rng := rand.New(rand.NewSource(seed))
+	inBag := make([]bool, len(train))
+	samples := make([]int, len(train))
+	for i := range samples {
+		if f.Bootstrap {
+			samples[i] = rng.Intn(len(train))
+		} else {
+			samples[i] = i
+		}
+		inBag[samples[i]] = true
+	}
+	tree := &DecisionTree{
+		Criterion:      f.Criterion,
+		MaxDepth:       f.MaxDepth,
+		MinSamplesLeaf: f.MinSamplesLeaf,
+		MaxFeatures:    maxFeatures,
+		Seed:           seed,
+	}
+	tree.fit(train, y, f.Classes, samples, rng)
+	return tree, inBag
+}
+
+// outOfBagError classifies every sample with the trees that didn't see it.
+func (f *RandomForest) outOfBagError(train [][]float64, y []int, inBag [][]bool) float64 {
+	var wrong, voted int
+	for i, sample := range train {
+		sum := make([]float64, len(f.Classes))
+		trees := 0
+		for t, tree := range f.Trees {
+			if inBag[t][i] {
+				continue
+			}
+			p, _ := tree.distribution(sample)
+			for c := range sum {
+				sum[c] += p[c]
+			}
+			trees++
+		}
+		if trees == 0 {
+			continue
+		}
+		voted++
+		if argmax(sum) != y[i] {
+			wrong++
+		}
+	}
+	if voted == 0 {
+		return math.NaN()
+	}
+	return float64(wrong) / float64(voted)
+}
+
+// distribution averages the label distributions of the trees for a single test vector.
+func (f *RandomForest) distribution(test []float64) ([]float64, error) {
+	if len(f.Trees) == 0 {
+		return nil, errNoSamples
+	}
+	sum := make([]float64, len(f.Classes))
+	for _, tree := range f.Trees {
+		p, err := tree.distribution(test)
+		if err != nil {
+			return nil, err
+		}
+		for c := range sum {
+			sum[c] += p[c] / float64(len(f.Trees))
+		}
+	}
+	return sum, nil
+}
+
+// Probabilities of every label for each test vector, averaged over the trees.
+func (f *RandomForest) Probabilities(test [][]float64) ([]map[string]float64, error) {
+	result := make([]map[string]float64, len(test))
+	for j, _test := range test {
+		p, err := f.distribution(_test)
+		if err != nil {
+			return nil, fmt.Errorf("test data %d: %w", j, err)
+		}
+		result[j] = labelMap(f.Classes, p)
+	}
+	return result, nil
+}
+
+// Classify returns the most probable label of every test vector.
+func (f *RandomForest) Classify(test [][]float64) ([]string, error) {
+	result := make([]string, len(test))
+	for j, _test := range test {
+		p, err := f.distribution(_test)
+		if err != nil {
+			return nil, fmt.Errorf("test data %d: %w", j, err)
+		}
+		result[j] = f.Classes[argmax(p)]
+	}
+	return result, nil
+}
+
+// FeatureImportances returns the mean feature importance of the trees, summing to 1.
+func (f *RandomForest) FeatureImportances() []float64 {
+	return f.Importances
+}
+
+// Fit is LearnBatch.
+func (f *RandomForest) Fit(samples [][]float64, labels []string) error {
+	return f.LearnBatch(samples, labels)
+}
+
+// Predict is Classify.
+func (f *RandomForest) Predict(samples [][]float64) ([]string, error) {
+	return f.Classify(samples)
+}
+
+// PredictProba is Probabilities.
+func (f *RandomForest) PredictProba(samples [][]float64) ([]map[string]float64, error) {
+	return f.Probabilities(samples)
+}
+
+//save to a file
+func (f *RandomForest) Save(file string) error {
+
+	buf := new(bytes.Buffer)
+	encoder := gob.NewEncoder(buf)
+
+	err := encoder.Encode(f)
+	if err != nil {
+		return fmt.Errorf("error encoding model: %s", err)
+	}
+
+	name, version := f.getMeta()
+	persist.Save(file, persist.Modeldata{
+		Data:    buf.Bytes(),
+		Name:    name,
+		Version: version,
+	})
+	return nil
+}
+
+// Load from the output file.
+func (f *RandomForest) Load(filePath string) error {
+	log.Printf("Loading Classifier from %s...", filePath)
+	meta := persist.Load(filePath)
+	//get the classifier current meta data
+	name, version := f.getMeta()
+	if meta.Name != name {
+		return fmt.Errorf("This file doesn't contain a RandomForest classifier")
+	}
+	if meta.Version != version {
+		return fmt.Errorf("Can't understand this file format")
+	}
+
+	// decode into a fresh one, gob leaves the fields saved as zero values untouched
+	var loaded RandomForest
+	decoder := gob.NewDecoder(bytes.NewBuffer(meta.Data))
+	err := decoder.Decode(&loaded)
+	if err != nil {
+		return fmt.Errorf("error decoding checkpoint file: %s", err)
+	}
+	*f = loaded
+
+	checkpointFile = filePath
+	return nil
+}
@@ -0,0 +1,93 @@
+package classifiers
+
+import (
+	"math"
+	"math/rand"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// xorData labels points by the quadrant they are in, which no linear model
+// can learn. The third feature is noise.
+func xorData(n int, seed int64) ([][]float64, []string) {
+	rng := rand.New(rand.NewSource(seed))
+	train := make([][]float64, n)
+	labels := make([]string, n)
+	for i := range train {
+		x, y := rng.Float64()*2-1, rng.Float64()*2-1
+		train[i] = []float64{x, y, rng.Float64()}
+		if (x > 0) == (y > 0) {
+			labels[i] = "same"
+		} else {
+			labels[i] = "different"
+		}
+	}
+	return train, labels
+}
+
+func accuracy(t *testing.T, res []string, labels []string) float64 {
+	t.Helper()
+	right := 0
+	for i := range res {
+		if res[i] == labels[i] {
+			right++
+		}
+	}
+	return float64(right) / float64(len(res))
+}
+
+func TestRandomForest(t *testing.T) {
+	train, labels := xorData(400, 1)
+	test, testLabels := xorData(200, 2)
+
+	forest := NewRandomForest(30)
+	if err := forest.LearnBatch(train, labels); err != nil {
+		t.Fatal(err)
+	}
+	res, err := forest.Classify(test)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if acc := accuracy(t, res, testLabels); acc < 0.9 {
+		t.Errorf("test accuracy %f", acc)
+	}
+	if math.IsNaN(forest.OOBError) || forest.OOBError > 0.15 {
+		t.Errorf("out-of-bag error %f", forest.OOBError)
+	}
+	imp := forest.FeatureImportances()
+	if imp[2] > imp[0] || imp[2] > imp[1] || math.Abs(imp[0]+imp[1]+imp[2]-1) > 1e-9 {
+		t.Errorf("importances %v", imp)
+	}
+
+	probs, _ := forest.Probabilities(test[:1])
+	if math.Abs(probs[0]["same"]+probs[0]["different"]-1) > 1e-9 {
+		t.Errorf("probabilities %v", probs[0])
+	}
+
+	serial := NewRandomForest(30)
+	serial.Workers = 1
+	serial.LearnBatch(train, labels)
+	if !reflect.DeepEqual(serial.Trees, forest.Trees) {
+		t.Error("the forest depends on the number of workers")
+	}
+}
+
+func TestRandomForestSaveLoad(t *testing.T) {
+	train, labels := xorData(100, 1)
+	forest := NewRandomForest(5)
+	forest.LearnBatch(train, labels)
+	file := filepath.Join(t.TempDir(), "forest.joi")
+	if err := forest.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewRandomForest(0)
+	if err := loaded.Load(file); err != nil {
+		t.Fatal(err)
+	}
+	want, _ := forest.Probabilities(train)
+	got, err := loaded.Probabilities(train)
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded forest differs: %v", err)
+	}
+}
@@ -0,0 +1,310 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package classifiers
+
+import (
+	"errors"
+	"fmt"
+	"github.com/broosaction/gotext/utils/types"
+	"math"
+	"sort"
+	"strings"
+)
+
+var (
+	errNoLabeledClasses = errors.New("the model must learn labeled texts of every class first")
+)
+
+// EMOptions tune NaiveBayes.EM, zero values take the defaults.
+type EMOptions struct {
+	// The most rounds of expectation and maximization, 10 by default.
+	MaxIterations int
+
+	// EM stops once the log-likelihood grows by less than this share, 1e-4 by default.
+	Tolerance float64
+
+	// How much an unlabeled text counts next to a labeled one, 1 by default.
+	// Lower it when the unlabeled texts outnumber the labeled ones by far,
+	// so they don't drown the labels.
+	UnlabeledWeight float64
+}
+
+// EMResult tells how EM went, one entry per round.
+type EMResult struct {
+	Iterations int
+
+	// The log-likelihood of the unlabeled texts under the model of every
+	// round. Words the model never learned are skipped, so the first round,
+	// before the unlabeled words are learned, doesn't compare with the next.
+	LogLikelihoods []float64
+
+	// The mean absolute change of the class probabilities of the unlabeled
+	// texts since the previous round, 0 for the first round.
+	Changes []float64
+
+	// Whether the log-likelihood settled before MaxIterations.
+	Converged bool
+}
+
+/**
+ * EM improves a NaiveBayes model with unlabeled texts, by Expectation-
+ * Maximization (Nigam et al., 2000). The model must have learned labeled
+ * texts of every class. Every round, the E step gives each unlabeled text
+ * the probability of every class under the current model, then the M step
+ * rebuilds the model from the labeled counts plus every unlabeled text
+ * learned as a fraction of each class, see LearnWeighted.
+ *
+ * The model keeps the weighted counts of the last round. Like every Learn,
+ * the labeled texts learned afterwards add to them.
+ *
+ * @category    Machine Learning
+
+  **usage
+	nb := classifiers.NewNaiveBayes()
+	nb.Fit(labeledTexts, labels)
+	res, err := nb.EM(unlabeledTexts, classifiers.EMOptions{UnlabeledWeight: 0.1})
+	fmt.Println(res.Iterations, res.Converged, res.LogLikelihoods)
+*/
+func (nb *NaiveBayes) EM(unlabeled []string, opts EMOptions) (EMResult, error) {
+	if len(nb.classes) == 0 {
+		return EMResult{}, errNoLabeledClasses
+	}
+	maxIterations, tolerance, lambda := opts.MaxIterations, opts.Tolerance, opts.UnlabeledWeight
+	if maxIterations <= 0 {
+		maxIterations = 10
+	}
+	if tolerance <= 0 {
+		tolerance = 1e-4
+	}
+	if lambda <= 0 {
+		lambda = 1
+	}
+
+	tokens := make([][]string, len(unlabeled))
+	for i, text := range unlabeled {
+		tokens[i] = nb.tokenize(strings.ToLower(text))
+	}
+	labeled := nb.copyCounts()
+	classes := make([]string, 0, len(nb.classes))
+	for name := range nb.classes {
+		classes = append(classes, name)
+	}
+	sort.Strings(classes)
+
+	var result EMResult
+	previous := make([]map[string]float64, len(unlabeled))
+	for result.Iterations < maxIterations {
+		// E step
+		totals := nb.totals()
+		var logLikelihood, change float64
+		posteriors := make([]map[string]float64, len(unlabeled))
+		for i := range tokens {
+			var logP float64
+			posteriors[i], logP = nb.posterior(tokens[i], totals)
+			logLikelihood += logP
+			if previous[i] == nil {
+				continue
+			}
+			for _, c := range classes {
+				change += math.Abs(posteriors[i][c] - previous[i][c])
+			}
+		}
+		if len(unlabeled) > 0 {
+			change /= float64(len(unlabeled) * len(classes))
+		}
+		result.LogLikelihoods = append(result.LogLikelihoods, logLikelihood)
+		result.Changes = append(result.Changes, change)
+		result.Iterations++
+
+		if n := len(result.LogLikelihoods); n > 1 {
+			last := result.LogLikelihoods[n-2]
+			if math.Abs(logLikelihood-last) <= tolerance*math.Abs(last) {
+				result.Converged = true
+				break
+			}
+		}
+
+		// M step
+		nb.restoreCounts(labeled)
+		for i := range tokens {
+			for _, c := range classes {
+				// tiny shares change nothing but would add the words to the class
+				if p := posteriors[i][c]; p > 1e-6 {
+					nb.learnTokens(tokens[i], c, lambda*p)
+				}
+			}
+		}
+		previous = posteriors
+	}
+	return result, nil
+}
+
+// nbCounts is a copy of what a NaiveBayes model learned.
+type nbCounts struct {
+	words          map[string]wordFrequency
+	classes        map[string]Class
+	vocabularySize int
+}
+
+func (nb *NaiveBayes) copyCounts() nbCounts {
+	return nbCounts{words: copyWords(nb.words), classes: copyClasses(nb.classes), vocabularySize: nb.vocabularySize}
+}
+
+// restoreCounts sets the model back to the counts, which stay untouched.
+func (nb *NaiveBayes) restoreCounts(c nbCounts) {
+	nb.words = copyWords(c.words)
+	nb.classes = copyClasses(c.classes)
+	nb.vocabularySize = c.vocabularySize
+}
+
+func copyWords(words map[string]wordFrequency) map[string]wordFrequency {
+	out := make(map[string]wordFrequency, len(words))
+	for w, wf := range words {
+		counter := make(map[string]float64, len(wf.Counter))
+		for class, n := range wf.Counter {
+			counter[class] = n
+		}
+		out[w] = wordFrequency{Word: wf.Word, Counter: counter}
+	}
+	return out
+}
+
+func copyClasses(classes map[string]Class) map[string]Class {
+	out := make(map[string]Class, len(classes))
+	for name, class := range classes {
+		words := make(map[string]types.Word, len(class.Words))
+		for w, word := range class.Words {
+			words[w] = word
+		}
+		class.Words = words
+		out[name] = class
+	}
+	return out
+}
+
+/**
+ * Self-Training
+ *
+ * Grows any probabilistic text classifier with unlabeled texts. After
+ * learning the labeled texts, every round predicts the unlabeled ones and
+ * learns those predicted with at least Threshold probability as if they
+ * were labeled, most confident first, until a round adds none. Unlike EM a
+ * text is learned once, with a hard label, and never revisited, so keep
+ * the threshold high, wrong pseudo-labels reinforce themselves. Every round
+ * Fits the model with the texts it added, which the text classifiers learn
+ * on top of the labeled texts and the earlier rounds.
+ *
+ * @category    Machine Learning
+
+  **usage
+	st := classifiers.NewSelfTraining(classifiers.NewLogisticRegression())
+	st.Threshold = 0.95
+	res, err := st.Run(labeledTexts, labels, unlabeledTexts)
+	label, _ := st.Model.Predict("play some jazz")
+*/
+type SelfTraining struct {
+	Model ProbabilisticTextClassifier
+
+	// The least probability of a prediction to be learned.
+	Threshold float64
+
+	// The most rounds, 10 by default.
+	MaxIterations int
+
+	// The most texts learned per round, 0 means all those above Threshold.
+	MaxPerIteration int
+}
+
+// SelfTrainingRound tells what a round of self-training did.
+type SelfTrainingRound struct {
+	// The number of unlabeled texts learned this round.
+	Added int
+
+	// The mean probability of the predictions learned.
+	MeanConfidence float64
+
+	// The unlabeled texts left, below the threshold.
+	Remaining int
+}
+
+// SelfTrainingResult tells how self-training went.
+type SelfTrainingResult struct {
+	Rounds []SelfTrainingRound
+
+	// The label learned for every unlabeled text that got one, by position.
+	PseudoLabels map[int]string
+
+	// Whether a round added no text before MaxIterations.
+	Converged bool
+}
+
+func NewSelfTraining(model ProbabilisticTextClassifier) *SelfTraining {
+	return &SelfTraining{
+		Model:         model,
+		Threshold:     0.9,
+		MaxIterations: 10,
+	}
+}
+
+// Run learns the labeled texts, then the unlabeled texts the model gets confident about.
+func (s *SelfTraining) Run(texts, labels, unlabeled []string) (SelfTrainingResult, error) {
+	result := SelfTrainingResult{PseudoLabels: make(map[int]string)}
+	if err := s.Model.Fit(texts, labels); err != nil {
+		return result, err
+	}
+	maxIterations := s.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = 10
+	}
+
+	type candidate struct {
+		at         int
+		label      string
+		confidence float64
+	}
+	for round := 0; round < maxIterations; round++ {
+		var picked []candidate
+		for i, text := range unlabeled {
+			if _, ok := result.PseudoLabels[i]; ok {
+				continue
+			}
+			probabilities, err := s.Model.PredictProba(text)
+			if err != nil {
+				return result, fmt.Errorf("unlabeled text %d: %w", i, err)
+			}
+			label, err := bestLabel(probabilities)
+			if err != nil || probabilities[label] < s.Threshold {
+				continue
+			}
+			picked = append(picked, candidate{i, label, probabilities[label]})
+		}
+		sort.SliceStable(picked, func(i, j int) bool { return picked[i].confidence > picked[j].confidence })
+		if s.MaxPerIteration > 0 && len(picked) > s.MaxPerIteration {
+			picked = picked[:s.MaxPerIteration]
+		}
+		if len(picked) == 0 {
+			result.Converged = true
+			break
+		}
+
+		r := SelfTrainingRound{Added: len(picked)}
+		newTexts := make([]string, len(picked))
+		newLabels := make([]string, len(picked))
+		for k, c := range picked {
+			newTexts[k], newLabels[k] = unlabeled[c.at], c.label
+			result.PseudoLabels[c.at] = c.label
+			r.MeanConfidence += c.confidence / float64(len(picked))
+		}
+		if err := s.Model.Fit(newTexts, newLabels); err != nil {
+			return result, err
+		}
+		r.Remaining = len(unlabeled) - len(result.PseudoLabels)
+		result.Rounds = append(result.Rounds, r)
+	}
+	return result, nil
+}
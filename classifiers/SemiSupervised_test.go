@@ -0,0 +1,105 @@
+package classifiers
+
+import (
+	"bytes"
+	"encoding/gob"
+	"math"
+	"testing"
+)
+
+var (
+	emLabeled   = []string{"rain forecast today", "jazz song please"}
+	emLabels    = []string{"weather", "music"}
+	emUnlabeled = []string{
+		"rain forecast umbrella", "umbrella drizzle forecast", "drizzle and rain today",
+		"jazz song guitar", "guitar drums song", "drums and jazz please",
+	}
+)
+
+func TestLearnWeighted(t *testing.T) {
+	twice := NewNaiveBayes()
+	weighted := NewNaiveBayes()
+	for _, nb := range []*NaiveBayes{twice, weighted} {
+		nb.Learn("play the song", "music")
+		nb.Learn("rain today", "weather")
+	}
+	twice.Learn("jazz song", "music")
+	twice.Learn("jazz song", "music")
+	weighted.LearnWeighted("jazz song", "music", 2)
+
+	a, b := twice.Probabilities("jazz rain"), weighted.Probabilities("jazz rain")
+	for class := range a {
+		if math.Abs(a[class]-b[class]) > 1e-12 {
+			t.Errorf("%s: %f learned twice, %f with weight 2", class, a[class], b[class])
+		}
+	}
+}
+
+func TestNaiveBayesEM(t *testing.T) {
+	nb := NewNaiveBayes()
+	if _, err := nb.EM(emUnlabeled, EMOptions{}); err != errNoLabeledClasses {
+		t.Errorf("got %v", err)
+	}
+	nb.Fit(emLabeled, emLabels)
+	res, err := nb.EM(emUnlabeled, EMOptions{MaxIterations: 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Iterations < 2 || len(res.LogLikelihoods) != res.Iterations || len(res.Changes) != res.Iterations {
+		t.Fatalf("result %+v", res)
+	}
+	// from the second round on the vocabulary stays the same and EM can't lose likelihood
+	for i := 2; i < len(res.LogLikelihoods); i++ {
+		if res.LogLikelihoods[i] < res.LogLikelihoods[i-1]-1e-9 {
+			t.Errorf("the log-likelihood fell: %v", res.LogLikelihoods)
+		}
+	}
+	// the words only seen in unlabeled texts were learned through their neighbours
+	for text, want := range map[string]string{"umbrella drizzle": "weather", "guitar drums": "music"} {
+		if got, _ := nb.Predict(text); got != want {
+			t.Errorf("%q: got %s, want %s (%v)", text, got, want, nb.Probabilities(text))
+		}
+	}
+}
+
+func TestSelfTraining(t *testing.T) {
+	st := NewSelfTraining(NewNaiveBayes())
+	st.Threshold = 0.6
+	res, err := st.Run(emLabeled, emLabels, emUnlabeled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Rounds) == 0 || res.Rounds[0].Added == 0 || res.Rounds[0].MeanConfidence < st.Threshold {
+		t.Fatalf("result %+v", res)
+	}
+	if res.PseudoLabels[0] != "weather" || res.PseudoLabels[3] != "music" {
+		t.Errorf("pseudo labels %v", res.PseudoLabels)
+	}
+	last := res.Rounds[len(res.Rounds)-1]
+	if last.Remaining != len(emUnlabeled)-len(res.PseudoLabels) {
+		t.Errorf("%d remaining, %d pseudo labels", last.Remaining, len(res.PseudoLabels))
+	}
+}
+
+func TestNaiveBayesDecodesVersion01(t *testing.T) {
+	var b bytes.Buffer
+	encoder := gob.NewEncoder(&b)
+	words := map[string]v01WordFrequency{"rain": {Word: savedWord{Text: "rain"}, Counter: map[string]int{"weather": 2}}}
+	classes := map[string]v01Class{"weather": {Name: "weather", Counter: 2, Words: map[string]savedWord{"rain": {Text: "rain"}}}}
+	for _, data := range []interface{}{words, classes, 2, struct {
+		Amount float64
+		Class  v01Class
+	}{}, "DefaultTokenizer"} {
+		if err := encoder.Encode(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	nb := NewNaiveBayes()
+	if err := nb.GobDecode(b.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	if nb.words["rain"].Counter["weather"] != 2 || nb.classes["weather"].Counter != 2 || nb.Smoothing() != 1 {
+		t.Errorf("decoded %+v %+v", nb.words, nb.classes)
+	}
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package classifiers
+
+import (
+	"github.com/broosaction/gotext/tokenizers"
+	"github.com/broosaction/gotext/utils/sparse"
+	"hash/fnv"
+	"strings"
+)
+
+/**
+ * Text Features
+ *
+ * Turns a text into the sparse feature vector the linear classifiers learn
+ * from. The text is tokenized and lower cased, then every n-gram of MinN to
+ * MaxN words becomes a feature valued by its count. A feature is found either
+ * in a vocabulary that grows while learning, or by hashing it into 2^HashBits
+ * buckets, which needs no vocabulary at all at the price of a few collisions.
+ */
+type TextFeatures struct {
+	// name of the tokenizer, see tokenizers.GetTokenizer
+	Tokenizer string
+
+	// the shortest and longest n-grams, 1 and 1 for single words
+	MinN, MaxN int
+
+	// use the hashing trick with 2^HashBits buckets, 0 keeps a vocabulary
+	HashBits int
+
+	// scale every vector to unit length so long texts don't dominate
+	Normalize bool
+
+	// feature index of every known n-gram, unused when hashing
+	Vocabulary map[string]int
+}
+
+func NewTextFeatures() *TextFeatures {
+	tokenizer := tokenizers.DefaultTokenizer{}
+	return &TextFeatures{
+		Tokenizer:  tokenizer.GetName(),
+		MinN:       1,
+		MaxN:       1,
+		Normalize:  true,
+		Vocabulary: map[string]int{},
+	}
+}
+
+// Size is the number of features, the length of a weight vector.
+func (f *TextFeatures) Size() int {
+	if f.HashBits > 0 {
+		return 1 << uint(f.HashBits)
+	}
+	return len(f.Vocabulary)
+}
+
+// Terms returns the n-grams of the text, in order and with repeats.
+func (f *TextFeatures) Terms(text string) []string {
+	tokens := tokenizers.GetTokenizer(f.Tokenizer).Tokenize(strings.ToLower(text))
+	minN, maxN := f.MinN, f.MaxN
+	if minN < 1 {
+		minN = 1
+	}
+	if maxN < minN {
+		maxN = minN
+	}
+	var terms []string
+	for n := minN; n <= maxN; n++ {
+		for i := 0; i+n <= len(tokens); i++ {
+			terms = append(terms, strings.Join(tokens[i:i+n], " "))
+		}
+	}
+	return terms
+}
+
+// Vector featurizes the text. When learn is true unknown n-grams are added to
+// the vocabulary, otherwise they are dropped.
+func (f *TextFeatures) Vector(text string, learn bool) sparse.Vector {
+	return f.vector(f.Terms(text), learn)
+}
+
+func (f *TextFeatures) vector(terms []string, learn bool) sparse.Vector {
+	counts := make(map[int]float64, len(terms))
+	for _, term := range terms {
+		if i, ok := f.index(term, learn); ok {
+			counts[i]++
+		}
+	}
+	v := sparse.New(counts)
+	if f.Normalize {
+		v = v.Normalize()
+	}
+	return v
+}
+
+func (f *TextFeatures) index(term string, learn bool) (int, bool) {
+	if f.HashBits > 0 {
+		h := fnv.New32a()
+		h.Write([]byte(term))
+		return int(h.Sum32() & (1<<uint(f.HashBits) - 1)), true
+	}
+	if f.Vocabulary == nil {
+		f.Vocabulary = map[string]int{}
+	}
+	i, ok := f.Vocabulary[term]
+	if !ok && learn {
+		i = len(f.Vocabulary)
+		f.Vocabulary[term] = i
+		ok = true
+	}
+	return i, ok
+}
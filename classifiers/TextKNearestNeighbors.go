@@ -0,0 +1,335 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package classifiers
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"github.com/broosaction/gotext/nlp/nlptools"
+	"github.com/broosaction/gotext/utils/persist"
+	"log"
+	"math"
+	"sort"
+)
+
+type SimilarityMethodType uint8
+
+const (
+	// cosine of the angle between two term vectors
+	SMT_CosineMethod SimilarityMethodType = iota
+	// weighted Jaccard, sum of minimums over sum of maximums
+	SMT_JaccardMethod
+)
+
+type TermWeightingType uint8
+
+const (
+	// term frequency - inverse document frequency weights from nlptools.TFIDF
+	TWT_TFIDF TermWeightingType = iota
+	// plain number of times a term occurs in the document
+	TWT_RawCount
+)
+
+/**
+ * Text K Nearest Neighbors
+ *
+ * The text mode of K Nearest Neighbors. Every training document is turned into
+ * a sparse term vector (TF-IDF weights or raw term counts) and an unknown
+ * document is labelled by the K most similar training documents, where each
+ * neighbor votes with its similarity. This makes it an example-based
+ * classifier that works well for few-shot intents, next to NaiveBayes.
+ *
+ * @category    Machine Learning
+
+  **usage
+	knn := classifiers.NewTextKNearestNeighbors(3, classifiers.SMT_CosineMethod, classifiers.TWT_TFIDF)
+
+	knn.Learn("what is the weather like today", "weather")
+	knn.Learn("will it rain tomorrow", "weather")
+	knn.Learn("play some music", "music")
+	knn.Learn("put on my favourite song", "music")
+
+	fmt.Println(knn.Classify("is it going to rain"))
+*/
+type TextKNearestNeighbors struct {
+	/**
+	 * The number of neighbors to consider when making a prediction.
+	 *
+	 * @var int
+	 */
+	K int
+
+	// The similarity function to use when comparing two documents.
+	SimilarityMethod SimilarityMethodType
+
+	// How the terms of a document are weighted in its vector.
+	Weighting TermWeightingType
+
+	// The term statistics of the training documents.
+	Model *nlptools.TFIDF
+
+	// The training documents that make up the neighborhood of the problem space.
+	Samples []string
+
+	//The memoized labels of the training set.
+	Labels []string
+
+	// cached vectors of the samples, dropped whenever the training set changes
+	// because every new document shifts the inverse document frequencies.
+	vectors []map[string]float64
+}
+
+func NewTextKNearestNeighbors(k int, sm SimilarityMethodType, tw TermWeightingType) *TextKNearestNeighbors {
+	knn := &TextKNearestNeighbors{
+		K:                k,
+		SimilarityMethod: sm,
+		Weighting:        tw,
+		Model:            nlptools.NewTFIDF(),
+	}
+	return knn
+}
+
+func (k *TextKNearestNeighbors) getMeta() (string, string) {
+	return "TextKNearestNeighbors", "01"
+}
+
+// Learn stores a single labelled document.
+func (k *TextKNearestNeighbors) Learn(text, class string) {
+	k.Model.AddDocs(text)
+	k.Samples = append(k.Samples, text)
+	k.Labels = append(k.Labels, class)
+	k.vectors = nil
+}
+
+/**
+ * Store the documents and their labels. No other work to be done as this is
+ * a lazy learning algorithm.
+ */
+func (k *TextKNearestNeighbors) LearnBatch(texts []string, labels []string) error {
+	if len(texts) != len(labels) {
+		return errNotEqualDataLength
+	}
+	for i, text := range texts {
+		k.Learn(text, labels[i])
+	}
+	return nil
+}
+
+// Vectorize turns a document into its sparse term vector.
+func (k *TextKNearestNeighbors) Vectorize(text string) map[string]float64 {
+	if k.Weighting == TWT_RawCount {
+		vector := make(map[string]float64)
+		for term, count := range k.Model.TermFreq(text) {
+			vector[term] = float64(count)
+		}
+		return vector
+	}
+	return k.Model.Cal(text)
+}
+
+func (k *TextKNearestNeighbors) sampleVectors() []map[string]float64 {
+	if len(k.vectors) != len(k.Samples) {
+		k.vectors = make([]map[string]float64, len(k.Samples))
+		for i, sample := range k.Samples {
+			k.vectors[i] = k.Vectorize(sample)
+		}
+	}
+	return k.vectors
+}
+
+// Similarity returns how alike two term vectors are, 0 meaning nothing in common.
+func (k *TextKNearestNeighbors) Similarity(a, b map[string]float64) float64 {
+	switch k.SimilarityMethod {
+	case SMT_JaccardMethod:
+		return JaccardSimilarity(a, b)
+	default:
+		return CosineSimilarity(a, b)
+	}
+}
+
+// neighbors returns the K training documents most similar to the text, most similar first.
+func (k *TextKNearestNeighbors) neighbors(text string) []textNeighbor {
+	query := k.Vectorize(text)
+
+	neighbors := make([]textNeighbor, 0, len(k.Samples))
+	for i, vector := range k.sampleVectors() {
+		sim := k.Similarity(query, vector)
+		if sim > 0 {
+			neighbors = append(neighbors, textNeighbor{idx: i, sim: sim})
+		}
+	}
+	sort.SliceStable(neighbors, func(i, j int) bool {
+		return neighbors[i].sim > neighbors[j].sim
+	})
+	if len(neighbors) > k.K {
+		neighbors = neighbors[:k.K]
+	}
+	return neighbors
+}
+
+// votes returns the share of the similarity of the neighbors going to every label.
+func (k *TextKNearestNeighbors) votes(neighbors []textNeighbor) map[string]float64 {
+	votes := make(map[string]float64)
+	total := 0.0
+	for _, n := range neighbors {
+		votes[k.Labels[n.idx]] += n.sim
+		total += n.sim
+	}
+	for label := range votes {
+		votes[label] /= total
+	}
+	return votes
+}
+
+/**
+ * Probabilities returns the share of the votes of the K most similar
+ * training documents going to every label, each voting with its
+ * similarity. The map is empty when no training document is similar.
+ */
+func (k *TextKNearestNeighbors) Probabilities(text string) map[string]float64 {
+	return k.votes(k.neighbors(text))
+}
+
+/**
+ * Determine what class `text` belongs to. Each of the K most similar training
+ * documents votes for its label with its similarity, the returned score is the
+ * share of the votes that went to the chosen label.
+ */
+func (k *TextKNearestNeighbors) Classify(text string) (string, float64) {
+	neighbors := k.neighbors(text)
+	votes := k.votes(neighbors)
+
+	// on ties, the label of the most similar neighbor wins
+	chosen, best := "", 0.0
+	for _, n := range neighbors {
+		label := k.Labels[n.idx]
+		if votes[label] > best {
+			chosen, best = label, votes[label]
+		}
+	}
+	return chosen, best
+}
+
+type textNeighbor struct {
+	idx int
+	sim float64
+}
+
+// CosineSimilarity of two sparse term vectors.
+func CosineSimilarity(a, b map[string]float64) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	var dot, normA, normB float64
+	for term, x := range a {
+		dot += x * b[term]
+		normA += x * x
+	}
+	for _, y := range b {
+		normB += y * y
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// JaccardSimilarity is the weighted Jaccard index of two sparse term vectors,
+// which is the plain set Jaccard index when all weights are 1.
+func JaccardSimilarity(a, b map[string]float64) float64 {
+	var min, max float64
+	for term, x := range a {
+		y := b[term]
+		min += math.Min(x, y)
+		max += math.Max(x, y)
+	}
+	for term, y := range b {
+		if _, ok := a[term]; !ok {
+			max += y
+		}
+	}
+	if max == 0 {
+		return 0
+	}
+	return min / max
+}
+
+// Fit is LearnBatch.
+func (k *TextKNearestNeighbors) Fit(texts []string, labels []string) error {
+	return k.LearnBatch(texts, labels)
+}
+
+// Predict is Classify, a text without any similar training document isn't classified.
+func (k *TextKNearestNeighbors) Predict(text string) (string, error) {
+	class, _ := k.Classify(text)
+	if class == "" {
+		return "", ErrNotClassified
+	}
+	return class, nil
+}
+
+// PredictProba is Probabilities.
+func (k *TextKNearestNeighbors) PredictProba(text string) (map[string]float64, error) {
+	return k.Probabilities(text), nil
+}
+
+//save to a file
+func (k *TextKNearestNeighbors) Save(file string) error {
+
+	buf := new(bytes.Buffer)
+	encoder := gob.NewEncoder(buf)
+
+	err := encoder.Encode(k)
+	if err != nil {
+		return fmt.Errorf("error encoding model: %s", err)
+	}
+
+	name, version := k.getMeta()
+	persist.Save(file, persist.Modeldata{
+		Data:    buf.Bytes(),
+		Name:    name,
+		Version: version,
+	})
+	return nil
+}
+
+// Load from the output file.
+func (k *TextKNearestNeighbors) Load(filePath string) error {
+	log.Printf("Loading Classifier from %s...", filePath)
+	meta := persist.Load(filePath)
+	//get the classifier current meta data
+	name, version := k.getMeta()
+	if meta.Name != name {
+		return fmt.Errorf("This file doesn't contain a TextKNearestNeighbors classifier")
+	}
+	if meta.Version != version {
+		return fmt.Errorf("Can't understand this file format")
+	}
+
+	// decode into a fresh one, gob leaves the fields saved as zero values untouched
+	var loaded TextKNearestNeighbors
+	decoder := gob.NewDecoder(bytes.NewBuffer(meta.Data))
+	err := decoder.Decode(&loaded)
+	if err != nil {
+		return fmt.Errorf("error decoding checkpoint file: %s", err)
+	}
+	if loaded.Model == nil {
+		loaded.Model = nlptools.NewTFIDF()
+	}
+	if loaded.Model.DocIndex == nil {
+		loaded.Model.DocIndex = make(map[string]int)
+	}
+	if loaded.Model.TermDocs == nil {
+		loaded.Model.TermDocs = make(map[string]int)
+	}
+	*k = loaded
+
+	checkpointFile = filePath
+	return nil
+}
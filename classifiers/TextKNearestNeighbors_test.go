@@ -0,0 +1,117 @@
+package classifiers
+
+import (
+	"errors"
+	"github.com/broosaction/gotext/utils/persist"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+var textKNNTexts = []string{
+	"what is the weather like today",
+	"will it rain tomorrow",
+	"is it going to be sunny this weekend",
+	"play some music",
+	"put on my favourite song",
+	"play the new album",
+}
+
+var textKNNLabels = []string{"weather", "weather", "weather", "music", "music", "music"}
+
+func TestTextKNearestNeighborsClassify(t *testing.T) {
+	for _, sm := range []SimilarityMethodType{SMT_CosineMethod, SMT_JaccardMethod} {
+		for _, tw := range []TermWeightingType{TWT_TFIDF, TWT_RawCount} {
+			knn := NewTextKNearestNeighbors(3, sm, tw)
+			if err := knn.Fit(textKNNTexts, textKNNLabels); err != nil {
+				t.Fatal(err)
+			}
+			if class, score := knn.Classify("will it rain this weekend"); class != "weather" || score <= 0.5 || score > 1 {
+				t.Errorf("similarity %d, weighting %d: got %q, %f", sm, tw, class, score)
+			}
+			if class, err := knn.Predict("play a song"); err != nil || class != "music" {
+				t.Errorf("similarity %d, weighting %d: got %q, %v", sm, tw, class, err)
+			}
+		}
+	}
+
+	knn := NewTextKNearestNeighbors(3, SMT_CosineMethod, TWT_TFIDF)
+	if err := knn.LearnBatch(textKNNTexts, textKNNLabels[:2]); !errors.Is(err, errNotEqualDataLength) {
+		t.Errorf("texts and labels of different lengths gave %v", err)
+	}
+	knn.Fit(textKNNTexts, textKNNLabels)
+	if _, err := knn.Predict("xylophone quartz"); !errors.Is(err, ErrNotClassified) {
+		t.Errorf("a text without similar documents gave %v", err)
+	}
+}
+
+func TestTextKNearestNeighborsProbabilities(t *testing.T) {
+	knn := NewTextKNearestNeighbors(4, SMT_CosineMethod, TWT_TFIDF)
+	knn.Fit(textKNNTexts, textKNNLabels)
+	probs, err := knn.PredictProba("play the weather song")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total float64
+	for _, p := range probs {
+		total += p
+	}
+	if math.Abs(total-1) > 1e-9 || probs["music"] <= probs["weather"] || probs["weather"] == 0 {
+		t.Errorf("got %v", probs)
+	}
+	class, score := knn.Classify("play the weather song")
+	if class != "music" || math.Abs(score-probs["music"]) > 1e-9 {
+		t.Errorf("classified %q with %f, probabilities %v", class, score, probs)
+	}
+	if probs := knn.Probabilities("xylophone quartz"); len(probs) != 0 {
+		t.Errorf("a text without similar documents got %v", probs)
+	}
+}
+
+func TestSimilarities(t *testing.T) {
+	a := map[string]float64{"rain": 1, "today": 1}
+	b := map[string]float64{"rain": 1, "tomorrow": 1}
+	if s := CosineSimilarity(a, b); math.Abs(s-0.5) > 1e-9 {
+		t.Errorf("cosine %f", s)
+	}
+	if s := JaccardSimilarity(a, b); math.Abs(s-1.0/3) > 1e-9 {
+		t.Errorf("jaccard %f", s)
+	}
+	if s := JaccardSimilarity(map[string]float64{"rain": 2}, map[string]float64{"rain": 1}); s != 0.5 {
+		t.Errorf("weighted jaccard %f", s)
+	}
+	if CosineSimilarity(a, nil) != 0 || JaccardSimilarity(nil, nil) != 0 {
+		t.Errorf("empty vectors are not similar")
+	}
+}
+
+func TestTextKNearestNeighborsSaveLoad(t *testing.T) {
+	knn := NewTextKNearestNeighbors(3, SMT_JaccardMethod, TWT_RawCount)
+	knn.Fit(textKNNTexts, textKNNLabels)
+	file := filepath.Join(t.TempDir(), "textknn.model")
+	if err := knn.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	// the format version has a space, meta.conf holds one value per line
+	meta := persist.Load(file)
+	if meta.FormatVersion != persist.SerializationFormatVersion || meta.Name != "TextKNearestNeighbors" || meta.Version != "01" {
+		t.Errorf("meta %q %q %q", meta.FormatVersion, meta.Name, meta.Version)
+	}
+
+	// loading replaces what a trained model had
+	loaded := NewTextKNearestNeighbors(1, SMT_CosineMethod, TWT_TFIDF)
+	loaded.Fit([]string{"unrelated text"}, []string{"other"})
+	if err := loaded.Load(file); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.K != 3 || loaded.SimilarityMethod != SMT_JaccardMethod || loaded.Weighting != TWT_RawCount || len(loaded.Samples) != len(textKNNTexts) {
+		t.Errorf("loaded K %d, similarity %d, weighting %d, %d samples", loaded.K, loaded.SimilarityMethod, loaded.Weighting, len(loaded.Samples))
+	}
+	want, _ := knn.Classify("will it rain tomorrow")
+	if got, _ := loaded.Classify("will it rain tomorrow"); got != want {
+		t.Errorf("loaded model classified %q, want %q", got, want)
+	}
+	if err := new(LogisticRegression).Load(file); err == nil {
+		t.Errorf("a TextKNearestNeighbors file loaded as LogisticRegression")
+	}
+}
@@ -0,0 +1,257 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+// Package active picks the unlabeled texts worth labeling next. A model that
+// tells how likely every label is ranks a pool of texts by how unsure it is
+// about them, optionally spreading the picks over clusters of similar texts,
+// and the labels given back are learned by the model, closing the loop.
+package active
+
+import (
+	"errors"
+	"fmt"
+	"github.com/broosaction/gotext/classifiers"
+	"math"
+	"sort"
+)
+
+type SamplingStrategy uint8
+
+const (
+	// one minus the probability of the most likely label
+	SS_LeastConfidence SamplingStrategy = iota
+	// one minus the gap between the two most likely labels
+	SS_Margin
+	// the entropy of the label distribution, divided by its maximum
+	SS_Entropy
+)
+
+var (
+	errNoModel       = errors.New("the learner has no model")
+	errNotInPool     = errors.New("the text is not in the pool")
+	errAlreadyTaught = errors.New("the text was already labeled")
+)
+
+// Query is a pool text proposed for labeling.
+type Query struct {
+	// Position of the text in the pool.
+	Index int
+	Text  string
+
+	// How unsure the model is about the text, from 0 (sure) to 1.
+	Score float64
+
+	// The label the model would give and the probability of every label.
+	Prediction    string
+	Probabilities map[string]float64
+}
+
+/**
+ * Uncertainty scores a label distribution, the higher the more the model
+ * would learn from the true label. All strategies give 1 to an empty
+ * distribution, a text the model can't say anything about.
+ */
+func Uncertainty(probabilities map[string]float64, strategy SamplingStrategy) float64 {
+	if len(probabilities) == 0 {
+		return 1
+	}
+	var first, second float64
+	for _, p := range probabilities {
+		if p > first {
+			first, second = p, first
+		} else if p > second {
+			second = p
+		}
+	}
+	switch strategy {
+	case SS_Margin:
+		return 1 - (first - second)
+	case SS_Entropy:
+		if len(probabilities) < 2 {
+			return 0
+		}
+		var entropy float64
+		for _, p := range probabilities {
+			if p > 0 {
+				entropy -= p * math.Log(p)
+			}
+		}
+		return entropy / math.Log(float64(len(probabilities)))
+	default:
+		return 1 - first
+	}
+}
+
+// Rank scores every text of the pool, the most uncertain first.
+func Rank(model classifiers.ProbabilisticTextClassifier, pool []string, strategy SamplingStrategy) ([]Query, error) {
+	queries := make([]Query, len(pool))
+	for i, text := range pool {
+		q, err := query(model, i, text, strategy)
+		if err != nil {
+			return nil, err
+		}
+		queries[i] = q
+	}
+	sortQueries(queries)
+	return queries, nil
+}
+
+func query(model classifiers.ProbabilisticTextClassifier, i int, text string, strategy SamplingStrategy) (Query, error) {
+	probabilities, err := model.PredictProba(text)
+	if err != nil {
+		return Query{}, fmt.Errorf("text %d: %w", i, err)
+	}
+	q := Query{Index: i, Text: text, Probabilities: probabilities, Score: Uncertainty(probabilities, strategy)}
+	for label, p := range probabilities {
+		if q.Prediction == "" || p > probabilities[q.Prediction] || (p == probabilities[q.Prediction] && label < q.Prediction) {
+			q.Prediction = label
+		}
+	}
+	return q, nil
+}
+
+// sortQueries puts the most uncertain first, the pool order breaking ties.
+func sortQueries(queries []Query) {
+	sort.SliceStable(queries, func(i, j int) bool {
+		if queries[i].Score != queries[j].Score {
+			return queries[i].Score > queries[j].Score
+		}
+		return queries[i].Index < queries[j].Index
+	})
+}
+
+/**
+ * Active Learner
+ *
+ * Keeps a model and the pool of texts still to label. Query proposes the
+ * texts the model is least sure about, Teach learns the label a person gave
+ * one of them and takes it out of the pool. The model should already know
+ * every label, a few examples each, or its uncertainty means little.
+ *
+ * With Diverse set, the most uncertain CandidateFactor*n texts are grouped
+ * into n clusters of TF-IDF vectors and the most uncertain text of every
+ * cluster is proposed, so a batch doesn't spend the budget on near copies.
+ *
+ * @category    Machine Learning
+
+  **usage
+	nb := classifiers.NewNaiveBayes()
+	nb.Fit(seedTexts, seedLabels)
+	learner := active.NewLearner(nb, unlabeled)
+	learner.Strategy = active.SS_Entropy
+	for round := 0; round < 10; round++ {
+		queries, _ := learner.Query(20)
+		for _, q := range queries {
+			learner.Teach(q.Index, askLabel(q.Text))
+		}
+	}
+*/
+type Learner struct {
+	Model    classifiers.ProbabilisticTextClassifier
+	Strategy SamplingStrategy
+
+	// Spread every batch over clusters of similar texts.
+	Diverse bool
+
+	// How many candidates per wanted text are clustered, 5 when not set.
+	CandidateFactor int
+
+	// Seed of the clustering.
+	Seed int64
+
+	// The texts to label, Index refers to this slice.
+	Pool []string
+
+	// The labels taught so far, by pool index.
+	Labels map[int]string
+}
+
+func NewLearner(model classifiers.ProbabilisticTextClassifier, pool []string) *Learner {
+	return &Learner{
+		Model:           model,
+		Strategy:        SS_LeastConfidence,
+		CandidateFactor: 5,
+		Pool:            pool,
+		Labels:          make(map[int]string),
+	}
+}
+
+// Remaining returns the number of pool texts not labeled yet.
+func (l *Learner) Remaining() int {
+	return len(l.Pool) - len(l.Labels)
+}
+
+// Query proposes up to n unlabeled texts to label, the most informative first.
+func (l *Learner) Query(n int) ([]Query, error) {
+	if l.Model == nil {
+		return nil, errNoModel
+	}
+	var queries []Query
+	for i, text := range l.Pool {
+		if _, ok := l.Labels[i]; ok {
+			continue
+		}
+		q, err := query(l.Model, i, text, l.Strategy)
+		if err != nil {
+			return nil, err
+		}
+		queries = append(queries, q)
+	}
+	sortQueries(queries)
+	if n <= 0 || n >= len(queries) {
+		return queries, nil
+	}
+	if !l.Diverse || n == 1 {
+		return queries[:n], nil
+	}
+
+	factor := l.CandidateFactor
+	if factor < 1 {
+		factor = 5
+	}
+	candidates := queries
+	if n*factor < len(candidates) {
+		candidates = candidates[:n*factor]
+	}
+	return diverse(candidates, n, l.Seed), nil
+}
+
+// Teach learns the label of the pool text at index and takes it out of the pool.
+func (l *Learner) Teach(index int, label string) error {
+	if l.Model == nil {
+		return errNoModel
+	}
+	if index < 0 || index >= len(l.Pool) {
+		return fmt.Errorf("index %d of %d texts: %w", index, len(l.Pool), errNotInPool)
+	}
+	if _, ok := l.Labels[index]; ok {
+		return fmt.Errorf("text %d: %w", index, errAlreadyTaught)
+	}
+	if l.Labels == nil {
+		l.Labels = make(map[int]string)
+	}
+	if err := l.Model.Fit([]string{l.Pool[index]}, []string{label}); err != nil {
+		return err
+	}
+	l.Labels[index] = label
+	return nil
+}
+
+// Labeled returns the texts taught so far and their labels, in pool order.
+func (l *Learner) Labeled() ([]string, []string) {
+	indexes := make([]int, 0, len(l.Labels))
+	for i := range l.Labels {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+	texts := make([]string, len(indexes))
+	labels := make([]string, len(indexes))
+	for k, i := range indexes {
+		texts[k], labels[k] = l.Pool[i], l.Labels[i]
+	}
+	return texts, labels
+}
@@ -0,0 +1,108 @@
+package active
+
+import (
+	"github.com/broosaction/gotext/classifiers"
+	"math"
+	"testing"
+)
+
+func TestUncertainty(t *testing.T) {
+	sure := map[string]float64{"a": 0.9, "b": 0.1}
+	torn := map[string]float64{"a": 0.5, "b": 0.5}
+	for _, s := range []SamplingStrategy{SS_LeastConfidence, SS_Margin, SS_Entropy} {
+		if Uncertainty(sure, s) >= Uncertainty(torn, s) {
+			t.Errorf("strategy %d ranks a sure prediction above a torn one", s)
+		}
+		if Uncertainty(map[string]float64{}, s) != 1 {
+			t.Errorf("strategy %d: an empty distribution should be the most uncertain", s)
+		}
+	}
+	if math.Abs(Uncertainty(sure, SS_LeastConfidence)-0.1) > 1e-9 || math.Abs(Uncertainty(sure, SS_Margin)-0.2) > 1e-9 {
+		t.Error("wrong least confidence or margin")
+	}
+	if math.Abs(Uncertainty(torn, SS_Entropy)-1) > 1e-9 {
+		t.Errorf("entropy of an even split is %f", Uncertainty(torn, SS_Entropy))
+	}
+}
+
+var pool = []string{
+	"will it rain tomorrow",
+	"play some jazz music",
+	"play the weather song",
+	"is the music forecast sunny",
+	"play the rain song",
+	"turn the music up",
+}
+
+func seeded() *classifiers.NaiveBayes {
+	nb := classifiers.NewNaiveBayes()
+	nb.Fit([]string{"what is the weather forecast", "will it be sunny", "play my song", "put on some music"},
+		[]string{"weather", "weather", "music", "music"})
+	return nb
+}
+
+func TestLearnerLoop(t *testing.T) {
+	learner := NewLearner(seeded(), pool)
+	learner.Strategy = SS_Margin
+	queries, err := learner.Query(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 2 || queries[0].Score < queries[1].Score {
+		t.Fatalf("queries %+v", queries)
+	}
+	ranked, _ := Rank(learner.Model, pool, SS_Margin)
+	if ranked[0].Index != queries[0].Index {
+		t.Errorf("the learner and Rank disagree: %d and %d", queries[0].Index, ranked[0].Index)
+	}
+
+	for _, q := range queries {
+		if err := learner.Teach(q.Index, "music"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := learner.Teach(queries[0].Index, "music"); err == nil {
+		t.Error("a text was taught twice")
+	}
+	if err := learner.Teach(len(pool), "music"); err == nil {
+		t.Error("taught a text out of the pool")
+	}
+	if learner.Remaining() != len(pool)-2 {
+		t.Errorf("%d texts remain", learner.Remaining())
+	}
+	next, _ := learner.Query(0)
+	for _, q := range next {
+		if _, ok := learner.Labels[q.Index]; ok {
+			t.Errorf("text %d was proposed again", q.Index)
+		}
+	}
+	texts, labels := learner.Labeled()
+	if len(texts) != 2 || labels[0] != "music" {
+		t.Errorf("labeled %v %v", texts, labels)
+	}
+}
+
+func TestDiverseQuery(t *testing.T) {
+	texts := []string{
+		"play the rain song", "play the rain song now", "play the rain song please",
+		"is the sunny music forecast right", "weather music tonight",
+	}
+	learner := NewLearner(seeded(), texts)
+	learner.Diverse = true
+	queries, err := learner.Query(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(queries) != 2 {
+		t.Fatalf("got %d queries", len(queries))
+	}
+	rain := 0
+	for _, q := range queries {
+		if q.Index < 3 {
+			rain++
+		}
+	}
+	if rain > 1 {
+		t.Errorf("two near copies were proposed: %+v", queries)
+	}
+}
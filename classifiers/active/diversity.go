@@ -0,0 +1,86 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package active
+
+import (
+	"github.com/broosaction/gotext/nlp/cluster"
+	"github.com/broosaction/gotext/nlp/nlptools"
+	"github.com/broosaction/gotext/utils/sparse"
+	"sort"
+)
+
+// the rounds of k-means, the candidates are few so it settles well before
+const kmeansRounds = 20
+
+/**
+ * diverse groups the candidates, sorted most uncertain first, into n
+ * clusters of their TF-IDF vectors with spherical k-means and returns the
+ * most uncertain candidate of every cluster, most uncertain first.
+ */
+func diverse(candidates []Query, n int, seed int64) []Query {
+	km := cluster.NewKMeans(n)
+	km.MaxIterations = kmeansRounds
+	km.Seed = seed
+	// n is below the number of candidates, so k-means can't fail
+	res, _ := km.Fit(tfidfVectors(candidates))
+	assigned := res.Assignments
+
+	picked := make([]Query, 0, n)
+	taken := make(map[int]bool)
+	for i, c := range assigned {
+		// the candidates are sorted, so the first of a cluster is its most uncertain
+		if !taken[c] {
+			taken[c] = true
+			picked = append(picked, candidates[i])
+		}
+	}
+	// empty clusters leave the batch short, fill it with the next most uncertain
+	chosen := make(map[int]bool, len(picked))
+	for _, q := range picked {
+		chosen[q.Index] = true
+	}
+	for _, q := range candidates {
+		if len(picked) == n {
+			break
+		}
+		if !chosen[q.Index] {
+			picked = append(picked, q)
+		}
+	}
+	sortQueries(picked)
+	return picked
+}
+
+// tfidfVectors returns the unit TF-IDF vector of every candidate, the candidates being the documents.
+func tfidfVectors(candidates []Query) []sparse.Vector {
+	model := nlptools.NewTFIDF()
+	for _, q := range candidates {
+		model.AddDocs(q.Text)
+	}
+	terms := make(map[string]int)
+	vectors := make([]sparse.Vector, len(candidates))
+	for i, q := range candidates {
+		weights := model.Cal(q.Text)
+		words := make([]string, 0, len(weights))
+		for w := range weights {
+			words = append(words, w)
+		}
+		sort.Strings(words)
+		values := make(map[int]float64, len(words))
+		for _, w := range words {
+			at, ok := terms[w]
+			if !ok {
+				at = len(terms)
+				terms[w] = at
+			}
+			values[at] = weights[w]
+		}
+		vectors[i] = sparse.New(values).Normalize()
+	}
+	return vectors
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package classifiers
+
+/**
+ * The classifiers keep their own Learn and Classify methods, whose shapes
+ * differ with the way each model learns. On top of those they implement the
+ * interfaces below, so that models can be swapped and evaluated generically,
+ * see the evaluation package.
+ *
+ * What a second Fit keeps depends on the kind of model. The text
+ * classifiers add to what they already learned: NaiveBayes, IntentClassifier,
+ * HierarchicalClassifier, TextKNearestNeighbors and AveragedPerceptron
+ * learn the new texts on top of the old ones, LogisticRegression and
+ * LinearSVM keep every text learned and retrain on all of them. The vector
+ * classifiers, KNearestNeighbors, DecisionTree and RandomForest, replace
+ * what they learned by the new samples. Callers that need a fresh model,
+ * for every fold of a cross-validation for instance, create a new one.
+ */
+
+// TextClassifier assigns one label to a text.
+type TextClassifier interface {
+	// Fit learns the texts and their labels, on top of those learned before.
+	Fit(texts []string, labels []string) error
+
+	// Predict returns the label of a text, or ErrNotClassified when the model can't tell.
+	Predict(text string) (string, error)
+}
+
+// ProbabilisticTextClassifier also tells how likely every label is.
+type ProbabilisticTextClassifier interface {
+	TextClassifier
+
+	// PredictProba returns the probability of every label for a text.
+	PredictProba(text string) (map[string]float64, error)
+}
+
+// VectorClassifier assigns one label to each numeric sample.
+type VectorClassifier interface {
+	// Fit learns the samples and their labels, replacing those learned before.
+	Fit(samples [][]float64, labels []string) error
+
+	// Predict returns the label of every sample.
+	Predict(samples [][]float64) ([]string, error)
+}
+
+// ProbabilisticVectorClassifier also tells how likely every label is.
+type ProbabilisticVectorClassifier interface {
+	VectorClassifier
+
+	// PredictProba returns the probability of every label for each sample.
+	PredictProba(samples [][]float64) ([]map[string]float64, error)
+}
+
+// Persistable models are saved to and loaded from model files, see utils/persist.
+type Persistable interface {
+	Save(file string) error
+	Load(filePath string) error
+}
+
+var (
+	_ ProbabilisticTextClassifier = (*NaiveBayes)(nil)
+	_ ProbabilisticTextClassifier = (*IntentClassifier)(nil)
+	_ ProbabilisticTextClassifier = (*LogisticRegression)(nil)
+	_ TextClassifier              = (*LinearSVM)(nil)
+	_ TextClassifier              = (*AveragedPerceptron)(nil)
+	_ ProbabilisticTextClassifier = (*TextKNearestNeighbors)(nil)
+	_ TextClassifier              = (*HierarchicalClassifier)(nil)
+
+	_ ProbabilisticVectorClassifier = (*KNearestNeighbors)(nil)
+	_ ProbabilisticVectorClassifier = (*DecisionTree)(nil)
+	_ ProbabilisticVectorClassifier = (*RandomForest)(nil)
+
+	_ Persistable = (*NaiveBayes)(nil)
+	_ Persistable = (*IntentClassifier)(nil)
+	_ Persistable = (*LogisticRegression)(nil)
+	_ Persistable = (*LinearSVM)(nil)
+	_ Persistable = (*AveragedPerceptron)(nil)
+	_ Persistable = (*TextKNearestNeighbors)(nil)
+	_ Persistable = (*HierarchicalClassifier)(nil)
+	_ Persistable = (*MultiLabelClassifier)(nil)
+	_ Persistable = (*KNearestNeighbors)(nil)
+	_ Persistable = (*KNearestNeighborsRegressor)(nil)
+	_ Persistable = (*DecisionTree)(nil)
+	_ Persistable = (*RandomForest)(nil)
+)
+
+// fitText learns the texts one by one, for models that learn online.
+func fitText(learn func(text, label string), texts []string, labels []string) error {
+	if len(texts) != len(labels) {
+		return errNotEqualDataLength
+	}
+	for i, text := range texts {
+		learn(text, labels[i])
+	}
+	return nil
+}
+
+// bestLabel returns the most probable label, the smallest one on ties so the
+// result doesn't depend on map order.
+func bestLabel(probabilities map[string]float64) (string, error) {
+	chosen, top := "", -1.0
+	for label, p := range probabilities {
+		if p > top || (p == top && label < chosen) {
+			chosen, top = label, p
+		}
+	}
+	if chosen == "" {
+		return "", ErrNotClassified
+	}
+	return chosen, nil
+}
@@ -0,0 +1,242 @@
+package evaluation
+
+import (
+	"errors"
+	"github.com/broosaction/gotext/classifiers"
+	"github.com/broosaction/gotext/tokenizers"
+	"math"
+	"sort"
+	"testing"
+)
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestEvaluate(t *testing.T) {
+	truth := []string{"cat", "cat", "cat", "dog", "dog", "bird"}
+	predicted := []string{"cat", "cat", "dog", "dog", "cat", "bird"}
+	r, err := Evaluate(truth, predicted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cat := r.Classes["cat"]
+	if !near(cat.Precision, 2.0/3) || !near(cat.Recall, 2.0/3) || cat.Support != 3 {
+		t.Errorf("cat %+v", cat)
+	}
+	dog := r.Classes["dog"]
+	if !near(dog.Precision, 0.5) || !near(dog.Recall, 0.5) || !near(dog.F1, 0.5) {
+		t.Errorf("dog %+v", dog)
+	}
+	if !near(r.Accuracy, 4.0/6) || !near(r.MicroF1, r.Accuracy) {
+		t.Errorf("accuracy %f, micro F1 %f", r.Accuracy, r.MicroF1)
+	}
+	if !near(r.MacroF1, (2.0/3+0.5+1)/3) || !near(r.WeightedF1, (3*2.0/3+2*0.5+1)/6) {
+		t.Errorf("macro F1 %f, weighted F1 %f", r.MacroF1, r.WeightedF1)
+	}
+	if r.Confusion.Count("cat", "dog") != 1 || r.Confusion.Count("dog", "cat") != 1 || r.Confusion.Count("bird", "bird") != 1 {
+		t.Errorf("confusion\n%s", r.Confusion)
+	}
+	if _, err := Evaluate(truth, predicted[:2]); err == nil {
+		t.Error("expected a length error")
+	}
+}
+
+func TestStratifiedSplits(t *testing.T) {
+	var labels []string
+	for i := 0; i < 30; i++ {
+		labels = append(labels, "a")
+	}
+	for i := 0; i < 10; i++ {
+		labels = append(labels, "b")
+	}
+
+	train, test := StratifiedSplit(labels, 0.2, 1)
+	count := func(indexes []int, label string) int {
+		n := 0
+		for _, i := range indexes {
+			if labels[i] == label {
+				n++
+			}
+		}
+		return n
+	}
+	if count(test, "a") != 6 || count(test, "b") != 2 || len(train) != 32 {
+		t.Errorf("test has %d a and %d b, train %d", count(test, "a"), count(test, "b"), len(train))
+	}
+
+	folds := StratifiedKFold(labels, 5, 1)
+	var all []int
+	for _, fold := range folds {
+		if count(fold, "a") != 6 || count(fold, "b") != 2 {
+			t.Errorf("fold has %d a and %d b", count(fold, "a"), count(fold, "b"))
+		}
+		all = append(all, fold...)
+	}
+	sort.Ints(all)
+	for i, sample := range all {
+		if sample != i {
+			t.Fatal("the folds don't partition the samples")
+		}
+	}
+	if len(KFold(10, 3, 1)[0]) != 4 {
+		t.Error("uneven folds")
+	}
+}
+
+var texts = []string{
+	"what is the weather like today", "will it rain tomorrow", "is it sunny outside",
+	"how cold is it this morning", "weather forecast for the weekend", "will it snow tonight",
+	"play some music", "put on my favourite song", "play the new album",
+	"turn the music up", "skip this song", "play some jazz music",
+}
+
+var labels = []string{
+	"weather", "weather", "weather", "weather", "weather", "weather",
+	"music", "music", "music", "music", "music", "music",
+}
+
+func TestCrossValidateText(t *testing.T) {
+	models := map[string]func() classifiers.TextClassifier{
+		"naive bayes":         func() classifiers.TextClassifier { return classifiers.NewNaiveBayes() },
+		"logistic regression": func() classifiers.TextClassifier { return classifiers.NewLogisticRegression() },
+		"perceptron":          func() classifiers.TextClassifier { return classifiers.NewAveragedPerceptron() },
+	}
+	for name, newModel := range models {
+		cv, err := CrossValidateText(newModel, texts, labels, 3, 1)
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		if len(cv.Folds) != 3 || cv.Overall.Accuracy < 0.7 {
+			t.Errorf("%s: %d folds, accuracy %f\n%s", name, len(cv.Folds), cv.Overall.Accuracy, cv.Overall)
+		}
+	}
+	if _, err := CrossValidateText(models["perceptron"], texts, labels, 1, 1); err != errFolds {
+		t.Errorf("got %v", err)
+	}
+
+	r, err := TrainTestText(models["logistic regression"], texts, labels, 0.34, 1)
+	if err != nil || r.Confusion == nil || r.Classes["music"].Support != 2 {
+		t.Errorf("got %+v, %v", r, err)
+	}
+}
+
+func TestCrossValidateVector(t *testing.T) {
+	samples := [][]float64{
+		{5.3, 3.7}, {5.1, 3.8}, {7.2, 3}, {5.4, 3.4}, {5.1, 3.3},
+		{5.4, 3.9}, {7.4, 2.8}, {6.1, 2.8}, {7.3, 2.9}, {6, 2.7},
+		{5.8, 2.8}, {6.3, 2.3}, {5.1, 2.5}, {6.3, 2.5}, {5.5, 2.4},
+	}
+	irisLabels := []string{
+		"Setosa", "Setosa", "Virginica", "Setosa", "Setosa",
+		"Setosa", "Virginica", "Versicolor", "Virginica", "Versicolor",
+		"Virginica", "Versicolor", "Versicolor", "Versicolor", "Versicolor",
+	}
+	newModel := func() classifiers.VectorClassifier {
+		return classifiers.NewKNearestNeighbors(3, classifiers.DMT_EulerMethod, nil)
+	}
+	cv, err := CrossValidateVector(newModel, samples, irisLabels, 3, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cv.Overall.Accuracy < 0.6 || cv.StdAccuracy < 0 {
+		t.Errorf("accuracy %f\n%s", cv.Overall.Accuracy, cv.Overall.Confusion)
+	}
+	if _, err := TrainTestVector(newModel, samples, irisLabels, 0.3, 1); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestParamSpace(t *testing.T) {
+	space := ParamSpace{"k": {1, 3, 5}, "method": {"a", "b"}}
+	grid := space.Grid()
+	if len(grid) != 6 || grid[0].Int("k", 0) != 1 || grid[1].Str("method", "") != "b" || grid[2].Int("k", 0) != 3 {
+		t.Errorf("grid %v", grid)
+	}
+	sample := space.Sample(4, 1)
+	seen := make(map[string]bool)
+	for _, p := range sample {
+		seen[p.String()] = true
+	}
+	if len(sample) != 4 || len(seen) != 4 {
+		t.Errorf("sample %v", sample)
+	}
+	if len(space.Sample(10, 1)) != 6 {
+		t.Error("a sample larger than the grid should be the grid")
+	}
+	if grid[0].Float("k", 2) != 2 || !grid[0].Bool("missing", true) {
+		t.Error("the defaults weren't used")
+	}
+}
+
+func TestGridSearchText(t *testing.T) {
+	space := ParamSpace{
+		"tokenizer": {tokenizers.DefaultTokenizerName, tokenizers.NGramTokenizerName},
+		"alpha":     {0.1, 1.0},
+	}
+	results, err := GridSearchText(func(p Params) (classifiers.TextClassifier, error) {
+		nb := classifiers.NewNaiveBayes()
+		nb.SetTokenizer(p.Str("tokenizer", tokenizers.DefaultTokenizerName))
+		nb.SetSmoothing(p.Float("alpha", 1))
+		return nb, nil
+	}, space, texts, labels, SearchOptions{Folds: 3, Seed: 1, Metric: SM_MacroF1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results.Results) != 4 || results.Best.Score < 0.7 {
+		t.Errorf("results\n%s", results)
+	}
+	// the n-gram configurations learn something too
+	for _, res := range results.Results {
+		if res.Params.Str("tokenizer", "") == tokenizers.NGramTokenizerName && (res.Err != nil || res.Score < 0.7) {
+			t.Errorf("n-grams scored %f, %v", res.Score, res.Err)
+		}
+	}
+	for i := 1; i < len(results.Results); i++ {
+		if results.Results[i].Score > results.Results[i-1].Score {
+			t.Fatalf("not ranked\n%s", results)
+		}
+	}
+
+	failing := errors.New("no model")
+	_, err = RandomSearchText(func(p Params) (classifiers.TextClassifier, error) {
+		return nil, failing
+	}, space, 2, texts, labels, SearchOptions{Folds: 3})
+	if !errors.Is(err, failing) {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestGridSearchTextNGrams(t *testing.T) {
+	space := ParamSpace{"ngrams": {1, 2}}
+	results, err := GridSearchText(func(p Params) (classifiers.TextClassifier, error) {
+		lr := classifiers.NewLogisticRegression()
+		lr.Features.MaxN = p.Int("ngrams", 1)
+		return lr, nil
+	}, space, texts, labels, SearchOptions{Folds: 3, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results.Results) != 2 || results.Best.Err != nil || results.Best.Score < 0.7 {
+		t.Errorf("results\n%s", results)
+	}
+}
+
+func TestRandomSearchVector(t *testing.T) {
+	samples := [][]float64{
+		{1, 1}, {1.2, 0.8}, {0.9, 1.1}, {1.1, 1.2}, {0.8, 0.9}, {1, 1.3},
+		{5, 5}, {5.2, 4.8}, {4.9, 5.1}, {5.1, 5.2}, {4.8, 4.9}, {5, 5.3},
+	}
+	groups := []string{"a", "a", "a", "a", "a", "a", "b", "b", "b", "b", "b", "b"}
+	space := ParamSpace{"k": {1, 3, 5}, "method": {classifiers.DMT_EulerMethod, classifiers.DMT_CosineMethod}}
+	results, err := RandomSearchVector(func(p Params) (classifiers.VectorClassifier, error) {
+		method, _ := p["method"].(classifiers.DistanceMethodType)
+		return classifiers.NewKNearestNeighbors(p.Int("k", 1), method, nil), nil
+	}, space, 3, samples, groups, SearchOptions{Folds: 3, Seed: 2, Workers: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results.Results) != 3 || results.Best.MeanAccuracy < 0.9 {
+		t.Errorf("results\n%s", results)
+	}
+}
@@ -0,0 +1,217 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+// Package evaluation measures how well a classifier does on data it didn't
+// learn from: train/test splits and k-fold cross-validation, stratified so
+// every part keeps the label proportions, and a report with the accuracy,
+// the precision, recall and F1 of every label and their averages, and the
+// confusion matrix. It works with any model implementing the interfaces of
+// the classifiers package.
+package evaluation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ClassMetrics are the scores of a single label.
+type ClassMetrics struct {
+	// Of the samples predicted as the label, the share that had it.
+	Precision float64
+
+	// Of the samples that had the label, the share predicted as it.
+	Recall float64
+
+	// The harmonic mean of Precision and Recall.
+	F1 float64
+
+	// The number of samples that had the label.
+	Support int
+}
+
+// Report sums up the predictions of a classifier against the truth.
+type Report struct {
+	// The share of the samples predicted right.
+	Accuracy float64
+
+	// The scores of every label, by label.
+	Classes map[string]ClassMetrics
+
+	// The unweighted mean of the scores of the labels with support, every label counts the same.
+	MacroPrecision, MacroRecall, MacroF1 float64
+
+	// The scores computed over all the predictions at once, every sample counts the same.
+	MicroPrecision, MicroRecall, MicroF1 float64
+
+	// The mean of the scores of the labels weighted by their support.
+	WeightedF1 float64
+
+	Confusion *ConfusionMatrix
+}
+
+/**
+ * Confusion Matrix
+ *
+ * Counts[i][j] is the number of samples with label Labels[i] that were
+ * predicted as Labels[j], so right predictions are on the diagonal.
+ */
+type ConfusionMatrix struct {
+	Labels []string
+	Counts [][]int
+
+	index map[string]int
+}
+
+// NewConfusionMatrix counts the predictions of every label.
+func NewConfusionMatrix(truth, predicted []string) *ConfusionMatrix {
+	seen := make(map[string]bool)
+	for i := range truth {
+		seen[truth[i]] = true
+		seen[predicted[i]] = true
+	}
+	labels := make([]string, 0, len(seen))
+	for l := range seen {
+		labels = append(labels, l)
+	}
+	sort.Strings(labels)
+
+	m := &ConfusionMatrix{Labels: labels, Counts: make([][]int, len(labels)), index: map[string]int{}}
+	for i, l := range labels {
+		m.index[l] = i
+		m.Counts[i] = make([]int, len(labels))
+	}
+	for i := range truth {
+		m.Counts[m.index[truth[i]]][m.index[predicted[i]]]++
+	}
+	return m
+}
+
+// Count returns how many samples with the label truth were predicted as predicted.
+func (m *ConfusionMatrix) Count(truth, predicted string) int {
+	i, ok := m.index[truth]
+	j, ok2 := m.index[predicted]
+	if !ok || !ok2 {
+		return 0
+	}
+	return m.Counts[i][j]
+}
+
+// String prints the matrix with the true labels as rows.
+func (m *ConfusionMatrix) String() string {
+	width := 4
+	for _, l := range m.Labels {
+		if len(l) > width {
+			width = len(l)
+		}
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%*s", width, "")
+	for _, l := range m.Labels {
+		fmt.Fprintf(&b, " %*s", width, l)
+	}
+	b.WriteString("\n")
+	for i, l := range m.Labels {
+		fmt.Fprintf(&b, "%*s", width, l)
+		for _, n := range m.Counts[i] {
+			fmt.Fprintf(&b, " %*d", width, n)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Evaluate compares the predictions with the true labels.
+func Evaluate(truth, predicted []string) (Report, error) {
+	if len(truth) != len(predicted) {
+		return Report{}, fmt.Errorf("%d true labels but %d predictions: %w", len(truth), len(predicted), errNotEqualDataLength)
+	}
+	if len(truth) == 0 {
+		return Report{}, errNoSamples
+	}
+	m := NewConfusionMatrix(truth, predicted)
+	report := Report{Classes: make(map[string]ClassMetrics), Confusion: m}
+
+	var right, labelled int
+	for i, label := range m.Labels {
+		var support, predictedAs int
+		for j := range m.Labels {
+			support += m.Counts[i][j]
+			predictedAs += m.Counts[j][i]
+		}
+		tp := m.Counts[i][i]
+		right += tp
+		if support == 0 {
+			// only ever predicted, it gets no metrics of its own
+			continue
+		}
+		c := ClassMetrics{
+			Precision: ratio(tp, predictedAs),
+			Recall:    ratio(tp, support),
+			Support:   support,
+		}
+		c.F1 = f1(c.Precision, c.Recall)
+		report.Classes[label] = c
+
+		labelled++
+		report.MacroPrecision += c.Precision
+		report.MacroRecall += c.Recall
+		report.MacroF1 += c.F1
+		report.WeightedF1 += c.F1 * float64(support)
+	}
+	report.MacroPrecision /= float64(labelled)
+	report.MacroRecall /= float64(labelled)
+	report.MacroF1 /= float64(labelled)
+	report.WeightedF1 /= float64(len(truth))
+
+	report.Accuracy = ratio(right, len(truth))
+	// with one label per sample every wrong prediction is both a false
+	// positive and a false negative, so all micro scores are the accuracy
+	report.MicroPrecision = report.Accuracy
+	report.MicroRecall = report.Accuracy
+	report.MicroF1 = report.Accuracy
+	return report, nil
+}
+
+// String prints the scores of every label followed by the averages.
+func (r Report) String() string {
+	labels := make([]string, 0, len(r.Classes))
+	width := len("weighted")
+	for l := range r.Classes {
+		labels = append(labels, l)
+		if len(l) > width {
+			width = len(l)
+		}
+	}
+	sort.Strings(labels)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%*s %9s %9s %9s %9s\n", width, "", "precision", "recall", "f1", "support")
+	for _, l := range labels {
+		c := r.Classes[l]
+		fmt.Fprintf(&b, "%*s %9.3f %9.3f %9.3f %9d\n", width, l, c.Precision, c.Recall, c.F1, c.Support)
+	}
+	fmt.Fprintf(&b, "%*s %9.3f %9.3f %9.3f\n", width, "macro", r.MacroPrecision, r.MacroRecall, r.MacroF1)
+	fmt.Fprintf(&b, "%*s %9.3f %9.3f %9.3f\n", width, "micro", r.MicroPrecision, r.MicroRecall, r.MicroF1)
+	fmt.Fprintf(&b, "%*s %9s %9s %9.3f\n", width, "weighted", "", "", r.WeightedF1)
+	fmt.Fprintf(&b, "accuracy %.3f\n", r.Accuracy)
+	return b.String()
+}
+
+func ratio(n, d int) float64 {
+	if d == 0 {
+		return 0
+	}
+	return float64(n) / float64(d)
+}
+
+func f1(precision, recall float64) float64 {
+	if precision+recall == 0 {
+		return 0
+	}
+	return 2 * precision * recall / (precision + recall)
+}
@@ -0,0 +1,337 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package evaluation
+
+import (
+	"fmt"
+	"github.com/broosaction/gotext/classifiers"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type ScoreMetric uint8
+
+const (
+	// the share of the samples predicted right
+	SM_Accuracy ScoreMetric = iota
+	// the mean F1 of the labels, every label counts the same
+	SM_MacroF1
+	// the mean F1 of the labels weighted by their support
+	SM_WeightedF1
+)
+
+// Params is one configuration, the value of every parameter by name.
+type Params map[string]interface{}
+
+// String lists the parameters sorted by name, "k=3 tokenizer=DefaultTokenizer".
+func (p Params) String() string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%s=%v", name, p[name])
+	}
+	return strings.Join(parts, " ")
+}
+
+// Int returns an int parameter, or def when it is missing or not an int.
+func (p Params) Int(name string, def int) int {
+	if v, ok := p[name].(int); ok {
+		return v
+	}
+	return def
+}
+
+// Float returns a float64 parameter, or def when it is missing or not a float64.
+func (p Params) Float(name string, def float64) float64 {
+	if v, ok := p[name].(float64); ok {
+		return v
+	}
+	return def
+}
+
+// Str returns a string parameter, or def when it is missing or not a string.
+func (p Params) Str(name string, def string) string {
+	if v, ok := p[name].(string); ok {
+		return v
+	}
+	return def
+}
+
+// Bool returns a bool parameter, or def when it is missing or not a bool.
+func (p Params) Bool(name string, def bool) bool {
+	if v, ok := p[name].(bool); ok {
+		return v
+	}
+	return def
+}
+
+// ParamSpace holds the candidate values of every parameter.
+type ParamSpace map[string][]interface{}
+
+func (s ParamSpace) names() []string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// size is the number of configurations of the grid.
+func (s ParamSpace) size() int {
+	n := 1
+	for _, values := range s {
+		n *= len(values)
+	}
+	return n
+}
+
+// at returns configuration i of the grid, the last parameter by name changing fastest.
+func (s ParamSpace) at(i int) Params {
+	names := s.names()
+	p := make(Params, len(names))
+	for k := len(names) - 1; k >= 0; k-- {
+		values := s[names[k]]
+		p[names[k]] = values[i%len(values)]
+		i /= len(values)
+	}
+	return p
+}
+
+// Grid returns every combination of the values.
+func (s ParamSpace) Grid() []Params {
+	grid := make([]Params, s.size())
+	for i := range grid {
+		grid[i] = s.at(i)
+	}
+	return grid
+}
+
+// Sample returns n different combinations drawn at random, the whole grid when it is smaller.
+func (s ParamSpace) Sample(n int, seed int64) []Params {
+	size := s.size()
+	if n >= size {
+		return s.Grid()
+	}
+	picked := rand.New(rand.NewSource(seed)).Perm(size)[:n]
+	sort.Ints(picked)
+	sample := make([]Params, n)
+	for i, at := range picked {
+		sample[i] = s.at(at)
+	}
+	return sample
+}
+
+// SearchOptions tell how every configuration is cross-validated and compared.
+type SearchOptions struct {
+	// The number of folds, 5 when not set.
+	Folds int
+
+	// Seed of the folds, every configuration gets the same folds.
+	Seed int64
+
+	// The number of configurations evaluated at the same time, 0 means one per CPU.
+	Workers int
+
+	// What the configurations are ranked by.
+	Metric ScoreMetric
+}
+
+// SearchResult is the cross-validation of one configuration.
+type SearchResult struct {
+	Params Params
+
+	// The chosen metric over the out-of-fold predictions.
+	Score float64
+
+	MeanAccuracy, StdAccuracy float64
+	MacroF1                   float64
+
+	// Why the configuration couldn't be evaluated, it then ranks last.
+	Err error
+}
+
+// SearchResults are the results of every configuration, best first.
+type SearchResults struct {
+	Best    SearchResult
+	Results []SearchResult
+}
+
+// String prints the results table, best first.
+func (r SearchResults) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%4s %8s %8s %8s %8s  %s\n", "rank", "score", "accuracy", "std", "macroF1", "params")
+	for i, res := range r.Results {
+		if res.Err != nil {
+			fmt.Fprintf(&b, "%4d %8s %8s %8s %8s  %s: %v\n", i+1, "-", "-", "-", "-", res.Params, res.Err)
+			continue
+		}
+		fmt.Fprintf(&b, "%4d %8.4f %8.4f %8.4f %8.4f  %s\n", i+1, res.Score, res.MeanAccuracy, res.StdAccuracy, res.MacroF1, res.Params)
+	}
+	return b.String()
+}
+
+/**
+ * GridSearchText cross-validates a text classifier for every configuration
+ * of the space. newModel builds a fresh, untrained model for a configuration,
+ * tokenizer settings included, and is called once per fold.
+ *
+  **usage
+	space := evaluation.ParamSpace{
+		"tokenizer": {tokenizers.DefaultTokenizerName, tokenizers.NGramTokenizerName},
+		"alpha":     {0.1, 0.5, 1.0},
+	}
+	results, err := evaluation.GridSearchText(func(p evaluation.Params) (classifiers.TextClassifier, error) {
+		nb := classifiers.NewNaiveBayes()
+		nb.SetTokenizer(p.Str("tokenizer", tokenizers.DefaultTokenizerName))
+		nb.SetNGrams(1, 2)
+		nb.SetSmoothing(p.Float("alpha", 1))
+		return nb, nil
+	}, space, texts, labels, evaluation.SearchOptions{Folds: 5})
+	fmt.Println(results.Best.Params)
+	fmt.Print(results)
+*/
+func GridSearchText(newModel func(Params) (classifiers.TextClassifier, error), space ParamSpace, texts, labels []string, opts SearchOptions) (SearchResults, error) {
+	return search(space.Grid(), opts, textEvaluator(newModel, texts, labels, opts))
+}
+
+// RandomSearchText is GridSearchText over n configurations drawn at random from the space.
+func RandomSearchText(newModel func(Params) (classifiers.TextClassifier, error), space ParamSpace, n int, texts, labels []string, opts SearchOptions) (SearchResults, error) {
+	return search(space.Sample(n, opts.Seed), opts, textEvaluator(newModel, texts, labels, opts))
+}
+
+// GridSearchVector cross-validates a vector classifier for every configuration of the space, see GridSearchText.
+func GridSearchVector(newModel func(Params) (classifiers.VectorClassifier, error), space ParamSpace, samples [][]float64, labels []string, opts SearchOptions) (SearchResults, error) {
+	return search(space.Grid(), opts, vectorEvaluator(newModel, samples, labels, opts))
+}
+
+// RandomSearchVector is GridSearchVector over n configurations drawn at random from the space.
+func RandomSearchVector(newModel func(Params) (classifiers.VectorClassifier, error), space ParamSpace, n int, samples [][]float64, labels []string, opts SearchOptions) (SearchResults, error) {
+	return search(space.Sample(n, opts.Seed), opts, vectorEvaluator(newModel, samples, labels, opts))
+}
+
+func textEvaluator(newModel func(Params) (classifiers.TextClassifier, error), texts, labels []string, opts SearchOptions) func(Params) (CVResult, error) {
+	return func(p Params) (CVResult, error) {
+		var err error
+		cv, cvErr := CrossValidateText(func() classifiers.TextClassifier {
+			model, e := newModel(p)
+			if e != nil {
+				err = e
+				return failingText{e}
+			}
+			return model
+		}, texts, labels, opts.folds(), opts.Seed)
+		if err != nil {
+			return CVResult{}, err
+		}
+		return cv, cvErr
+	}
+}
+
+func vectorEvaluator(newModel func(Params) (classifiers.VectorClassifier, error), samples [][]float64, labels []string, opts SearchOptions) func(Params) (CVResult, error) {
+	return func(p Params) (CVResult, error) {
+		var err error
+		cv, cvErr := CrossValidateVector(func() classifiers.VectorClassifier {
+			model, e := newModel(p)
+			if e != nil {
+				err = e
+				return failingVector{e}
+			}
+			return model
+		}, samples, labels, opts.folds(), opts.Seed)
+		if err != nil {
+			return CVResult{}, err
+		}
+		return cv, cvErr
+	}
+}
+
+// failingText and failingVector stand in for a model that couldn't be built, their Fit fails.
+type failingText struct{ err error }
+
+func (f failingText) Fit([]string, []string) error   { return f.err }
+func (f failingText) Predict(string) (string, error) { return "", f.err }
+
+type failingVector struct{ err error }
+
+func (f failingVector) Fit([][]float64, []string) error      { return f.err }
+func (f failingVector) Predict([][]float64) ([]string, error) { return nil, f.err }
+
+func (o SearchOptions) folds() int {
+	if o.Folds == 0 {
+		return 5
+	}
+	return o.Folds
+}
+
+// search evaluates the configurations in parallel and ranks them.
+func search(configs []Params, opts SearchOptions, evaluate func(Params) (CVResult, error)) (SearchResults, error) {
+	if len(configs) == 0 {
+		return SearchResults{}, fmt.Errorf("the parameter space is empty")
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	results := make([]SearchResult, len(configs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = SearchResult{Params: configs[i]}
+				cv, err := evaluate(configs[i])
+				if err != nil {
+					results[i].Err = err
+					continue
+				}
+				results[i].MeanAccuracy = cv.MeanAccuracy
+				results[i].StdAccuracy = cv.StdAccuracy
+				results[i].MacroF1 = cv.Overall.MacroF1
+				results[i].Score = opts.Metric.of(cv.Overall)
+			}
+		}()
+	}
+	for i := range configs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	// stable, so equal scores keep the order of the space
+	sort.SliceStable(results, func(i, j int) bool {
+		if (results[i].Err == nil) != (results[j].Err == nil) {
+			return results[i].Err == nil
+		}
+		return results[i].Score > results[j].Score
+	})
+	if results[0].Err != nil {
+		return SearchResults{Results: results}, fmt.Errorf("no configuration could be evaluated: %w", results[0].Err)
+	}
+	return SearchResults{Best: results[0], Results: results}, nil
+}
+
+func (m ScoreMetric) of(r Report) float64 {
+	switch m {
+	case SM_MacroF1:
+		return r.MacroF1
+	case SM_WeightedF1:
+		return r.WeightedF1
+	default:
+		return r.Accuracy
+	}
+}
@@ -0,0 +1,236 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package evaluation
+
+import (
+	"errors"
+	"fmt"
+	"github.com/broosaction/gotext/classifiers"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+var (
+	errNotEqualDataLength = errors.New("the data length is not equal")
+	errNoSamples          = errors.New("there are no samples to evaluate")
+	errFolds              = errors.New("cross-validation needs at least 2 folds and a sample per fold")
+)
+
+// byLabel returns the sample indexes of every label, the labels sorted.
+func byLabel(labels []string) ([]string, map[string][]int) {
+	groups := make(map[string][]int)
+	for i, l := range labels {
+		groups[l] = append(groups[l], i)
+	}
+	names := make([]string, 0, len(groups))
+	for l := range groups {
+		names = append(names, l)
+	}
+	sort.Strings(names)
+	return names, groups
+}
+
+/**
+ * StratifiedSplit shuffles the samples into a train and a test set, putting
+ * a testFraction of the samples of every label in the test set, rounded. A
+ * label with a single sample stays in the train set.
+ */
+func StratifiedSplit(labels []string, testFraction float64, seed int64) (train, test []int) {
+	rng := rand.New(rand.NewSource(seed))
+	names, groups := byLabel(labels)
+	for _, l := range names {
+		group := groups[l]
+		rng.Shuffle(len(group), func(i, j int) { group[i], group[j] = group[j], group[i] })
+		held := int(math.Round(float64(len(group)) * testFraction))
+		if held >= len(group) {
+			held = len(group) - 1
+		}
+		test = append(test, group[:held]...)
+		train = append(train, group[held:]...)
+	}
+	rng.Shuffle(len(train), func(i, j int) { train[i], train[j] = train[j], train[i] })
+	rng.Shuffle(len(test), func(i, j int) { test[i], test[j] = test[j], test[i] })
+	return train, test
+}
+
+// KFold shuffles the sample indexes 0 to n-1 into k folds of almost equal size.
+func KFold(n, k int, seed int64) [][]int {
+	folds := make([][]int, k)
+	for i, sample := range rand.New(rand.NewSource(seed)).Perm(n) {
+		folds[i%k] = append(folds[i%k], sample)
+	}
+	return folds
+}
+
+// StratifiedKFold shuffles the samples into k folds, dealing the samples of
+// every label round-robin so each fold keeps the label proportions.
+func StratifiedKFold(labels []string, k int, seed int64) [][]int {
+	rng := rand.New(rand.NewSource(seed))
+	folds := make([][]int, k)
+	next := 0
+	names, groups := byLabel(labels)
+	for _, l := range names {
+		group := groups[l]
+		rng.Shuffle(len(group), func(i, j int) { group[i], group[j] = group[j], group[i] })
+		for _, sample := range group {
+			folds[next%k] = append(folds[next%k], sample)
+			next++
+		}
+	}
+	return folds
+}
+
+// CVResult is the outcome of a cross-validation.
+type CVResult struct {
+	// The report of every fold, evaluated on the fold after training on the others.
+	Folds []Report
+
+	// The report over the out-of-fold predictions of all the samples.
+	Overall Report
+
+	// The mean and standard deviation of the accuracy of the folds.
+	MeanAccuracy, StdAccuracy float64
+
+	// The out-of-fold prediction of every sample.
+	Predictions []string
+}
+
+/**
+ * CrossValidateText runs a stratified k-fold cross-validation of a text
+ * classifier. Every fold is predicted by a fresh model from newModel trained
+ * on the other folds. Texts the model can't classify count as wrong, with
+ * the empty label as prediction.
+ */
+func CrossValidateText(newModel func() classifiers.TextClassifier, texts, labels []string, k int, seed int64) (CVResult, error) {
+	if len(texts) != len(labels) {
+		return CVResult{}, fmt.Errorf("%d texts but %d labels: %w", len(texts), len(labels), errNotEqualDataLength)
+	}
+	return crossValidate(labels, k, seed, func(train, test []int) ([]string, error) {
+		model := newModel()
+		if err := model.Fit(pickStrings(texts, train), pickStrings(labels, train)); err != nil {
+			return nil, err
+		}
+		return predictTexts(model, pickStrings(texts, test))
+	})
+}
+
+// CrossValidateVector runs a stratified k-fold cross-validation of a vector classifier, see CrossValidateText.
+func CrossValidateVector(newModel func() classifiers.VectorClassifier, samples [][]float64, labels []string, k int, seed int64) (CVResult, error) {
+	if len(samples) != len(labels) {
+		return CVResult{}, fmt.Errorf("%d samples but %d labels: %w", len(samples), len(labels), errNotEqualDataLength)
+	}
+	return crossValidate(labels, k, seed, func(train, test []int) ([]string, error) {
+		model := newModel()
+		if err := model.Fit(pickVectors(samples, train), pickStrings(labels, train)); err != nil {
+			return nil, err
+		}
+		return model.Predict(pickVectors(samples, test))
+	})
+}
+
+// crossValidate predicts every fold with fit, which trains on the train indexes and predicts the test ones.
+func crossValidate(labels []string, k int, seed int64, fit func(train, test []int) ([]string, error)) (CVResult, error) {
+	if k < 2 || k > len(labels) {
+		return CVResult{}, errFolds
+	}
+	folds := StratifiedKFold(labels, k, seed)
+	result := CVResult{Predictions: make([]string, len(labels))}
+	for f, test := range folds {
+		var train []int
+		for g, fold := range folds {
+			if g != f {
+				train = append(train, fold...)
+			}
+		}
+		predicted, err := fit(train, test)
+		if err != nil {
+			return CVResult{}, fmt.Errorf("fold %d: %w", f, err)
+		}
+		for i, sample := range test {
+			result.Predictions[sample] = predicted[i]
+		}
+		report, err := Evaluate(pickStrings(labels, test), predicted)
+		if err != nil {
+			return CVResult{}, fmt.Errorf("fold %d: %w", f, err)
+		}
+		result.Folds = append(result.Folds, report)
+		result.MeanAccuracy += report.Accuracy / float64(k)
+	}
+	for _, report := range result.Folds {
+		result.StdAccuracy += math.Pow(report.Accuracy-result.MeanAccuracy, 2) / float64(k)
+	}
+	result.StdAccuracy = math.Sqrt(result.StdAccuracy)
+
+	var err error
+	result.Overall, err = Evaluate(labels, result.Predictions)
+	return result, err
+}
+
+// TrainTestText trains a fresh text classifier on a stratified split and evaluates it on the held out texts.
+func TrainTestText(newModel func() classifiers.TextClassifier, texts, labels []string, testFraction float64, seed int64) (Report, error) {
+	if len(texts) != len(labels) {
+		return Report{}, fmt.Errorf("%d texts but %d labels: %w", len(texts), len(labels), errNotEqualDataLength)
+	}
+	train, test := StratifiedSplit(labels, testFraction, seed)
+	model := newModel()
+	if err := model.Fit(pickStrings(texts, train), pickStrings(labels, train)); err != nil {
+		return Report{}, err
+	}
+	predicted, err := predictTexts(model, pickStrings(texts, test))
+	if err != nil {
+		return Report{}, err
+	}
+	return Evaluate(pickStrings(labels, test), predicted)
+}
+
+// TrainTestVector trains a fresh vector classifier on a stratified split and evaluates it on the held out samples.
+func TrainTestVector(newModel func() classifiers.VectorClassifier, samples [][]float64, labels []string, testFraction float64, seed int64) (Report, error) {
+	if len(samples) != len(labels) {
+		return Report{}, fmt.Errorf("%d samples but %d labels: %w", len(samples), len(labels), errNotEqualDataLength)
+	}
+	train, test := StratifiedSplit(labels, testFraction, seed)
+	model := newModel()
+	if err := model.Fit(pickVectors(samples, train), pickStrings(labels, train)); err != nil {
+		return Report{}, err
+	}
+	predicted, err := model.Predict(pickVectors(samples, test))
+	if err != nil {
+		return Report{}, err
+	}
+	return Evaluate(pickStrings(labels, test), predicted)
+}
+
+// predictTexts predicts every text, texts that can't be classified get the empty label.
+func predictTexts(model classifiers.TextClassifier, texts []string) ([]string, error) {
+	predicted := make([]string, len(texts))
+	for i, text := range texts {
+		label, err := model.Predict(text)
+		if err != nil && !errors.Is(err, classifiers.ErrNotClassified) {
+			return nil, fmt.Errorf("text %d: %w", i, err)
+		}
+		predicted[i] = label
+	}
+	return predicted, nil
+}
+
+func pickStrings(values []string, indexes []int) []string {
+	out := make([]string, len(indexes))
+	for i, at := range indexes {
+		out[i] = values[at]
+	}
+	return out
+}
+
+func pickVectors(values [][]float64, indexes []int) [][]float64 {
+	out := make([][]float64, len(indexes))
+	for i, at := range indexes {
+		out[i] = values[at]
+	}
+	return out
+}
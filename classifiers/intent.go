@@ -26,6 +26,13 @@ type IntentClassifier struct {
 	Tokenizer string
 }
 
+// intentClassifierData is what gob saves of an IntentClassifier, a sync.RWMutex can't be encoded.
+type intentClassifierData struct {
+	Feat2cat  map[string]map[string]int
+	CatCount  map[string]int
+	Tokenizer string
+}
+
 var(
 // ErrNotClassified indicates that a document could not be classified
  ErrNotClassified = errors.New("unable to classify document")
@@ -88,6 +95,27 @@ func (c *IntentClassifier) Classify(r string) (string, error) {
 
 
 
+// Probabilities of every category for the document, normalized to sum to 1.
+// The map is empty when no category gives the document a chance.
+func (c *IntentClassifier) Probabilities(r string) map[string]float64 {
+	c.Mu.RLock()
+	defer c.Mu.RUnlock()
+
+	probabilities := make(map[string]float64, len(c.CatCount))
+	var sum float64
+	for _, category := range c.categories() {
+		probabilities[category] = c.probability(r, category)
+		sum += probabilities[category]
+	}
+	if sum == 0 {
+		return map[string]float64{}
+	}
+	for category := range probabilities {
+		probabilities[category] /= sum
+	}
+	return probabilities
+}
+
 func (c *IntentClassifier) addFeature(feature string, category string) {
 	if _, ok := c.Feat2cat[feature]; !ok {
 		c.Feat2cat[feature] = make(map[string]int)
@@ -168,6 +196,54 @@ func asReader(text string) io.Reader {
 }
 
 
+// Fit trains on the texts one by one, see Train.
+func (c *IntentClassifier) Fit(texts []string, labels []string) error {
+	return fitText(func(text, label string) { c.Train(text, label) }, texts, labels)
+}
+
+// Predict is Classify.
+func (c *IntentClassifier) Predict(text string) (string, error) {
+	return c.Classify(text)
+}
+
+// PredictProba is Probabilities.
+func (c *IntentClassifier) PredictProba(text string) (map[string]float64, error) {
+	return c.Probabilities(text), nil
+}
+
+// GobEncode encodes the classifier without its Mu.
+func (c *IntentClassifier) GobEncode() ([]byte, error) {
+	c.Mu.RLock()
+	defer c.Mu.RUnlock()
+
+	buf := new(bytes.Buffer)
+	err := gob.NewEncoder(buf).Encode(intentClassifierData{
+		Feat2cat:  c.Feat2cat,
+		CatCount:  c.CatCount,
+		Tokenizer: c.Tokenizer,
+	})
+	return buf.Bytes(), err
+}
+
+// GobDecode replaces the counts and the tokenizer of the classifier by the encoded ones.
+func (c *IntentClassifier) GobDecode(data []byte) error {
+	var d intentClassifierData
+	if err := gob.NewDecoder(bytes.NewBuffer(data)).Decode(&d); err != nil {
+		return err
+	}
+	if d.Feat2cat == nil {
+		d.Feat2cat = make(map[string]map[string]int)
+	}
+	if d.CatCount == nil {
+		d.CatCount = make(map[string]int)
+	}
+
+	c.Mu.Lock()
+	defer c.Mu.Unlock()
+	c.Feat2cat, c.CatCount, c.Tokenizer = d.Feat2cat, d.CatCount, d.Tokenizer
+	return nil
+}
+
 //save to a file
 func (c *IntentClassifier) Save(file string) error {
 
@@ -195,7 +271,7 @@ func (c *IntentClassifier) Load(filePath string) error {
 	//get the classifier current meta data
 	name, version := c.getMeta()
 	if meta.Name != name {
-		return fmt.Errorf("This file doesn't contain a KNearestNeighbors classifier")
+		return fmt.Errorf("This file doesn't contain an IntentClassifier")
 	}
 	if meta.Version != version {
 		return fmt.Errorf("Can't understand this file format")
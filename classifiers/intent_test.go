@@ -0,0 +1,37 @@
+package classifiers
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestIntentClassifierSaveLoad(t *testing.T) {
+	c := NewIntentClassifier()
+	c.Train("the app crashes on start", "crash")
+	c.Train("I can not log in", "login")
+	file := filepath.Join(t.TempDir(), "intent.joi")
+	if err := c.Save(file); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := NewIntentClassifier()
+	loaded.Train("my card was declined", "payment")
+	if err := loaded.Load(file); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(loaded.CatCount, c.CatCount) || !reflect.DeepEqual(loaded.Feat2cat, c.Feat2cat) {
+		t.Errorf("loaded %v, want %v", loaded.CatCount, c.CatCount)
+	}
+	if got, err := loaded.Classify("the app crashes"); err != nil || got != "crash" {
+		t.Errorf("got %s, %v", got, err)
+	}
+
+	// the exported Mu still guards the counts
+	loaded.Mu.RLock()
+	n := len(loaded.CatCount)
+	loaded.Mu.RUnlock()
+	if n != 2 {
+		t.Errorf("%d categories", n)
+	}
+}
@@ -14,7 +14,7 @@ import (
 	"github.com/broosaction/gotext/tokenizers"
 	"github.com/broosaction/gotext/utils/persist"
 	"github.com/broosaction/gotext/utils/types"
-	"go.uber.org/zap"
+	"io"
 	"log"
 	"math"
 	"strings"
@@ -60,6 +60,9 @@ type NaiveBayes struct {
 	vocabularySize 	int
 	weigh 			weight
 	tokenizer 		string//tokenizers.Tokenizer
+	alpha           float64
+	// the range of the NGramTokenizer
+	minN, maxN      int
 }
 
 
@@ -68,7 +71,8 @@ type NaiveBayes struct {
  */
 type Class struct {
 	Name                    string
-	Counter                 int
+	// the number of documents learned, fractional when learned with a weight
+	Counter                 float64
 	Words                   map[string]types.Word
 	Probability             int
 	Temp_tokenProbabilities float64
@@ -88,14 +92,15 @@ type weight struct {
 // wordFrequency stores frequency of words. For example:
 // wordFrequency{
 //      word: "excellent"
-//	counter: map[string]int{
+//	counter: map[string]float64{
 //		"positive": 15
 //		"negative": 0
 //	}
 // }
+// The counts are fractional for words learned with a weight, see LearnWeighted.
 type wordFrequency struct {
 	Word    types.Word
-	Counter map[string]int
+	Counter map[string]float64
 }
 
 var (
@@ -114,12 +119,47 @@ func NewNaiveBayes() *NaiveBayes {
 	c.weigh = 			weight{}
 	tokenizer := tokenizers.DefaultTokenizer{}
 	c.tokenizer = 		tokenizer.GetName()
+	c.alpha =           1
+	c.minN, c.maxN =    1, 2
 	return c
 }
 
+// SetTokenizer sets the tokenizer, by name, see tokenizers.GetTokenizer.
+// It should be set before learning, texts are only tokenized once.
+func (nb *NaiveBayes) SetTokenizer(name string) {
+	nb.tokenizer = name
+}
+
+// SetSmoothing sets the additive smoothing constant, 1 (Laplace) by default.
+// Smaller values trust the counts of rare words more, it must be above 0.
+func (nb *NaiveBayes) SetSmoothing(alpha float64) {
+	nb.alpha = alpha
+}
+
+// SetNGrams sets the shortest and longest n-grams, in words, of the NGramTokenizer,
+// 1 and 2 by default. Like the tokenizer, it should be set before learning.
+func (nb *NaiveBayes) SetNGrams(min, max int) {
+	nb.minN, nb.maxN = min, max
+}
+
+// NGrams returns the range of the NGramTokenizer.
+func (nb *NaiveBayes) NGrams() (int, int) {
+	if nb.minN <= 0 || nb.maxN < nb.minN {
+		return 1, 2
+	}
+	return nb.minN, nb.maxN
+}
+
+// Smoothing returns the additive smoothing constant.
+func (nb *NaiveBayes) Smoothing() float64 {
+	if nb.alpha <= 0 {
+		return 1
+	}
+	return nb.alpha
+}
 
 func (nb *NaiveBayes) getMeta() (string, string) {
-	return "NaiveBayes", "01"
+	return "NaiveBayes", "02"
 }
 
 /**
@@ -142,6 +182,14 @@ func (nb *NaiveBayes) setClasses(name string) {
 
 }
 
+// countDocument records weight more learned documents of the class, for the class prior.
+func (nb *NaiveBayes) countDocument(name string, weight float64) {
+	nb.setClasses(name)
+	wf := nb.classes[name]
+	wf.Counter += weight
+	nb.classes[name] = wf
+}
+
 
 
 
@@ -150,50 +198,67 @@ func (nb *NaiveBayes) setClasses(name string) {
  * the `text` corresponds to.
  */
 func (nb *NaiveBayes) Learn(text, class string) {
-	nb.setClasses(class)
+	nb.LearnWeighted(text, class, 1)
+}
+
+/**
+ * LearnWeighted learns `text` as `weight` documents of `class`, every word
+ * count and the class document count growing by weight instead of 1. A
+ * fractional weight is a soft label, as learned from unlabeled texts by EM.
+ */
+func (nb *NaiveBayes) LearnWeighted(text, class string, weight float64) {
 	//normalize the text into a word array
+	nb.learnTokens(nb.tokenize(text), class, weight)
+}
 
-	tokens := tokenizers.GetTokenizer(nb.tokenizer).Tokenize(text)// nb.tokenizer.Tokenize(text)
+func (nb *NaiveBayes) tokenize(text string) []string {
+	if nb.tokenizer == tokenizers.NGramTokenizerName {
+		min, max := nb.NGrams()
+		return tokenizers.NGramTokenizer{Min: min, Max: max}.Tokenize(text)
+	}
+	return tokenizers.GetTokenizer(nb.tokenizer).Tokenize(text)
+}
 
+func (nb *NaiveBayes) learnTokens(tokens []string, class string, weight float64) {
+	nb.countDocument(class, weight)
 	for _, w := range tokens {
-		nb.addWord(w, class)
-
+		nb.addWord(w, class, weight)
 	}
 }
 
 func (nb *NaiveBayes) LearnSentence(sentence types.Sentence, class string) {
-	nb.setClasses(class)
+	nb.countDocument(class, 1)
 	//normalize the Sentence into a word array
 	sentence.PrepareWords()
 
 	tokens := sentence.Words
 
 	for _, w := range tokens {
-		nb.addWord(w.Text, class)
+		nb.addWord(w.Text, class, 1)
 
 	}
 }
 
 func (nb *NaiveBayes) LearnDocument(document types.Document, class string){
-	nb.setClasses(class)
+	nb.countDocument(class, 1)
 	document.PrepareSentences()
 	sentences := document.Sentences
 	for _, s := range sentences {
 		words := s.Words
 		for _, w := range words {
-			nb.addWord(w.Text, class)
+			nb.addWord(w.Text, class, 1)
 		}
 
 	}
 }
 
-func (nb *NaiveBayes) addWord(word, class string) {
+func (nb *NaiveBayes) addWord(word, class string, weight float64) {
 	word = strings.ToLower(word)
 	wf, ok := nb.words[word]
 	if !ok {
-		wf = wordFrequency{Word: types.NewWord(word), Counter: map[string]int{}}
+		wf = wordFrequency{Word: types.NewWord(word), Counter: map[string]float64{}}
 	}
-	wf.Counter[class]++
+	wf.Counter[class] += weight
 	nb.words[word] = wf
 	nb.classes[class].Words[word] = types.NewWord(word)
 	nb.vocabularySize++
@@ -201,85 +266,108 @@ func (nb *NaiveBayes) addWord(word, class string) {
 }
 
 /**
- * Calculate probability that a `token` belongs to a `category` or class
- *
+ * Determine what category or class `text` belongs to, and its probability,
+ * the most probable class of Probabilities. An untrained model returns an
+ * empty class.
  */
-func (nb *NaiveBayes) tokenProbability(token, category string) float64 {
-	//how many times this word has occurred in documents mapped to this category
-	wordFrequencyCount := nb.words[token].Counter[category]
-	//what is the count of all words that have ever been mapped to this category
-	wordCount := len(nb.classes[category].Words)
-
-	//use laplace Add-1 Smoothing equation
-	return (float64(wordFrequencyCount) + 1) / (float64(wordCount) + float64(nb.vocabularySize))
+func (nb *NaiveBayes) Classify(text string) (string, float64) {
+	probabilities := nb.Probabilities(text)
+	class, err := bestLabel(probabilities)
+	if err != nil {
+		return "", 0
+	}
+	return class, probabilities[class]
 }
 
 /**
- * Determine what category or class `text` belongs to.
+ * Probabilities of every class for `text`, the posterior of a multinomial
+ * naive-bayes model with additive smoothing, alpha being 1 by default:
+ *
+ *	P(c | text) ∝ P(c) * Π P(w | c) ^ count(w),  P(w | c) = (count(w, c) + alpha) / (words(c) + alpha * |V|)
+ *
+ * where P(c) is the share of the learned documents in class c (uniform for
+ * models saved before documents were counted), words(c) is the number of
+ * words learned for c and |V| the number of distinct words. Words never
+ * learned are skipped. The sum is done with logs so long texts don't underflow.
  */
-func (nb *NaiveBayes) Classify(text string) (string, float64) {
-	text = strings.ToLower(text)
-	//var maxProbability = -syscall.INFINITE
-	var top_probability = 0.0000
-	var chosenCategory string = ""
-	tokens :=  tokenizers.GetTokenizer(nb.tokenizer).Tokenize(text)//nb.tokenizer.Tokenize(text)
-
-	logger, _ := zap.NewProduction()
-	defer logger.Sync()
-	for _, class := range nb.classes {
-
-		//start by calculating the overall probability of this category
-		//=>  out of all documents we've ever looked at, how many were
-		//    mapped to this category
-		categoryProbability := len(class.Words) / len(nb.words)
-		class.Probability = categoryProbability
+func (nb *NaiveBayes) Probabilities(text string) map[string]float64 {
+	probabilities, _ := nb.posterior(nb.tokenize(strings.ToLower(text)), nb.totals())
+	return probabilities
+}
 
-		//take the log to avoid underflow
-		logProbability := math.Log(float64(categoryProbability))
-		tokens_w := map[string]int{}
-		for _, w := range tokens {
+// nbTotals are the sums over the whole model the posterior needs, computed
+// once for many texts.
+type nbTotals struct {
+	// the number of words learned for every class
+	words      map[string]float64
+	documents  float64
+	vocabulary float64
+}
 
-			tokens_w[w]++
+func (nb *NaiveBayes) totals() nbTotals {
+	t := nbTotals{words: make(map[string]float64, len(nb.classes)), vocabulary: float64(len(nb.words))}
+	for _, wf := range nb.words {
+		for class, n := range wf.Counter {
+			t.words[class] += n
 		}
-		for _, w := range tokens {
-
-			frequencyInText := tokens_w[w]
-			tokenProbability := nb.tokenProbability(w, class.Name)
-
-
-			logger.Info("processed",
-				// Structured context as strongly typed Field values.
-				zap.String("token", w),
-				zap.String("category", class.Name),
-				zap.Float64("token Probability", tokenProbability),
-			)
+	}
+	for _, class := range nb.classes {
+		t.documents += class.Counter
+	}
+	return t
+}
 
-			class.Temp_tokenProbabilities += tokenProbability
+// posterior returns the probability of every class for the tokens, and the
+// log of the probability of the tokens themselves, summed over the classes.
+func (nb *NaiveBayes) posterior(tokens []string, t nbTotals) (map[string]float64, float64) {
+	probabilities := make(map[string]float64, len(nb.classes))
+	if len(nb.classes) == 0 {
+		return probabilities, 0
+	}
+	alpha := nb.Smoothing()
 
-			//determine the log of the P( w | c ) for this word
-			logProbability += float64(frequencyInText) * math.Log(tokenProbability)
+	max := math.Inf(-1)
+	for name, class := range nb.classes {
+		logProbability := -math.Log(float64(len(nb.classes)))
+		if t.documents > 0 {
+			logProbability = math.Log(class.Counter / t.documents)
 		}
-		if class.Temp_tokenProbabilities > top_probability {
-			top_probability = class.Temp_tokenProbabilities
-			chosenCategory = class.Name
+		for _, w := range tokens {
+			wf, ok := nb.words[w]
+			if !ok {
+				continue
+			}
+			logProbability += math.Log((wf.Counter[name] + alpha) / (t.words[name] + alpha*t.vocabulary))
 		}
-         nb.weigh = weight{
-         	Amount: class.Temp_tokenProbabilities,
-         	Class: class,
-		 }
-		/*if (logProbability > maxProbability) {
-			maxProbability = int(logProbability)
-			chosenCategory = class.name
-		} */
+		probabilities[name] = logProbability
+		max = math.Max(max, logProbability)
+	}
 
-		//a reset is okay for online learning.
-		class.Temp_tokenProbabilities = 0.0000
+	var sum float64
+	for name, logProbability := range probabilities {
+		probabilities[name] = math.Exp(logProbability - max)
+		sum += probabilities[name]
+	}
+	for name := range probabilities {
+		probabilities[name] /= sum
 	}
-	return chosenCategory, top_probability
+	return probabilities, max + math.Log(sum)
 }
 
+// Fit learns the texts one by one, see Learn.
+func (nb *NaiveBayes) Fit(texts []string, labels []string) error {
+	return fitText(nb.Learn, texts, labels)
+}
 
+// Predict returns the most probable class of the text, see Probabilities.
+func (nb *NaiveBayes) Predict(text string) (string, error) {
+	return bestLabel(nb.Probabilities(text))
+}
 
+// PredictProba is Probabilities.
+func (nb *NaiveBayes) PredictProba(text string) (map[string]float64, error) {
+	return nb.Probabilities(text), nil
+}
 
 // GobEncode implements GobEncoder. This is necessary because RNN contains several unexported fields.
 // It would be easier to simply export them by changing to uppercase, but for comparison purposes,
@@ -296,11 +384,22 @@ func (nb *NaiveBayes) GobEncode() ([]byte, error) {
 			err = encoder.Encode(data)
 		}
 	}
-	encode(nb.words)
-	encode(nb.classes)
+	words := make(map[string]savedWordFrequency, len(nb.words))
+	for w, wf := range nb.words {
+		words[w] = savedWordFrequency{Word: saveWord(wf.Word), Counter: wf.Counter}
+	}
+	classes := make(map[string]savedClass, len(nb.classes))
+	for name, c := range nb.classes {
+		classes[name] = saveClass(c)
+	}
+	encode(words)
+	encode(classes)
 	encode(nb.vocabularySize)
-	encode(nb.weigh)
+	encode(savedWeight{Amount: nb.weigh.Amount, Class: saveClass(nb.weigh.Class)})
 	encode(nb.tokenizer)
+	encode(nb.alpha)
+	encode(nb.minN)
+	encode(nb.maxN)
 
 	return b.Bytes(), err
 }
@@ -333,7 +432,8 @@ func (nb *NaiveBayes) Load(filePath string) error {
 	if meta.Name != name {
 		return fmt.Errorf("This file doesn't contain a Naive-Bayes classifier")
 	}
-	if meta.Version != version {
+	// version 01 counted in integers, GobDecode converts its counts
+	if meta.Version != version && meta.Version != "01" {
 		return fmt.Errorf("Can't understand this file format")
 	}
 
@@ -349,6 +449,131 @@ func (nb *NaiveBayes) Load(filePath string) error {
 
 // GobDecode implements GoDecoder.
 func (nb *NaiveBayes) GobDecode(data []byte) error {
+	err := nb.decode(data, false)
+	if err != nil {
+		// models of version 01 counted in integers, the counts can't be decoded as floats
+		if nb.decode(data, true) == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// savedWord is types.Word as models save it, with the float64 Vector words had
+// before they got embeddings, so models saved before still load. The classifier
+// never sets a vector, none is lost.
+type savedWord struct {
+	Text     string
+	PosTag   string
+	Stem     string
+	Vector   float64
+	Meaning  string
+	Letters  []string
+	Synonyms []string
+}
+
+func saveWord(w types.Word) savedWord {
+	return savedWord{
+		Text:     w.Text,
+		PosTag:   w.PosTag,
+		Stem:     w.Stem,
+		Meaning:  w.Meaning,
+		Letters:  w.Letters,
+		Synonyms: w.Synonyms,
+	}
+}
+
+func (w savedWord) word() types.Word {
+	word := types.NewWord(w.Text)
+	word.PosTag, word.Stem, word.Meaning = w.PosTag, w.Stem, w.Meaning
+	if w.Letters != nil {
+		word.Letters = w.Letters
+	}
+	if w.Synonyms != nil {
+		word.Synonyms = w.Synonyms
+	}
+	return word
+}
+
+func loadWords(saved map[string]savedWord) map[string]types.Word {
+	words := make(map[string]types.Word, len(saved))
+	for w, word := range saved {
+		words[w] = word.word()
+	}
+	return words
+}
+
+// savedClass, savedWordFrequency and savedWeight are Class, wordFrequency and weight as saved.
+type savedClass struct {
+	Name                    string
+	Counter                 float64
+	Words                   map[string]savedWord
+	Probability             int
+	Temp_tokenProbabilities float64
+}
+
+type savedWordFrequency struct {
+	Word    savedWord
+	Counter map[string]float64
+}
+
+type savedWeight struct {
+	Amount float64
+	Class  savedClass
+}
+
+func saveClass(c Class) savedClass {
+	var words map[string]savedWord
+	if c.Words != nil {
+		words = make(map[string]savedWord, len(c.Words))
+		for w, word := range c.Words {
+			words[w] = saveWord(word)
+		}
+	}
+	return savedClass{
+		Name:                    c.Name,
+		Counter:                 c.Counter,
+		Words:                   words,
+		Probability:             c.Probability,
+		Temp_tokenProbabilities: c.Temp_tokenProbabilities,
+	}
+}
+
+func (c savedClass) class() Class {
+	return Class{
+		Name:                    c.Name,
+		Counter:                 c.Counter,
+		Words:                   loadWords(c.Words),
+		Probability:             c.Probability,
+		Temp_tokenProbabilities: c.Temp_tokenProbabilities,
+	}
+}
+
+// v01Class and v01WordFrequency are Class and wordFrequency as version 01 saved them.
+type v01Class struct {
+	Name                    string
+	Counter                 int
+	Words                   map[string]savedWord
+	Probability             int
+	Temp_tokenProbabilities float64
+}
+
+type v01WordFrequency struct {
+	Word    savedWord
+	Counter map[string]int
+}
+
+func (c v01Class) upgrade() Class {
+	return Class{
+		Name:                    c.Name,
+		Counter:                 float64(c.Counter),
+		Words:                   loadWords(c.Words),
+		Probability:             c.Probability,
+		Temp_tokenProbabilities: c.Temp_tokenProbabilities,
+	}
+}
+
+func (nb *NaiveBayes) decode(data []byte, v01 bool) error {
 	b := bytes.NewBuffer(data)
 	decoder := gob.NewDecoder(b)
 
@@ -360,10 +585,70 @@ func (nb *NaiveBayes) GobDecode(data []byte) error {
 			err = decoder.Decode(data)
 		}
 	}
-	decode(&nb.words)
-	decode(&nb.classes)
-	decode(&nb.vocabularySize)
-	decode(&nb.weigh)
+	if v01 {
+		var words map[string]v01WordFrequency
+		var classes map[string]v01Class
+		var weigh struct {
+			Amount float64
+			Class  v01Class
+		}
+		decode(&words)
+		decode(&classes)
+		decode(&nb.vocabularySize)
+		decode(&weigh)
+		if err != nil {
+			return err
+		}
+		nb.words = make(map[string]wordFrequency, len(words))
+		for w, wf := range words {
+			counter := make(map[string]float64, len(wf.Counter))
+			for class, n := range wf.Counter {
+				counter[class] = float64(n)
+			}
+			nb.words[w] = wordFrequency{Word: wf.Word.word(), Counter: counter}
+		}
+		nb.classes = make(map[string]Class, len(classes))
+		for name, c := range classes {
+			nb.classes[name] = c.upgrade()
+		}
+		nb.weigh = weight{Amount: weigh.Amount, Class: weigh.Class.upgrade()}
+	} else {
+		var words map[string]savedWordFrequency
+		var classes map[string]savedClass
+		var weigh savedWeight
+		decode(&words)
+		decode(&classes)
+		decode(&nb.vocabularySize)
+		decode(&weigh)
+		if err != nil {
+			return err
+		}
+		nb.words = make(map[string]wordFrequency, len(words))
+		for w, wf := range words {
+			counter := wf.Counter
+			if counter == nil {
+				counter = map[string]float64{}
+			}
+			nb.words[w] = wordFrequency{Word: wf.Word.word(), Counter: counter}
+		}
+		nb.classes = make(map[string]Class, len(classes))
+		for name, c := range classes {
+			nb.classes[name] = c.class()
+		}
+		nb.weigh = weight{Amount: weigh.Amount, Class: weigh.Class.class()}
+	}
 	decode(&nb.tokenizer)
+	// models saved before the smoothing or the n-gram range could be set end here
+	for _, optional := range []interface{}{&nb.alpha, &nb.minN, &nb.maxN} {
+		if err != nil {
+			break
+		}
+		if e := decoder.Decode(optional); e != nil {
+			if e != io.EOF {
+				err = e
+			}
+			break
+		}
+	}
 	return err
-}
\ No newline at end of file
+}
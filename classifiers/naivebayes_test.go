@@ -0,0 +1,128 @@
+package classifiers
+
+import (
+	"bytes"
+	"encoding/gob"
+	"github.com/broosaction/gotext/tokenizers"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+// word02 is types.Word before word vectors, when Vector was a float64.
+type word02 struct {
+	Text     string
+	PosTag   string
+	Stem     string
+	Vector   float64
+	Meaning  string
+	Letters  []string
+	Synonyms []string
+}
+
+type class02 struct {
+	Name                    string
+	Counter                 float64
+	Words                   map[string]word02
+	Probability             int
+	Temp_tokenProbabilities float64
+}
+
+func TestNaiveBayesDecodesFloatVectorWords(t *testing.T) {
+	var b bytes.Buffer
+	encoder := gob.NewEncoder(&b)
+	rain := word02{Text: "rain", Letters: []string{"r", "a", "i", "n"}}
+	jazz := word02{Text: "jazz", Letters: []string{"j", "a", "z", "z"}}
+	words := map[string]struct {
+		Word    word02
+		Counter map[string]float64
+	}{
+		"rain": {rain, map[string]float64{"weather": 2}},
+		"jazz": {jazz, map[string]float64{"music": 1.5}},
+	}
+	classes := map[string]class02{
+		"weather": {Name: "weather", Counter: 2, Words: map[string]word02{"rain": rain}},
+		"music":   {Name: "music", Counter: 1.5, Words: map[string]word02{"jazz": jazz}},
+	}
+	for _, data := range []interface{}{words, classes, 2, struct {
+		Amount float64
+		Class  class02
+	}{}, "DefaultTokenizer", 0.5} {
+		if err := encoder.Encode(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	nb := NewNaiveBayes()
+	if err := nb.GobDecode(b.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	if nb.words["jazz"].Counter["music"] != 1.5 || nb.classes["weather"].Words["rain"].Text != "rain" || nb.Smoothing() != 0.5 {
+		t.Errorf("decoded %+v %+v", nb.words, nb.classes)
+	}
+	if got, err := nb.Predict("jazz"); err != nil || got != "music" {
+		t.Errorf("got %s, %v", got, err)
+	}
+	// the decoded classes keep learning
+	nb.Learn("rain again", "weather")
+}
+
+func TestNaiveBayesSaveLoad(t *testing.T) {
+	nb := NewNaiveBayes()
+	nb.Fit(emLabeled, emLabels)
+	file := filepath.Join(t.TempDir(), "nb.joi")
+	if err := nb.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewNaiveBayes()
+	if err := loaded.Load(file); err != nil {
+		t.Fatal(err)
+	}
+	for _, text := range emUnlabeled {
+		want, _ := nb.Predict(text)
+		if got, err := loaded.Predict(text); err != nil || got != want {
+			t.Errorf("%s: got %s, want %s", text, got, want)
+		}
+	}
+}
+
+func TestNaiveBayesClassifyAgreesWithPredict(t *testing.T) {
+	nb := NewNaiveBayes()
+	if class, p := nb.Classify("anything"); class != "" || p != 0 {
+		t.Errorf("untrained model gave %s %f", class, p)
+	}
+	// a class with many short texts next to one with a few long ones
+	nb.Fit(textTrain, textLabels)
+	nb.Learn("music music music music songs albums playlists guitars drums concerts", "music")
+	for _, text := range append(textTrain, "play rain music", "alarm for the weather", "a song about the rain") {
+		class, p := nb.Classify(text)
+		want, err := nb.Predict(text)
+		if err != nil || class != want || math.Abs(p-nb.Probabilities(text)[want]) > 1e-9 {
+			t.Errorf("%q: Classify gave %s %f, Predict %s", text, class, p, want)
+		}
+	}
+}
+
+func TestNaiveBayesNGrams(t *testing.T) {
+	nb := NewNaiveBayes()
+	nb.SetTokenizer(tokenizers.NGramTokenizerName)
+	nb.Learn("play some jazz", "music")
+	for _, gram := range []string{"play", "jazz", "play some", "some jazz"} {
+		if _, ok := nb.words[gram]; !ok {
+			t.Errorf("%q wasn't learned: %v", gram, nb.words)
+		}
+	}
+
+	nb.SetNGrams(2, 3)
+	file := filepath.Join(t.TempDir(), "ngrams.joi")
+	if err := nb.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewNaiveBayes()
+	if err := loaded.Load(file); err != nil {
+		t.Fatal(err)
+	}
+	if min, max := loaded.NGrams(); min != 2 || max != 3 {
+		t.Errorf("loaded n-grams %d to %d", min, max)
+	}
+}
@@ -80,9 +80,9 @@ func main() {
 
 		knn := classifiers.NewKNearestNeighbors(len(labels), dm, w)
 		knn.LearnBatch(train, labels)
-		res := knn.Classify(test)
+		res, err := knn.Classify(test)
 
-			fmt.Println(res)
+			fmt.Println(res, err)
 
 
 
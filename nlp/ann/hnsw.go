@@ -0,0 +1,554 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package ann
+
+import (
+	"bytes"
+	"container/heap"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"github.com/broosaction/gotext/utils/persist"
+	"log"
+	"math"
+	"math/rand"
+	"sync"
+)
+
+type MetricType uint8
+
+const (
+	// 1 - cosine similarity, vectors are normalized when inserted
+	MT_Cosine MetricType = iota
+	// negative dot product, for embeddings trained for inner product search
+	MT_DotProduct
+	// straight line distance
+	MT_Euclidean
+)
+
+var (
+	errDimension  = errors.New("the vector dimension doesn't match the index")
+	errZeroVector = errors.New("a zero vector has no direction for cosine distance")
+)
+
+/**
+ * HNSW (Hierarchical Navigable Small World)
+ *
+ * An approximate nearest neighbor index for dense vectors. Every vector is a
+ * node in a stack of proximity graphs, each layer holding an exponentially
+ * smaller share of the nodes. A search greedily walks the sparse top layers to
+ * find a good entry point and then explores the dense bottom layer, which gives
+ * logarithmic query time at a small loss of recall.
+ *
+ * M controls how many links every node keeps (more links, better recall, more
+ * memory), EfConstruction how thorough inserts are and EfSearch how thorough
+ * queries are.
+ *
+ * @category    Machine Learning
+
+  **usage
+	index := ann.NewHNSW(ann.MT_Cosine, 16, 200)
+
+	index.Insert(1, embedding("how do I reset my password"))
+	index.Insert(2, embedding("where can I download my invoice"))
+
+	results, _ := index.Search(embedding("forgot password"), 1)
+	fmt.Println(results[0].ID)
+*/
+type HNSW struct {
+	Metric MetricType
+
+	// maximum number of links a node keeps on every layer but the bottom one,
+	// which keeps twice as many.
+	M int
+
+	// size of the dynamic candidate list while inserting
+	EfConstruction int
+
+	// size of the dynamic candidate list while searching, raised to k when lower
+	EfSearch int
+
+	// dimension of the vectors, fixed by the first insert
+	Dim int
+
+	Nodes []Node
+
+	// position in Nodes of every live ID
+	Positions map[int]int
+
+	// node every search starts from, -1 while the index is empty
+	EntryPoint int
+
+	// number of deleted nodes still linked into the graph
+	Deleted int
+
+	// seeds the random levels so that building the same data gives the same index
+	Seed int64
+
+	// mu guards the graph, writes also hold writeMu so that Compact can
+	// rebuild the graph on the side while searches go on.
+	mu      sync.RWMutex
+	writeMu sync.Mutex
+	rng     *rand.Rand
+	visited sync.Pool
+}
+
+// Node is a vector of the index together with its links.
+type Node struct {
+	ID      int
+	Vector  []float64
+	Friends [][]int
+	Deleted bool
+}
+
+// Result is a vector found by a search.
+type Result struct {
+	ID       int
+	Distance float64
+}
+
+// NewHNSW creates an empty index, m and efConstruction fall back to 16 and 200
+// when they are not positive.
+func NewHNSW(metric MetricType, m, efConstruction int) *HNSW {
+	if m <= 0 {
+		m = 16
+	}
+	if efConstruction <= 0 {
+		efConstruction = 200
+	}
+	return &HNSW{
+		Metric:         metric,
+		M:              m,
+		EfConstruction: efConstruction,
+		EfSearch:       50,
+		Positions:      make(map[int]int),
+		EntryPoint:     -1,
+		Seed:           42,
+	}
+}
+
+func (h *HNSW) getMeta() (string, string) {
+	return "HNSW", "01"
+}
+
+// Len returns the number of vectors that can be found.
+func (h *HNSW) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.Positions)
+}
+
+// Vector returns a copy of the stored vector of id, normalized for cosine.
+func (h *HNSW) Vector(id int) ([]float64, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	pos, ok := h.Positions[id]
+	if !ok {
+		return nil, false
+	}
+	v := make([]float64, len(h.Nodes[pos].Vector))
+	copy(v, h.Nodes[pos].Vector)
+	return v, true
+}
+
+func (h *HNSW) distance(a, b []float64) float64 {
+	switch h.Metric {
+	case MT_Euclidean:
+		var sum float64
+		for i, x := range a {
+			d := x - b[i]
+			sum += d * d
+		}
+		return math.Sqrt(sum)
+	case MT_DotProduct:
+		return -dot(a, b)
+	default:
+		return 1 - dot(a, b)
+	}
+}
+
+func dot(a, b []float64) float64 {
+	// four independent sums let the CPU overlap the multiplications,
+	// this is where nearly all of the search time goes
+	var s0, s1, s2, s3 float64
+	i := 0
+	for ; i+4 <= len(a); i += 4 {
+		s0 += a[i] * b[i]
+		s1 += a[i+1] * b[i+1]
+		s2 += a[i+2] * b[i+2]
+		s3 += a[i+3] * b[i+3]
+	}
+	for ; i < len(a); i++ {
+		s0 += a[i] * b[i]
+	}
+	return s0 + s1 + s2 + s3
+}
+
+// prepare validates a vector and brings it into the form the index stores, mu must be held.
+func (h *HNSW) prepare(vector []float64) ([]float64, error) {
+	if h.Dim != 0 && len(vector) != h.Dim {
+		return nil, errDimension
+	}
+	v := make([]float64, len(vector))
+	copy(v, vector)
+	if h.Metric == MT_Cosine {
+		norm := math.Sqrt(dot(v, v))
+		if norm == 0 {
+			return nil, errZeroVector
+		}
+		for i := range v {
+			v[i] /= norm
+		}
+	}
+	return v, nil
+}
+
+func (h *HNSW) randomLevel() int {
+	if h.rng == nil {
+		h.rng = rand.New(rand.NewSource(h.Seed + int64(len(h.Nodes))))
+	}
+	ml := 1 / math.Log(float64(h.M))
+	return int(-math.Log(1-h.rng.Float64()) * ml)
+}
+
+func (h *HNSW) maxFriends(level int) int {
+	if level == 0 {
+		return 2 * h.M
+	}
+	return h.M
+}
+
+// Insert adds a vector under id, replacing the vector id had before.
+func (h *HNSW) Insert(id int, vector []float64) error {
+	h.writeMu.Lock()
+	defer h.writeMu.Unlock()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	v, err := h.prepare(vector)
+	if err != nil {
+		return err
+	}
+	h.insert(id, v)
+	return nil
+}
+
+// insert links a prepared vector into the graph, mu must be held.
+func (h *HNSW) insert(id int, v []float64) {
+	if h.Dim == 0 {
+		h.Dim = len(v)
+	}
+	if pos, ok := h.Positions[id]; ok {
+		h.Nodes[pos].Deleted = true
+		h.Deleted++
+	}
+
+	level := h.randomLevel()
+	pos := len(h.Nodes)
+	h.Nodes = append(h.Nodes, Node{ID: id, Vector: v, Friends: make([][]int, level+1)})
+	h.Positions[id] = pos
+
+	if h.EntryPoint < 0 {
+		h.EntryPoint = pos
+		return
+	}
+
+	entry := h.EntryPoint
+	top := len(h.Nodes[entry].Friends) - 1
+	// greedy walk down to the first layer the new node lives on
+	for l := top; l > level; l-- {
+		entry = h.greedy(v, entry, l)
+	}
+	for l := min(level, top); l >= 0; l-- {
+		found := h.searchLayer(v, []int{entry}, h.EfConstruction, l)
+		friends := h.selectNeighbors(v, found, h.M)
+		h.Nodes[pos].Friends[l] = friends
+		for _, f := range friends {
+			h.link(f, pos, l)
+		}
+		entry = found[0].node
+	}
+	if level > top {
+		h.EntryPoint = pos
+	}
+}
+
+// link adds a link from node to friend on layer l, shrinking the friend list
+// with the neighbor heuristic once it grows beyond its limit.
+func (h *HNSW) link(node, friend, l int) {
+	friends := append(h.Nodes[node].Friends[l], friend)
+	if len(friends) > h.maxFriends(l) {
+		v := h.Nodes[node].Vector
+		c := make([]candidate, len(friends))
+		for i, f := range friends {
+			c[i] = candidate{node: f, dist: h.distance(v, h.Nodes[f].Vector)}
+		}
+		sortCandidates(c)
+		friends = h.selectNeighbors(v, c, h.maxFriends(l))
+	}
+	h.Nodes[node].Friends[l] = friends
+}
+
+// selectNeighbors picks up to m links out of candidates sorted by distance.
+// A candidate is preferred when it is closer to the node than to every link
+// picked so far, which keeps links pointing in different directions and the
+// graph navigable. Free slots are then filled with the closest leftovers.
+func (h *HNSW) selectNeighbors(v []float64, c []candidate, m int) []int {
+	selected := make([]int, 0, m)
+	var skipped []int
+	for _, cand := range c {
+		if len(selected) == m {
+			break
+		}
+		good := true
+		for _, s := range selected {
+			if h.distance(h.Nodes[cand.node].Vector, h.Nodes[s].Vector) < cand.dist {
+				good = false
+				break
+			}
+		}
+		if good {
+			selected = append(selected, cand.node)
+		} else {
+			skipped = append(skipped, cand.node)
+		}
+	}
+	for _, s := range skipped {
+		if len(selected) == m {
+			break
+		}
+		selected = append(selected, s)
+	}
+	return selected
+}
+
+// greedy moves from entry to the closest node reachable on layer l.
+func (h *HNSW) greedy(v []float64, entry, l int) int {
+	best := h.distance(v, h.Nodes[entry].Vector)
+	for changed := true; changed; {
+		changed = false
+		for _, f := range h.Nodes[entry].Friends[l] {
+			if d := h.distance(v, h.Nodes[f].Vector); d < best {
+				entry, best, changed = f, d, true
+			}
+		}
+	}
+	return entry
+}
+
+// searchLayer returns the ef nodes closest to v found on layer l, sorted by
+// distance. Deleted nodes are still walked through as they keep the graph connected.
+func (h *HNSW) searchLayer(v []float64, entries []int, ef, l int) []candidate {
+	visited := h.visitedSet()
+	defer h.visited.Put(visited)
+
+	var toVisit minCandidates
+	var found maxCandidates
+	for _, e := range entries {
+		c := candidate{node: e, dist: h.distance(v, h.Nodes[e].Vector)}
+		visited.add(e)
+		heap.Push(&toVisit, c)
+		heap.Push(&found, c)
+	}
+
+	for toVisit.Len() > 0 {
+		c := heap.Pop(&toVisit).(candidate)
+		if c.dist > found[0].dist && found.Len() >= ef {
+			break
+		}
+		for _, f := range h.Nodes[c.node].Friends[l] {
+			if visited.has(f) {
+				continue
+			}
+			visited.add(f)
+			d := h.distance(v, h.Nodes[f].Vector)
+			if found.Len() < ef || d < found[0].dist {
+				heap.Push(&toVisit, candidate{node: f, dist: d})
+				heap.Push(&found, candidate{node: f, dist: d})
+				if found.Len() > ef {
+					heap.Pop(&found)
+				}
+			}
+		}
+	}
+
+	result := make([]candidate, len(found))
+	copy(result, found)
+	sortCandidates(result)
+	return result
+}
+
+// Search returns the k vectors closest to the query, nearest first.
+func (h *HNSW) Search(query []float64, k int) ([]Result, error) {
+	return h.SearchEf(query, k, h.EfSearch)
+}
+
+// SearchEf is Search with an explicit candidate list size, a larger ef trades
+// speed for recall.
+func (h *HNSW) SearchEf(query []float64, k, ef int) ([]Result, error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	v, err := h.prepare(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.EntryPoint < 0 || k <= 0 {
+		return nil, nil
+	}
+	if ef < k {
+		ef = k
+	}
+	// ask for more candidates when deleted nodes could push live ones out
+	if h.Deleted > 0 {
+		ef += min(h.Deleted, ef)
+	}
+
+	entry := h.EntryPoint
+	for l := len(h.Nodes[entry].Friends) - 1; l > 0; l-- {
+		entry = h.greedy(v, entry, l)
+	}
+
+	results := make([]Result, 0, k)
+	for _, c := range h.searchLayer(v, []int{entry}, ef, 0) {
+		if h.Nodes[c.node].Deleted {
+			continue
+		}
+		results = append(results, Result{ID: h.Nodes[c.node].ID, Distance: c.dist})
+		if len(results) == k {
+			break
+		}
+	}
+	return results, nil
+}
+
+// Delete removes id from the results of future searches. The node stays in the
+// graph to keep it navigable until the index is rebuilt with Compact.
+func (h *HNSW) Delete(id int) bool {
+	h.writeMu.Lock()
+	defer h.writeMu.Unlock()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	pos, ok := h.Positions[id]
+	if !ok {
+		return false
+	}
+	h.Nodes[pos].Deleted = true
+	h.Deleted++
+	delete(h.Positions, id)
+	return true
+}
+
+/**
+ * Compact rebuilds the graph from the live vectors only, dropping deleted
+ * nodes for good. The new graph is built on the side and swapped in at once,
+ * searches meanwhile run on the old one and writes wait for the swap.
+ */
+func (h *HNSW) Compact() {
+	h.writeMu.Lock()
+	defer h.writeMu.Unlock()
+
+	// writes wait on writeMu, so the graph can be read without mu from here
+	fresh := &HNSW{
+		Metric:         h.Metric,
+		M:              h.M,
+		EfConstruction: h.EfConstruction,
+		Dim:            h.Dim,
+		Positions:      make(map[int]int),
+		EntryPoint:     -1,
+		Seed:           h.Seed,
+	}
+	for _, n := range h.Nodes {
+		if !n.Deleted {
+			// already validated and normalized
+			fresh.insert(n.ID, n.Vector)
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.Nodes = fresh.Nodes
+	h.Positions = fresh.Positions
+	h.EntryPoint = fresh.EntryPoint
+	h.Deleted = 0
+	h.rng = fresh.rng
+}
+
+//save to a file
+func (h *HNSW) Save(file string) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	buf := new(bytes.Buffer)
+	encoder := gob.NewEncoder(buf)
+
+	err := encoder.Encode(h)
+	if err != nil {
+		return fmt.Errorf("error encoding model: %s", err)
+	}
+
+	name, version := h.getMeta()
+	persist.Save(file, persist.Modeldata{
+		Data:    buf.Bytes(),
+		Name:    name,
+		Version: version,
+	})
+	return nil
+}
+
+// Load from the output file.
+func (h *HNSW) Load(filePath string) error {
+	log.Printf("Loading index from %s...", filePath)
+	meta := persist.Load(filePath)
+	//get the index current meta data
+	name, version := h.getMeta()
+	if meta.Name != name {
+		return fmt.Errorf("This file doesn't contain a HNSW index")
+	}
+	if meta.Version != version {
+		return fmt.Errorf("Can't understand this file format")
+	}
+
+	h.writeMu.Lock()
+	defer h.writeMu.Unlock()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// gob leaves out zero values, so decode into a blank index rather than
+	// over the current one where an EntryPoint of 0 would stay at -1.
+	var loaded HNSW
+	decoder := gob.NewDecoder(bytes.NewBuffer(meta.Data))
+	err := decoder.Decode(&loaded)
+	if err != nil {
+		return fmt.Errorf("error decoding index file: %s", err)
+	}
+	h.Metric = loaded.Metric
+	h.M = loaded.M
+	h.EfConstruction = loaded.EfConstruction
+	h.EfSearch = loaded.EfSearch
+	h.Dim = loaded.Dim
+	h.Nodes = loaded.Nodes
+	h.Positions = loaded.Positions
+	h.EntryPoint = loaded.EntryPoint
+	h.Deleted = loaded.Deleted
+	h.Seed = loaded.Seed
+	if h.Positions == nil {
+		h.Positions = make(map[int]int)
+	}
+	h.rng = nil
+	return nil
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
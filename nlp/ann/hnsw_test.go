@@ -0,0 +1,176 @@
+package ann
+
+import (
+	"math/rand"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+)
+
+func randomVectors(r *rand.Rand, n, dim int) [][]float64 {
+	vectors := make([][]float64, n)
+	for i := range vectors {
+		vectors[i] = make([]float64, dim)
+		for d := range vectors[i] {
+			vectors[i][d] = r.NormFloat64()
+		}
+	}
+	return vectors
+}
+
+func exactNearest(h *HNSW, vectors [][]float64, query []float64, k int) map[int]bool {
+	q, _ := h.prepare(query)
+	ids := make([]int, len(vectors))
+	dist := make([]float64, len(vectors))
+	for i, v := range vectors {
+		p, _ := h.prepare(v)
+		ids[i], dist[i] = i, h.distance(q, p)
+	}
+	sort.Slice(ids, func(a, b int) bool { return dist[ids[a]] < dist[ids[b]] })
+	result := make(map[int]bool, k)
+	for _, id := range ids[:k] {
+		result[id] = true
+	}
+	return result
+}
+
+func TestHNSWRecall(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	vectors := randomVectors(r, 3000, 24)
+	queries := randomVectors(r, 50, 24)
+
+	for _, metric := range []MetricType{MT_Cosine, MT_Euclidean, MT_DotProduct} {
+		h := NewHNSW(metric, 16, 100)
+		for i, v := range vectors {
+			if err := h.Insert(i, v); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		hits := 0
+		for _, q := range queries {
+			exact := exactNearest(h, vectors, q, 10)
+			results, err := h.SearchEf(q, 10, 100)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, res := range results {
+				if exact[res.ID] {
+					hits++
+				}
+			}
+		}
+		if recall := float64(hits) / float64(10*len(queries)); recall < 0.9 {
+			t.Errorf("metric %d: recall %.2f is too low", metric, recall)
+		}
+	}
+}
+
+func TestHNSWDeleteAndPersist(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	vectors := randomVectors(r, 500, 8)
+	h := NewHNSW(MT_Euclidean, 8, 64)
+	for i, v := range vectors {
+		h.Insert(i, v)
+	}
+
+	if !h.Delete(42) || h.Delete(42) {
+		t.Fatal("deleting an id should succeed exactly once")
+	}
+	results, _ := h.Search(vectors[42], 5)
+	for _, res := range results {
+		if res.ID == 42 {
+			t.Fatal("a deleted vector was returned")
+		}
+	}
+
+	file := filepath.Join(t.TempDir(), "index.joi")
+	if err := h.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewHNSW(MT_Cosine, 0, 0)
+	if err := loaded.Load(file); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Len() != 499 || loaded.Metric != MT_Euclidean {
+		t.Fatalf("loaded index has %d vectors and metric %d", loaded.Len(), loaded.Metric)
+	}
+	results, _ = loaded.Search(vectors[7], 1)
+	if len(results) != 1 || results[0].ID != 7 {
+		t.Fatalf("expected to find vector 7 itself, got %v", results)
+	}
+
+	loaded.Compact()
+	if loaded.Deleted != 0 || len(loaded.Nodes) != 499 {
+		t.Fatalf("compact left %d nodes, %d deleted", len(loaded.Nodes), loaded.Deleted)
+	}
+	if err := loaded.Insert(1000, []float64{1, 2}); err != errDimension {
+		t.Fatalf("expected a dimension error, got %v", err)
+	}
+}
+
+func TestHNSWConcurrentCompact(t *testing.T) {
+	r := rand.New(rand.NewSource(5))
+	vectors := randomVectors(r, 300, 8)
+	h := NewHNSW(MT_Cosine, 8, 64)
+	for i, v := range vectors[:200] {
+		h.Insert(i, v)
+	}
+	for i := 0; i < 100; i++ {
+		h.Delete(i)
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				// the live vectors never drop below 100, a search must always find them
+				if results, err := h.Search(vectors[100+g], 5); err != nil || len(results) != 5 {
+					t.Errorf("search during compaction got %v, %v", results, err)
+					return
+				}
+			}
+		}(g)
+	}
+	for i, v := range vectors[200:] {
+		h.Insert(200+i, v)
+		if i%25 == 0 {
+			h.Compact()
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	if h.Len() != 200 || h.Deleted != 0 && len(h.Nodes)-h.Deleted != 200 {
+		t.Errorf("%d live vectors, %d nodes, %d deleted", h.Len(), len(h.Nodes), h.Deleted)
+	}
+	for _, id := range []int{150, 250, 299} {
+		if results, _ := h.Search(vectors[id], 1); len(results) != 1 || results[0].ID != id {
+			t.Errorf("vector %d found %v", id, results)
+		}
+	}
+}
+
+func BenchmarkHNSWSearch(b *testing.B) {
+	r := rand.New(rand.NewSource(1))
+	vectors := randomVectors(r, 10000, 128)
+	queries := randomVectors(r, 100, 128)
+	h := NewHNSW(MT_Cosine, 16, 100)
+	for i, v := range vectors {
+		h.Insert(i, v)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.Search(queries[i%len(queries)], 10)
+	}
+}
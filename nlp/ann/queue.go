@@ -0,0 +1,86 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package ann
+
+import "sort"
+
+// candidate is a node met during a graph search together with its distance to the query.
+type candidate struct {
+	node int
+	dist float64
+}
+
+// minCandidates pops the closest candidate first.
+type minCandidates []candidate
+
+func (q minCandidates) Len() int            { return len(q) }
+func (q minCandidates) Less(i, j int) bool  { return q[i].dist < q[j].dist }
+func (q minCandidates) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *minCandidates) Push(x interface{}) { *q = append(*q, x.(candidate)) }
+func (q *minCandidates) Pop() interface{} {
+	old := *q
+	c := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return c
+}
+
+// maxCandidates keeps the farthest candidate on top, so it can be dropped first.
+type maxCandidates []candidate
+
+func (q maxCandidates) Len() int            { return len(q) }
+func (q maxCandidates) Less(i, j int) bool  { return q[i].dist > q[j].dist }
+func (q maxCandidates) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *maxCandidates) Push(x interface{}) { *q = append(*q, x.(candidate)) }
+func (q *maxCandidates) Pop() interface{} {
+	old := *q
+	c := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return c
+}
+
+func sortCandidates(c []candidate) {
+	sort.Slice(c, func(i, j int) bool {
+		return c[i].dist < c[j].dist
+	})
+}
+
+// visitedSet marks the nodes a search has already looked at. Rather than
+// clearing the marks after every search, each search gets a new generation
+// and only marks of the current generation count.
+type visitedSet struct {
+	marks      []uint32
+	generation uint32
+}
+
+func (v *visitedSet) add(node int) {
+	v.marks[node] = v.generation
+}
+
+func (v *visitedSet) has(node int) bool {
+	return v.marks[node] == v.generation
+}
+
+// visitedSet takes a visited set from the pool, sized for the current nodes.
+func (h *HNSW) visitedSet() *visitedSet {
+	v, _ := h.visited.Get().(*visitedSet)
+	if v == nil {
+		v = &visitedSet{}
+	}
+	if len(v.marks) < len(h.Nodes) {
+		v.marks = append(v.marks, make([]uint32, len(h.Nodes)-len(v.marks))...)
+	}
+	v.generation++
+	if v.generation == 0 {
+		// the counter wrapped, old marks could be mistaken for current ones
+		for i := range v.marks {
+			v.marks[i] = 0
+		}
+		v.generation = 1
+	}
+	return v
+}
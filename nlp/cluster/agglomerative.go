@@ -0,0 +1,197 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package cluster
+
+import (
+	"errors"
+	"github.com/broosaction/gotext/utils/sparse"
+	"math"
+	"sort"
+)
+
+type Linkage uint8
+
+const (
+	// the mean distance between the vectors of two clusters
+	LK_Average Linkage = iota
+	// the largest distance between the vectors of two clusters, compact clusters
+	LK_Complete
+	// the smallest distance between the vectors of two clusters, chains of close vectors
+	LK_Single
+)
+
+var (
+	errNoStop = errors.New("set K or Threshold to know when to stop merging")
+)
+
+// Merge is a step of agglomerative clustering, joining the clusters holding vectors A and B.
+type Merge struct {
+	A, B     int
+	Distance float64
+}
+
+/**
+ * Agglomerative Clustering
+ *
+ * Starts with every vector in its own cluster and merges the two closest
+ * clusters, by cosine distance and the Linkage, until K clusters are left,
+ * or, with K 0, until the closest clusters are farther than Threshold. The
+ * merges are found with the nearest neighbour chain, in O(n²) time, but
+ * the distances of every pair are kept, so it suits a few thousand vectors.
+ *
+ * Unlike k-means the result doesn't depend on a seed, and Merges keeps the
+ * whole hierarchy to cut it again at another K.
+ *
+ * @category    Machine Learning
+
+  **usage
+	ag := cluster.NewAgglomerative(10)
+	ag.Linkage = cluster.LK_Complete
+	res, err := ag.Fit(vectors)
+*/
+type Agglomerative struct {
+	// The number of clusters, 0 to stop at Threshold instead.
+	K int
+
+	Linkage Linkage
+
+	// The largest distance of two clusters merged when K is 0.
+	Threshold float64
+
+	// Every merge of the last Fit, closest first, down to a single cluster.
+	Merges []Merge
+}
+
+func NewAgglomerative(k int) *Agglomerative {
+	return &Agglomerative{
+		K:       k,
+		Linkage: LK_Average,
+	}
+}
+
+// Fit clusters the vectors.
+func (ag *Agglomerative) Fit(vectors []sparse.Vector) (Result, error) {
+	k := ag.K
+	if k == 0 && ag.Threshold <= 0 {
+		return Result{}, errNoStop
+	}
+	if k == 0 {
+		k = 1
+	}
+	unit, dim, err := check(vectors, k)
+	if err != nil {
+		return Result{}, err
+	}
+	ag.Merges = ag.merges(unit)
+
+	parent := make([]int, len(unit))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	clusters := len(unit)
+	for _, m := range ag.Merges {
+		if clusters <= k || (ag.K == 0 && m.Distance > ag.Threshold) {
+			break
+		}
+		parent[find(m.B)] = find(m.A)
+		clusters--
+	}
+
+	// number the clusters by their first vector
+	assignments := make([]int, len(unit))
+	label := make(map[int]int)
+	for i := range unit {
+		root := find(i)
+		if _, ok := label[root]; !ok {
+			label[root] = len(label)
+		}
+		assignments[i] = label[root]
+	}
+	return result(unit, assignments, len(label), dim), nil
+}
+
+// merges returns the merges down to one cluster, closest first, by the nearest neighbour chain.
+func (ag *Agglomerative) merges(vectors []sparse.Vector) []Merge {
+	n := len(vectors)
+	distance := make([][]float64, n)
+	for i := range distance {
+		distance[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			d := math.Max(0, 1-vectors[i].DotVector(vectors[j]))
+			distance[i][j], distance[j][i] = d, d
+		}
+	}
+	size := make([]int, n)
+	active := make([]bool, n)
+	for i := range size {
+		size[i], active[i] = 1, true
+	}
+
+	var merges []Merge
+	var chain []int
+	for left := n; left > 1; {
+		if len(chain) == 0 {
+			for i := range active {
+				if active[i] {
+					chain = append(chain, i)
+					break
+				}
+			}
+		}
+		a := chain[len(chain)-1]
+		// the previous cluster of the chain wins ties, so the chain can't loop
+		b, bd := -1, math.Inf(1)
+		if len(chain) > 1 {
+			b = chain[len(chain)-2]
+			bd = distance[a][b]
+		}
+		for c := range active {
+			if active[c] && c != a && distance[a][c] < bd {
+				b, bd = c, distance[a][c]
+			}
+		}
+		if len(chain) < 2 || b != chain[len(chain)-2] {
+			chain = append(chain, b)
+			continue
+		}
+
+		chain = chain[:len(chain)-2]
+		merges = append(merges, Merge{A: a, B: b, Distance: bd})
+		// the union takes the place of a, Lance-Williams updates its distances
+		for c := range active {
+			if !active[c] || c == a || c == b {
+				continue
+			}
+			var d float64
+			switch ag.Linkage {
+			case LK_Complete:
+				d = math.Max(distance[a][c], distance[b][c])
+			case LK_Single:
+				d = math.Min(distance[a][c], distance[b][c])
+			default:
+				d = (float64(size[a])*distance[a][c] + float64(size[b])*distance[b][c]) / float64(size[a]+size[b])
+			}
+			distance[a][c], distance[c][a] = d, d
+		}
+		size[a] += size[b]
+		active[b] = false
+		left--
+	}
+	// these linkages never bring clusters closer by merging, so sorted merges replay in a valid order
+	sort.SliceStable(merges, func(i, j int) bool { return merges[i].Distance < merges[j].Distance })
+	return merges
+}
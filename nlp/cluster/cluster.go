@@ -0,0 +1,135 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+// Package cluster groups texts nobody labeled, such as the TF-IDF vectors
+// of a vectorizer, by cosine similarity. Spherical k-means, its mini-batch
+// variant for large corpora and agglomerative clustering return the
+// cluster of every vector and the centroids, whose heaviest terms describe
+// the clusters, and the silhouette score tells how well a number of
+// clusters fits the data.
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"github.com/broosaction/gotext/utils/sparse"
+	"sort"
+)
+
+var (
+	errNoVectors = errors.New("there are no vectors to cluster")
+	errBadK      = errors.New("the number of clusters must be between 1 and the number of vectors")
+)
+
+// Result is a clustering.
+type Result struct {
+	// The cluster of every vector, from 0 to K-1.
+	Assignments []int
+
+	// The mean direction of the vectors of every cluster, of unit length.
+	Centroids []sparse.Vector
+
+	// The number of vectors of every cluster.
+	Sizes []int
+
+	// The sum of the cosine distances of the vectors to their centroid, the lower the tighter.
+	Inertia float64
+
+	// The rounds run, for the iterative methods.
+	Iterations int
+}
+
+// K is the number of clusters.
+func (r *Result) K() int {
+	return len(r.Centroids)
+}
+
+// Predict returns the cluster whose centroid is the most similar to the vector.
+func (r *Result) Predict(v sparse.Vector) int {
+	return nearest(v.Normalize(), r.Centroids)
+}
+
+// Members returns the positions of the vectors of every cluster.
+func (r *Result) Members() [][]int {
+	members := make([][]int, r.K())
+	for i, c := range r.Assignments {
+		members[c] = append(members[c], i)
+	}
+	return members
+}
+
+// Term is a feature of a centroid and its weight.
+type Term struct {
+	Text   string
+	Weight float64
+}
+
+/**
+ * TopTerms returns the n heaviest terms of every centroid, the names being
+ * the term of every feature, such as CountVectorizer.FeatureNames.
+ */
+func (r *Result) TopTerms(names []string, n int) [][]Term {
+	top := make([][]Term, r.K())
+	for c, centroid := range r.Centroids {
+		terms := make([]Term, 0, centroid.Len())
+		for k, i := range centroid.Indices {
+			if i < len(names) {
+				terms = append(terms, Term{Text: names[i], Weight: centroid.Values[k]})
+			}
+		}
+		sort.Slice(terms, func(a, b int) bool {
+			if terms[a].Weight != terms[b].Weight {
+				return terms[a].Weight > terms[b].Weight
+			}
+			return terms[a].Text < terms[b].Text
+		})
+		if n > 0 && len(terms) > n {
+			terms = terms[:n]
+		}
+		top[c] = terms
+	}
+	return top
+}
+
+// check returns the unit vectors and their dimension, or an error when k can't cluster them.
+func check(vectors []sparse.Vector, k int) ([]sparse.Vector, int, error) {
+	if len(vectors) == 0 {
+		return nil, 0, errNoVectors
+	}
+	if k < 1 || k > len(vectors) {
+		return nil, 0, fmt.Errorf("k = %d for %d vectors: %w", k, len(vectors), errBadK)
+	}
+	unit := make([]sparse.Vector, len(vectors))
+	dim := 0
+	for i, v := range vectors {
+		unit[i] = v.Normalize()
+		if v.MaxIndex()+1 > dim {
+			dim = v.MaxIndex() + 1
+		}
+	}
+	return unit, dim, nil
+}
+
+// result builds the result of the assignments, the centroids being the normalized means of the clusters.
+func result(vectors []sparse.Vector, assignments []int, k, dim int) Result {
+	sums := make([][]float64, k)
+	for c := range sums {
+		sums[c] = make([]float64, dim)
+	}
+	r := Result{Assignments: assignments, Centroids: make([]sparse.Vector, k), Sizes: make([]int, k)}
+	for i, v := range vectors {
+		v.AddTo(sums[assignments[i]], 1)
+		r.Sizes[assignments[i]]++
+	}
+	for c := range sums {
+		r.Centroids[c] = sparse.FromDense(sums[c]).Normalize()
+	}
+	for i, v := range vectors {
+		r.Inertia += 1 - v.DotVector(r.Centroids[assignments[i]])
+	}
+	return r
+}
@@ -0,0 +1,200 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package cluster
+
+import (
+	"errors"
+	"github.com/broosaction/gotext/nlp/vectorizer"
+	"github.com/broosaction/gotext/utils/sparse"
+	stringUtils "github.com/broosaction/gotext/utils/strings"
+	"testing"
+)
+
+var tickets = []string{
+	"cannot login my password is rejected",
+	"login fails after a password reset",
+	"password reset done but login still fails",
+	"login says my password is not accepted",
+	"invoice charged twice please refund",
+	"refund the double charge on my invoice",
+	"wrong charge on the invoice please refund",
+	"charged twice for one invoice need a refund",
+	"app crashes when uploading a photo",
+	"photo upload crashes the app",
+	"app crashes uploading a photo on android",
+	"uploading a photo crashes the app",
+}
+
+// topic is the true group of every ticket.
+func topic(i int) int {
+	return i / 4
+}
+
+func vectors(t *testing.T) ([]sparse.Vector, *vectorizer.TfidfVectorizer) {
+	tv := vectorizer.NewTfidfVectorizer()
+	tv.Counts.StopWords = stringUtils.GetStopwords()
+	vs, err := tv.FitTransform(tickets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vs, tv
+}
+
+// pure tells whether every cluster holds the tickets of one topic and every topic is in one cluster.
+func pure(assignments []int) bool {
+	clusterOf := make(map[int]int)
+	topicOf := make(map[int]int)
+	for i, c := range assignments {
+		if tc, ok := clusterOf[topic(i)]; ok && tc != c {
+			return false
+		}
+		if ct, ok := topicOf[c]; ok && ct != topic(i) {
+			return false
+		}
+		clusterOf[topic(i)], topicOf[c] = c, topic(i)
+	}
+	return true
+}
+
+func TestKMeans(t *testing.T) {
+	vs, tv := vectors(t)
+	km := NewKMeans(3)
+	km.Restarts = 5
+	res, err := km.Fit(vs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pure(res.Assignments) {
+		t.Errorf("assignments = %v", res.Assignments)
+	}
+	if res.K() != 3 || res.Sizes[0]+res.Sizes[1]+res.Sizes[2] != len(tickets) {
+		t.Errorf("sizes = %v", res.Sizes)
+	}
+	for c, centroid := range res.Centroids {
+		if n := centroid.Norm(); n < 0.999 || n > 1.001 {
+			t.Errorf("centroid %d has norm %f", c, n)
+		}
+	}
+	if c := res.Predict(tv.Transform("password login problem")); c != res.Assignments[0] {
+		t.Errorf("predicted cluster %d, want %d", c, res.Assignments[0])
+	}
+
+	top := res.TopTerms(tv.Counts.FeatureNames(), 3)
+	want := map[int]string{0: "password", 4: "invoice", 8: "app"}
+	for i, term := range want {
+		found := false
+		for _, tm := range top[res.Assignments[i]] {
+			found = found || tm.Text == term
+		}
+		if !found {
+			t.Errorf("top terms of cluster %d = %v, want %q among them", res.Assignments[i], top[res.Assignments[i]], term)
+		}
+	}
+	if len(res.Members()[res.Assignments[0]]) != 4 {
+		t.Errorf("members = %v", res.Members())
+	}
+
+	if _, err := NewKMeans(13).Fit(vs); !errors.Is(err, errBadK) {
+		t.Errorf("k above the vectors gave %v", err)
+	}
+	if _, err := NewKMeans(2).Fit(nil); !errors.Is(err, errNoVectors) {
+		t.Errorf("no vectors gave %v", err)
+	}
+}
+
+func TestMiniBatchKMeans(t *testing.T) {
+	vs, _ := vectors(t)
+	mb := NewMiniBatchKMeans(3)
+	mb.BatchSize = 6
+	mb.MaxIterations = 50
+	mb.Seed = 3
+	res, err := mb.Fit(vs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, size := range res.Sizes {
+		if size == 0 {
+			t.Errorf("empty cluster: %v", res.Sizes)
+		}
+	}
+	km := NewKMeans(3)
+	km.Restarts = 5
+	full, _ := km.Fit(vs)
+	if res.Inertia > 1.5*full.Inertia {
+		t.Errorf("mini-batch inertia %f, k-means %f", res.Inertia, full.Inertia)
+	}
+}
+
+func TestAgglomerative(t *testing.T) {
+	vs, _ := vectors(t)
+	for _, linkage := range []Linkage{LK_Average, LK_Complete} {
+		ag := NewAgglomerative(3)
+		ag.Linkage = linkage
+		res, err := ag.Fit(vs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !pure(res.Assignments) {
+			t.Errorf("linkage %d: assignments = %v", linkage, res.Assignments)
+		}
+		if len(ag.Merges) != len(tickets)-1 {
+			t.Errorf("%d merges", len(ag.Merges))
+		}
+		for i := 1; i < len(ag.Merges); i++ {
+			if ag.Merges[i].Distance < ag.Merges[i-1].Distance {
+				t.Errorf("merges not sorted: %v", ag.Merges)
+			}
+		}
+	}
+
+	ag := NewAgglomerative(0)
+	if _, err := ag.Fit(vs); !errors.Is(err, errNoStop) {
+		t.Errorf("no K nor Threshold gave %v", err)
+	}
+	// tickets 8 and 11 have the same words
+	ag.Threshold = 0.0001
+	if res, _ := ag.Fit(vs); res.K() != len(tickets)-1 {
+		t.Errorf("a tiny threshold gave %d clusters", res.K())
+	}
+	ag.Threshold = 2
+	if res, _ := ag.Fit(vs); res.K() != 1 {
+		t.Errorf("the largest threshold gave %d clusters", res.K())
+	}
+}
+
+func TestSilhouette(t *testing.T) {
+	vs, _ := vectors(t)
+	truth := make([]int, len(tickets))
+	for i := range truth {
+		truth[i] = topic(i)
+	}
+	shuffled := make([]int, len(tickets))
+	for i := range shuffled {
+		shuffled[i] = i % 3
+	}
+	good, bad := Silhouette(vs, truth), Silhouette(vs, shuffled)
+	if good <= 0 || good <= bad {
+		t.Errorf("true topics score %f, shuffled %f", good, bad)
+	}
+	if s := Silhouette(vs, make([]int, len(tickets))); s != 0 {
+		t.Errorf("a single cluster scores %f", s)
+	}
+
+	best, bestScore := 0, -2.0
+	for k := 2; k <= 5; k++ {
+		km := NewKMeans(k)
+		km.Restarts = 5
+		res, _ := km.Fit(vs)
+		if s := Silhouette(vs, res.Assignments); s > bestScore {
+			best, bestScore = k, s
+		}
+	}
+	if best != 3 {
+		t.Errorf("silhouette chose k = %d", best)
+	}
+}
@@ -0,0 +1,194 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package cluster
+
+import (
+	"github.com/broosaction/gotext/utils/sparse"
+	"math/rand"
+)
+
+/**
+ * Spherical K-Means
+ *
+ * K-means on the unit sphere: every vector goes to the centroid it is the
+ * most similar to by cosine, and every centroid becomes the normalized sum
+ * of its vectors, until no vector moves or the inertia stops falling. The
+ * first centroid is a random vector, the next ones are drawn k-means++
+ * style, with a chance growing with the distance to the centroids chosen.
+ * A cluster left empty takes the vector farthest from its centroid.
+ *
+ * K-means finds a local optimum only, Restarts runs it from several seeds
+ * and keeps the tightest clustering.
+ *
+ * @category    Machine Learning
+
+  **usage
+	tv := vectorizer.NewTfidfVectorizer()
+	vectors, _ := tv.FitTransform(tickets)
+	res, err := cluster.NewKMeans(8).Fit(vectors)
+	for c, terms := range res.TopTerms(tv.Counts.FeatureNames(), 5) {
+		fmt.Println(c, res.Sizes[c], terms)
+	}
+*/
+type KMeans struct {
+	// The number of clusters.
+	K int
+
+	// The most rounds of a run, 100 by default.
+	MaxIterations int
+
+	// A run stops once the inertia falls by less than this share, 1e-4 by default.
+	Tolerance float64
+
+	// The number of runs from different seeds, 1 by default.
+	Restarts int
+
+	Seed int64
+}
+
+func NewKMeans(k int) *KMeans {
+	return &KMeans{
+		K:             k,
+		MaxIterations: 100,
+		Tolerance:     1e-4,
+		Restarts:      1,
+	}
+}
+
+// Fit clusters the vectors into K clusters.
+func (km *KMeans) Fit(vectors []sparse.Vector) (Result, error) {
+	unit, dim, err := check(vectors, km.K)
+	if err != nil {
+		return Result{}, err
+	}
+	restarts := km.Restarts
+	if restarts < 1 {
+		restarts = 1
+	}
+	rng := rand.New(rand.NewSource(km.Seed))
+	var best Result
+	for run := 0; run < restarts; run++ {
+		r := km.run(unit, dim, rng)
+		if run == 0 || r.Inertia < best.Inertia {
+			best = r
+		}
+	}
+	return best, nil
+}
+
+func (km *KMeans) run(vectors []sparse.Vector, dim int, rng *rand.Rand) Result {
+	maxIterations, tolerance := km.MaxIterations, km.Tolerance
+	if maxIterations <= 0 {
+		maxIterations = 100
+	}
+	if tolerance <= 0 {
+		tolerance = 1e-4
+	}
+
+	centroids := seeds(vectors, km.K, rng)
+	assignments := make([]int, len(vectors))
+	var r Result
+	last := -1.0
+	for iteration := 1; iteration <= maxIterations; iteration++ {
+		changed := false
+		for i, v := range vectors {
+			best := nearest(v, centroids)
+			if iteration == 1 || assignments[i] != best {
+				assignments[i] = best
+				changed = true
+			}
+		}
+		fillEmpty(vectors, assignments, centroids, km.K)
+		r = result(vectors, assignments, km.K, dim)
+		r.Iterations = iteration
+		for c := range centroids {
+			centroids[c] = r.Centroids[c]
+		}
+		if !changed || (last >= 0 && last-r.Inertia <= tolerance*last) {
+			break
+		}
+		last = r.Inertia
+	}
+	return r
+}
+
+/**
+ * seeds picks k centroids k-means++ style: a random vector, then vectors
+ * drawn with a chance proportional to their cosine distance to the
+ * nearest centroid chosen.
+ */
+func seeds(vectors []sparse.Vector, k int, rng *rand.Rand) []sparse.Vector {
+	centroids := make([]sparse.Vector, 0, k)
+	centroids = append(centroids, vectors[rng.Intn(len(vectors))])
+	distance := make([]float64, len(vectors))
+	for i, v := range vectors {
+		distance[i] = 1 - v.DotVector(centroids[0])
+	}
+	for len(centroids) < k {
+		var sum float64
+		for _, d := range distance {
+			sum += d
+		}
+		next := rng.Intn(len(vectors))
+		if sum > 0 {
+			r := rng.Float64() * sum
+			for i, d := range distance {
+				if r -= d; r < 0 {
+					next = i
+					break
+				}
+			}
+		}
+		centroids = append(centroids, vectors[next])
+		for i, v := range vectors {
+			if d := 1 - v.DotVector(vectors[next]); d < distance[i] {
+				distance[i] = d
+			}
+		}
+	}
+	return centroids
+}
+
+// nearest returns the centroid the most similar to the unit vector.
+func nearest(v sparse.Vector, centroids []sparse.Vector) int {
+	best, bestSimilarity := 0, -2.0
+	for c, centroid := range centroids {
+		if s := v.DotVector(centroid); s > bestSimilarity {
+			best, bestSimilarity = c, s
+		}
+	}
+	return best
+}
+
+// fillEmpty moves the vector farthest from its centroid into every empty cluster.
+func fillEmpty(vectors []sparse.Vector, assignments []int, centroids []sparse.Vector, k int) {
+	sizes := make([]int, k)
+	for _, c := range assignments {
+		sizes[c]++
+	}
+	for c := 0; c < k; c++ {
+		if sizes[c] > 0 {
+			continue
+		}
+		farthest, distance := -1, -1.0
+		for i, v := range vectors {
+			if sizes[assignments[i]] < 2 {
+				continue
+			}
+			if d := 1 - v.DotVector(centroids[assignments[i]]); d > distance {
+				farthest, distance = i, d
+			}
+		}
+		if farthest < 0 {
+			return
+		}
+		sizes[assignments[farthest]]--
+		assignments[farthest] = c
+		sizes[c]++
+	}
+}
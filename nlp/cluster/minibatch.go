@@ -0,0 +1,109 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package cluster
+
+import (
+	"github.com/broosaction/gotext/utils/sparse"
+	"math/rand"
+)
+
+/**
+ * Mini-Batch K-Means (Sculley, 2010)
+ *
+ * Spherical k-means for corpora too large to go over every round. Every
+ * round draws BatchSize vectors, assigns them to their nearest centroid
+ * and moves each centroid towards its vectors by a step of one over the
+ * number of vectors it has been given so far, so centroids settle as they
+ * grow. After the last round every vector is assigned once. The clusters
+ * come close to those of KMeans for a fraction of the work.
+ *
+ * @category    Machine Learning
+
+  **usage
+	mb := cluster.NewMiniBatchKMeans(50)
+	mb.BatchSize = 1000
+	res, err := mb.Fit(vectors)
+*/
+type MiniBatchKMeans struct {
+	// The number of clusters.
+	K int
+
+	// The vectors drawn every round, 100 by default.
+	BatchSize int
+
+	// The number of rounds, 100 by default.
+	MaxIterations int
+
+	Seed int64
+}
+
+func NewMiniBatchKMeans(k int) *MiniBatchKMeans {
+	return &MiniBatchKMeans{
+		K:             k,
+		BatchSize:     100,
+		MaxIterations: 100,
+	}
+}
+
+// Fit clusters the vectors into K clusters.
+func (mb *MiniBatchKMeans) Fit(vectors []sparse.Vector) (Result, error) {
+	unit, dim, err := check(vectors, mb.K)
+	if err != nil {
+		return Result{}, err
+	}
+	batchSize, maxIterations := mb.BatchSize, mb.MaxIterations
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	if maxIterations <= 0 {
+		maxIterations = 100
+	}
+	rng := rand.New(rand.NewSource(mb.Seed))
+
+	sparseCentroids := seeds(unit, mb.K, rng)
+	centroids := make([][]float64, mb.K)
+	for c, centroid := range sparseCentroids {
+		centroids[c] = centroid.Dense(dim)
+	}
+	counts := make([]int, mb.K)
+	batch := make([]int, batchSize)
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		for b := range batch {
+			batch[b] = rng.Intn(len(unit))
+		}
+		// assign the whole batch before moving the centroids
+		nearestOf := make([]int, batchSize)
+		for b, i := range batch {
+			nearestOf[b] = nearest(unit[i], sparseCentroids)
+		}
+		moved := make(map[int]bool)
+		for b, i := range batch {
+			c := nearestOf[b]
+			counts[c]++
+			step := 1 / float64(counts[c])
+			for d := range centroids[c] {
+				centroids[c][d] *= 1 - step
+			}
+			unit[i].AddTo(centroids[c], step)
+			moved[c] = true
+		}
+		for c := range moved {
+			sparseCentroids[c] = sparse.FromDense(centroids[c]).Normalize()
+			centroids[c] = sparseCentroids[c].Dense(dim)
+		}
+	}
+
+	assignments := make([]int, len(unit))
+	for i, v := range unit {
+		assignments[i] = nearest(v, sparseCentroids)
+	}
+	fillEmpty(unit, assignments, sparseCentroids, mb.K)
+	r := result(unit, assignments, mb.K, dim)
+	r.Iterations = maxIterations
+	return r, nil
+}
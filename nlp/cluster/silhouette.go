@@ -0,0 +1,98 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package cluster
+
+import (
+	"github.com/broosaction/gotext/utils/sparse"
+	"math"
+)
+
+/**
+ * Silhouette returns the mean silhouette of the vectors, from -1 to 1, by
+ * cosine distance. A vector scores how much closer it is to its own
+ * cluster than to the next closest one,
+ *
+ *	s(i) = (b(i) - a(i)) / max(a(i), b(i))
+ *
+ * a(i) being its mean distance to the rest of its cluster and b(i) to the
+ * vectors of the nearest other cluster, 0 alone in its cluster. Run a
+ * clustering for several K and keep the K of the highest silhouette.
+ * It compares every pair of vectors, sample large corpora.
+ *
+ *	for k := 2; k <= 20; k++ {
+ *		res, _ := cluster.NewKMeans(k).Fit(vectors)
+ *		fmt.Println(k, cluster.Silhouette(vectors, res.Assignments))
+ *	}
+ */
+func Silhouette(vectors []sparse.Vector, assignments []int) float64 {
+	samples := SilhouetteSamples(vectors, assignments)
+	if len(samples) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, s := range samples {
+		sum += s
+	}
+	return sum / float64(len(samples))
+}
+
+// SilhouetteSamples returns the silhouette of every vector, 0 for all with fewer than two clusters.
+func SilhouetteSamples(vectors []sparse.Vector, assignments []int) []float64 {
+	k := 0
+	for _, c := range assignments {
+		if c+1 > k {
+			k = c + 1
+		}
+	}
+	unit := make([]sparse.Vector, len(vectors))
+	sizes := make([]int, k)
+	for i, v := range vectors {
+		unit[i] = v.Normalize()
+		sizes[assignments[i]]++
+	}
+	samples := make([]float64, len(vectors))
+	clusters := 0
+	for _, size := range sizes {
+		if size > 0 {
+			clusters++
+		}
+	}
+	if clusters < 2 {
+		return samples
+	}
+
+	sums := make([]float64, k)
+	for i, v := range unit {
+		for c := range sums {
+			sums[c] = 0
+		}
+		for j, u := range unit {
+			if i != j {
+				sums[assignments[j]] += 1 - v.DotVector(u)
+			}
+		}
+		own := assignments[i]
+		if sizes[own] < 2 {
+			continue
+		}
+		a := sums[own] / float64(sizes[own]-1)
+		b := -1.0
+		for c, sum := range sums {
+			if c == own || sizes[c] == 0 {
+				continue
+			}
+			if d := sum / float64(sizes[c]); b < 0 || d < b {
+				b = d
+			}
+		}
+		if max := math.Max(a, b); max > 0 {
+			samples[i] = (b - a) / max
+		}
+	}
+	return samples
+}
@@ -0,0 +1,348 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+// Package embeddings gives words dense vectors, close for words used in the
+// same contexts. Vectors are trained on a local corpus with skip-gram or
+// CBOW and negative sampling, optionally with the character n-grams of
+// fastText so words never seen still get one, or loaded pre-trained from
+// the word2vec text and binary formats and the GloVe format. The vectors
+// answer nearest neighbours and analogies, and a SentenceEncoder pools them
+// into vectors of sentences and documents, weighted by smooth inverse
+// frequency, to match texts by meaning.
+package embeddings
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"github.com/broosaction/gotext/tokenizers"
+	"github.com/broosaction/gotext/utils/persist"
+	"log"
+	"math"
+	"sort"
+	"strings"
+)
+
+var (
+	errDimension = errors.New("the vector dimension doesn't match the embeddings")
+	errEmptyWord = errors.New("a word can't be empty")
+)
+
+// Neighbor is a word and its cosine similarity to a query.
+type Neighbor struct {
+	Word       string
+	Similarity float64
+}
+
+/**
+ * Embeddings
+ *
+ * The vector of every word of a vocabulary, all of the same dimension.
+ * Words are looked up as they are, then lower cased, so vectors trained or
+ * published lower cased still match "Paris". Embeddings trained with
+ * subwords also give a vector to unknown words, from their character
+ * n-grams.
+ *
+ * @category    Machine Learning
+
+  **usage
+	emb, err := embeddings.LoadFile("glove.6B.100d.txt", embeddings.FM_GloVe)
+	fmt.Println(emb.Nearest("invoice", 5))
+	fmt.Println(emb.Analogy("man", "king", "woman", 1)) // queen
+	v := emb.SentenceVector("where is my refund")
+*/
+type Embeddings struct {
+	// The dimension of the vectors.
+	Dim int
+
+	// The vocabulary, by index, and the index of every word.
+	Words []string
+	Index map[string]int
+
+	Vectors [][]float32
+
+	// The character n-gram vectors for unknown words, nil without subwords.
+	Subwords *Subwords
+
+	// name of the tokenizer of SentenceVector, see tokenizers.GetTokenizer
+	Tokenizer string
+}
+
+func NewEmbeddings(dim int) *Embeddings {
+	return &Embeddings{
+		Dim:       dim,
+		Index:     make(map[string]int),
+		Tokenizer: tokenizers.DefaultTokenizerName,
+	}
+}
+
+func (e *Embeddings) getMeta() (string, string) {
+	return "Embeddings", "01"
+}
+
+// Len is the size of the vocabulary.
+func (e *Embeddings) Len() int {
+	return len(e.Words)
+}
+
+// Add sets the vector of the word, the first sets the dimension of empty embeddings.
+func (e *Embeddings) Add(word string, vector []float32) error {
+	if word == "" {
+		return errEmptyWord
+	}
+	if e.Dim == 0 && e.Len() == 0 {
+		e.Dim = len(vector)
+	}
+	if len(vector) != e.Dim {
+		return fmt.Errorf("%q has %d values, not %d: %w", word, len(vector), e.Dim, errDimension)
+	}
+	if e.Index == nil {
+		e.Index = make(map[string]int)
+	}
+	if i, ok := e.Index[word]; ok {
+		e.Vectors[i] = vector
+		return nil
+	}
+	e.Index[word] = len(e.Words)
+	e.Words = append(e.Words, word)
+	e.Vectors = append(e.Vectors, vector)
+	return nil
+}
+
+// lookup returns the index of the word, or of its lower case.
+func (e *Embeddings) lookup(word string) (int, bool) {
+	if i, ok := e.Index[word]; ok {
+		return i, true
+	}
+	i, ok := e.Index[strings.ToLower(word)]
+	return i, ok
+}
+
+// Has tells whether the word is in the vocabulary.
+func (e *Embeddings) Has(word string) bool {
+	_, ok := e.lookup(word)
+	return ok
+}
+
+/**
+ * Vector returns the vector of the word, built from its character n-grams
+ * when it is not in the vocabulary and the embeddings have subwords, false
+ * when there is none. The vector is shared, copy it before changing it.
+ */
+func (e *Embeddings) Vector(word string) ([]float32, bool) {
+	if i, ok := e.lookup(word); ok {
+		return e.Vectors[i], true
+	}
+	if e.Subwords != nil {
+		if v := e.Subwords.Vector(strings.ToLower(word), e.Dim); v != nil {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// Similarity returns the cosine similarity of the vectors of two words, 0 when one has none.
+func (e *Embeddings) Similarity(a, b string) float64 {
+	va, ok := e.Vector(a)
+	if !ok {
+		return 0
+	}
+	vb, ok := e.Vector(b)
+	if !ok {
+		return 0
+	}
+	return Cosine(va, vb)
+}
+
+// Nearest returns the n words of the vocabulary closest to the word, but itself, nil when it has no vector.
+func (e *Embeddings) Nearest(word string, n int) []Neighbor {
+	v, ok := e.Vector(word)
+	if !ok {
+		return nil
+	}
+	return e.NearestVector(v, n, word, strings.ToLower(word))
+}
+
+/**
+ * NearestVector returns the n words of the vocabulary with the highest
+ * cosine similarity to the vector, most similar first, leaving out the
+ * words excluded. It compares to every word, index the vectors in an
+ * ann.HNSW for large vocabularies queried often.
+ */
+func (e *Embeddings) NearestVector(vector []float32, n int, exclude ...string) []Neighbor {
+	if n <= 0 || len(vector) != e.Dim {
+		return nil
+	}
+	skip := make(map[string]bool, len(exclude))
+	for _, w := range exclude {
+		skip[w] = true
+	}
+	norm := Norm(vector)
+	if norm == 0 {
+		return nil
+	}
+	// the best n so far, most similar first
+	best := make([]Neighbor, 0, n+1)
+	for i, v := range e.Vectors {
+		if skip[e.Words[i]] {
+			continue
+		}
+		vn := Norm(v)
+		if vn == 0 {
+			continue
+		}
+		s := dot(vector, v) / (norm * vn)
+		if len(best) == n && s <= best[n-1].Similarity {
+			continue
+		}
+		at := sort.Search(len(best), func(j int) bool { return best[j].Similarity < s })
+		best = append(best, Neighbor{})
+		copy(best[at+1:], best[at:])
+		best[at] = Neighbor{Word: e.Words[i], Similarity: s}
+		if len(best) > n {
+			best = best[:n]
+		}
+	}
+	return best
+}
+
+/**
+ * Analogy completes "a is to b as c is to ?", returning the n words closest
+ * to b - a + c over the unit vectors (3CosAdd), a, b and c left out. Analogy
+ * ("man", "king", "woman", 1) gives queen with good vectors. Nil when a word
+ * has no vector.
+ */
+func (e *Embeddings) Analogy(a, b, c string, n int) []Neighbor {
+	target := make([]float32, e.Dim)
+	for _, term := range []struct {
+		word string
+		sign float64
+	}{{a, -1}, {b, 1}, {c, 1}} {
+		v, ok := e.Vector(term.word)
+		if !ok {
+			return nil
+		}
+		norm := Norm(v)
+		if norm == 0 {
+			return nil
+		}
+		for i, x := range v {
+			target[i] += float32(term.sign * float64(x) / norm)
+		}
+	}
+	var exclude []string
+	for _, w := range []string{a, b, c} {
+		exclude = append(exclude, w, strings.ToLower(w))
+	}
+	return e.NearestVector(target, n, exclude...)
+}
+
+// Average returns the mean vector of the words having one, nil when none has.
+func (e *Embeddings) Average(words []string) []float32 {
+	var mean []float32
+	count := 0
+	for _, w := range words {
+		v, ok := e.Vector(w)
+		if !ok {
+			continue
+		}
+		if mean == nil {
+			mean = make([]float32, e.Dim)
+		}
+		for i, x := range v {
+			mean[i] += x
+		}
+		count++
+	}
+	for i := range mean {
+		mean[i] /= float32(count)
+	}
+	return mean
+}
+
+// Tokens splits the text into words with the Tokenizer.
+func (e *Embeddings) Tokens(text string) []string {
+	name := e.Tokenizer
+	if name == "" {
+		name = tokenizers.DefaultTokenizerName
+	}
+	return tokenizers.GetTokenizer(name).Tokenize(text)
+}
+
+// SentenceVector returns the mean vector of the words of the text, nil when none has one.
+func (e *Embeddings) SentenceVector(text string) []float32 {
+	return e.Average(e.Tokens(text))
+}
+
+//save to a file
+func (e *Embeddings) Save(file string) error {
+	name, version := e.getMeta()
+	buf := new(bytes.Buffer)
+	encoder := gob.NewEncoder(buf)
+
+	err := encoder.Encode(e)
+	if err != nil {
+		return fmt.Errorf("error encoding model: %s", err)
+	}
+
+	persist.Save(file, persist.Modeldata{
+		Data:    buf.Bytes(),
+		Name:    name,
+		Version: version,
+	})
+	return nil
+}
+
+// Load from the output file.
+func (e *Embeddings) Load(filePath string) error {
+	log.Printf("Loading Embeddings from %s...", filePath)
+	meta := persist.Load(filePath)
+	//get the model metadata
+	name, version := e.getMeta()
+	if meta.Name != name {
+		return fmt.Errorf("This file doesn't contain Embeddings")
+	}
+	if meta.Version != version {
+		return fmt.Errorf("Can't understand this file format")
+	}
+
+	// decode into a fresh one, gob leaves the fields saved as zero values untouched
+	var loaded Embeddings
+	decoder := gob.NewDecoder(bytes.NewBuffer(meta.Data))
+	err := decoder.Decode(&loaded)
+	if err != nil {
+		return fmt.Errorf("error decoding checkpoint file: %s", err)
+	}
+	if loaded.Index == nil {
+		loaded.Index = make(map[string]int)
+	}
+	*e = loaded
+	return nil
+}
+
+// Cosine returns the cosine similarity of two vectors of the same dimension, 0 when one is zero.
+func Cosine(a, b []float32) float64 {
+	na, nb := Norm(a), Norm(b)
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot(a, b) / (na * nb)
+}
+
+// Norm is the euclidean length of the vector.
+func Norm(v []float32) float64 {
+	return math.Sqrt(dot(v, v))
+}
+
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i, x := range a {
+		sum += float64(x) * float64(b[i])
+	}
+	return sum
+}
@@ -0,0 +1,401 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package embeddings
+
+import (
+	"bytes"
+	"errors"
+	"github.com/broosaction/gotext/utils/types"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var _ types.Embedding = (*Embeddings)(nil)
+
+var (
+	animals = []string{"cat", "dog", "horse", "cow", "goat"}
+	fruits  = []string{"apple", "banana", "orange", "pear", "mango"}
+)
+
+// corpus has animals and fruits in contexts of their own.
+func corpus() []string {
+	rng := rand.New(rand.NewSource(1))
+	pick := func(words []string) string { return words[rng.Intn(len(words))] }
+	var texts []string
+	for i := 0; i < 300; i++ {
+		texts = append(texts,
+			"the "+pick(animals)+" runs in the field and sleeps in the barn",
+			"i peel a ripe "+pick(fruits)+" and eat it sweet and juicy",
+			"the farmer feeds the "+pick(animals)+" and the "+pick(animals),
+			"a fresh "+pick(fruits)+" juice with a slice of "+pick(fruits))
+	}
+	return texts
+}
+
+func small() *Trainer {
+	tr := NewTrainer()
+	tr.Dim = 20
+	tr.Window = 3
+	tr.MinCount = 1
+	tr.Epochs = 3
+	tr.Sample = 0
+	tr.Seed = 1
+	return tr
+}
+
+func separates(t *testing.T, e *Embeddings) {
+	t.Helper()
+	if s, d := e.Similarity("cat", "dog"), e.Similarity("cat", "apple"); s <= d {
+		t.Errorf("cat ~ dog %f, cat ~ apple %f", s, d)
+	}
+	if s, d := e.Similarity("banana", "pear"), e.Similarity("banana", "horse"); s <= d {
+		t.Errorf("banana ~ pear %f, banana ~ horse %f", s, d)
+	}
+	for _, n := range e.Nearest("goat", 3) {
+		if !contains(animals, n.Word) {
+			t.Errorf("neighbours of goat = %v", e.Nearest("goat", 3))
+		}
+	}
+}
+
+func contains(words []string, word string) bool {
+	for _, w := range words {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSkipGram(t *testing.T) {
+	e, err := small().Train(corpus())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Dim != 20 || !e.Has("barn") || e.Has("zebra") {
+		t.Errorf("dim %d, %d words", e.Dim, e.Len())
+	}
+	separates(t, e)
+
+	tr := small()
+	tr.MinCount = 100000
+	if _, err := tr.Train(corpus()); !errors.Is(err, errEmptyVocabulary) {
+		t.Errorf("too high a MinCount gave %v", err)
+	}
+}
+
+func TestCBOW(t *testing.T) {
+	tr := small()
+	tr.Model = TM_CBOW
+	tr.Alpha = 0.05
+	e, err := tr.Train(corpus())
+	if err != nil {
+		t.Fatal(err)
+	}
+	separates(t, e)
+}
+
+func TestSubwords(t *testing.T) {
+	tr := small()
+	tr.MinN, tr.MaxN = 3, 5
+	tr.Buckets = 10000
+	e, err := tr.Train(corpus())
+	if err != nil {
+		t.Fatal(err)
+	}
+	separates(t, e)
+	v, ok := e.Vector("bananas")
+	if !ok || len(v) != 20 {
+		t.Fatalf("no vector for an unknown word")
+	}
+	if s, d := e.Similarity("bananas", "banana"), e.Similarity("bananas", "horse"); s <= d {
+		t.Errorf("bananas ~ banana %f, bananas ~ horse %f", s, d)
+	}
+	if _, ok := e.Vector("zzz"); ok {
+		t.Errorf("a word without known n-grams has a vector")
+	}
+	if ids := (&Subwords{MinN: 3, MaxN: 3, Buckets: 100}).ids("where"); len(ids) != 5 {
+		t.Errorf("%d trigrams of <where>", len(ids))
+	}
+}
+
+// toy has a gender and a royalty axis.
+func toy(t *testing.T) *Embeddings {
+	e := NewEmbeddings(0)
+	for word, v := range map[string][]float32{
+		"king":  {1, 1, 0.1},
+		"queen": {1, -1, 0.1},
+		"man":   {0.1, 1, 0},
+		"woman": {0.1, -1, 0},
+		"apple": {0, 0, 1},
+	} {
+		if err := e.Add(word, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return e
+}
+
+func TestAnalogyAndNearest(t *testing.T) {
+	e := toy(t)
+	if e.Dim != 3 {
+		t.Errorf("dim %d", e.Dim)
+	}
+	if got := e.Analogy("man", "king", "woman", 1); len(got) != 1 || got[0].Word != "queen" {
+		t.Errorf("man : king :: woman : %v", got)
+	}
+	if got := e.Analogy("man", "king", "unicorn", 1); got != nil {
+		t.Errorf("analogy with an unknown word %v", got)
+	}
+	near := e.Nearest("King", 4)
+	if len(near) != 4 || near[0].Word != "man" || contains([]string{near[0].Word, near[1].Word, near[2].Word, near[3].Word}, "king") {
+		t.Errorf("neighbours of King = %v", near)
+	}
+	for i := 1; i < len(near); i++ {
+		if near[i].Similarity > near[i-1].Similarity {
+			t.Errorf("neighbours not sorted: %v", near)
+		}
+	}
+	if err := e.Add("pear", []float32{1, 2}); !errors.Is(err, errDimension) {
+		t.Errorf("a vector of the wrong dimension gave %v", err)
+	}
+
+	avg := e.SentenceVector("The king and the QUEEN")
+	if len(avg) != 3 || avg[0] != 1 || avg[1] != 0 {
+		t.Errorf("sentence vector %v", avg)
+	}
+	if v := e.SentenceVector("nothing known"); v != nil {
+		t.Errorf("sentence vector without known words %v", v)
+	}
+
+	w := types.NewWord("Queen")
+	w.PrepareVector(e)
+	if len(w.Vector) != 3 || w.Vector[1] != -1 {
+		t.Errorf("word vector %v", w.Vector)
+	}
+}
+
+func TestFormats(t *testing.T) {
+	e := toy(t)
+	e.Add("new york", []float32{0.5, 0.25, -0.125})
+	for _, format := range []Format{FM_Word2VecText, FM_GloVe} {
+		var buf bytes.Buffer
+		if err := e.Write(&buf, format); err != nil {
+			t.Fatal(err)
+		}
+		read, err := Read(&buf, format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		same(t, e, read)
+	}
+
+	delete(e.Index, "new york")
+	e.Words, e.Vectors = e.Words[:5], e.Vectors[:5]
+	file := filepath.Join(t.TempDir(), "toy.bin")
+	if err := e.SaveFile(file, FM_Word2VecBinary); err != nil {
+		t.Fatal(err)
+	}
+	read, err := LoadFile(file, FM_Word2VecBinary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	same(t, e, read)
+
+	glove := "the 0.1 0.2\ncat 0.3 -0.4\n\nthe 9 9\n"
+	read, err = Read(strings.NewReader(glove), FM_GloVe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := read.Vector("the"); read.Len() != 2 || read.Dim != 2 || v[1] != 0.2 {
+		t.Errorf("glove read %d words of %d values, the = %v", read.Len(), read.Dim, v)
+	}
+	if _, err := Read(strings.NewReader("2 3\ncat 0.1 0.2\n"), FM_Word2VecText); !errors.Is(err, errFormat) {
+		t.Errorf("a short line gave %v", err)
+	}
+	if _, err := Read(strings.NewReader("cat 0.1 0.2\n"), FM_Word2VecText); !errors.Is(err, errFormat) {
+		t.Errorf("no header gave %v", err)
+	}
+	if _, err := Read(strings.NewReader("2 2\ncat "), FM_Word2VecBinary); !errors.Is(err, errFormat) {
+		t.Errorf("a truncated binary file gave %v", err)
+	}
+
+	// a header can't make the reader allocate what it claims
+	huge := "9000000000000000 2\ncat 0.1 0.2\n"
+	if read, err := Read(strings.NewReader(huge), FM_Word2VecText); err != nil || read.Len() != 1 || cap(read.Vectors) > maxPrealloc {
+		t.Errorf("a lying header gave %v", err)
+	}
+	if _, err := Read(strings.NewReader(huge), FM_Word2VecBinary); !errors.Is(err, errFormat) {
+		t.Errorf("a lying binary header gave %v", err)
+	}
+	if _, err := Read(strings.NewReader("1 9000000000000000\n"), FM_Word2VecBinary); !errors.Is(err, errFormat) {
+		t.Errorf("a huge dimension gave %v", err)
+	}
+}
+
+func same(t *testing.T, want, got *Embeddings) {
+	t.Helper()
+	if got.Len() != want.Len() || got.Dim != want.Dim {
+		t.Fatalf("%d words of %d values, want %d of %d", got.Len(), got.Dim, want.Len(), want.Dim)
+	}
+	for i, w := range want.Words {
+		v, ok := got.Vector(w)
+		if !ok {
+			t.Errorf("%q is missing", w)
+			continue
+		}
+		for j := range v {
+			if v[j] != want.Vectors[i][j] {
+				t.Errorf("%q = %v, want %v", w, v, want.Vectors[i])
+				break
+			}
+		}
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	tr := small()
+	tr.MinN, tr.MaxN = 3, 4
+	tr.Buckets = 1000
+	e, err := tr.Train(corpus()[:40])
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(t.TempDir(), "emb.model")
+	if err := e.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	var loaded Embeddings
+	if err := loaded.Load(file); err != nil {
+		t.Fatal(err)
+	}
+	same(t, e, &loaded)
+	if a, b := e.Similarity("cats", "dog"), loaded.Similarity("cats", "dog"); a != b || a == 0 {
+		t.Errorf("loaded subwords give %f, want %f", b, a)
+	}
+}
+
+// faq has a refund, a password and a filler axis, every word leaning on the filler one.
+func faq(t *testing.T) *Embeddings {
+	e := NewEmbeddings(4)
+	for word, v := range map[string][]float32{
+		"refund":   {1, 0, 0, 0.5},
+		"money":    {0.9, 0.1, 0, 0.5},
+		"back":     {0.6, 0, 0.3, 0.5},
+		"password": {0, 1, 0, 0.5},
+		"login":    {0.1, 0.9, 0, 0.5},
+		"forgot":   {0, 0.6, 0.3, 0.5},
+		"how":      {0, 0, 0.2, 1},
+		"do":       {0, 0, 0.1, 1},
+		"i":        {0, 0, 0.1, 1},
+		"my":       {0.1, 0.1, 0, 1},
+		"get":      {0, 0, 0.3, 1},
+		"a":        {0, 0, 0, 1},
+	} {
+		if err := e.Add(word, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return e
+}
+
+var questions = []string{"how do i get a refund", "i forgot my password", "my money back", "my login"}
+
+func TestSentenceEncoder(t *testing.T) {
+	encoder := NewSentenceEncoder(faq(t), nil)
+	if err := encoder.Fit(questions); err != nil {
+		t.Fatal(err)
+	}
+	if len(encoder.Components) != 1 || encoder.Frequencies.Samples["my"] != 3 {
+		t.Fatalf("components %v, frequencies %v", encoder.Components, encoder.Frequencies)
+	}
+	var norm float64
+	for _, x := range encoder.Components[0] {
+		norm += x * x
+	}
+	if norm < 0.999 || norm > 1.001 {
+		t.Errorf("component of squared norm %f", norm)
+	}
+	for _, v := range encoder.EncodeAll(questions) {
+		var p float64
+		for i, x := range v {
+			p += float64(x) * encoder.Components[0][i]
+		}
+		if p > 1e-6 || p < -1e-6 {
+			t.Errorf("%v keeps %f of the common component", v, p)
+		}
+	}
+
+	vectors := encoder.EncodeAll(questions[:2])
+	if m := encoder.Match("give my money back", vectors, 2); len(m) != 2 || m[0].Index != 0 || m[0].Similarity <= m[1].Similarity {
+		t.Errorf("money back matched %v", m)
+	}
+	if m := encoder.Match("login", vectors, 1); len(m) != 1 || m[0].Index != 1 {
+		t.Errorf("login matched %v", m)
+	}
+	if m := encoder.Match("unknown words", vectors, 1); m != nil {
+		t.Errorf("a query without known words matched %v", m)
+	}
+
+	a, b := types.NewSentence("I forgot my password"), types.NewSentence("login")
+	c := types.NewSentence("refund")
+	if ab, ac := encoder.SentenceSimilarity(&a, &b), encoder.SentenceSimilarity(&a, &c); ab <= ac {
+		t.Errorf("password ~ login %f, password ~ refund %f", ab, ac)
+	}
+	d1, d2 := types.NewDocument("My money back. A refund!"), types.NewDocument("how do i get a refund")
+	d3 := types.NewDocument("I forgot my login. My password?")
+	if same, other := encoder.DocumentSimilarity(&d1, &d2), encoder.DocumentSimilarity(&d1, &d3); same <= other {
+		t.Errorf("refund documents similarity %f, refund and password %f", same, other)
+	}
+
+	if err := NewSentenceEncoder(faq(t), nil).Fit([]string{"nothing known"}); !errors.Is(err, errNoSentence) {
+		t.Errorf("fitting texts without known words gave %v", err)
+	}
+}
+
+func TestPooling(t *testing.T) {
+	freq := types.NewFreqDist(map[string]int{"my": 1000, "refund": 1, "password": 1})
+	sif := NewSentenceEncoder(faq(t), freq)
+	mean := NewSentenceEncoder(faq(t), freq)
+	mean.Pooling = PL_Mean
+	// the frequent word weighs little with SIF, so sentences sharing it differ more
+	if s, m := sif.Similarity("my refund", "my password"), mean.Similarity("my refund", "my password"); s >= m {
+		t.Errorf("SIF similarity %f, mean %f", s, m)
+	}
+
+	max := NewSentenceEncoder(faq(t), nil)
+	max.Pooling = PL_Max
+	if v := max.Encode("refund password"); len(v) != 4 || v[0] != 1 || v[1] != 1 || v[2] != 0 || v[3] != 0.5 {
+		t.Errorf("max pooling %v", v)
+	}
+	if v := mean.EncodeWords([]string{"refund", "password"}); v[0] != 0.5 || v[1] != 0.5 {
+		t.Errorf("mean pooling %v", v)
+	}
+}
+
+func TestSaveLoadEncoder(t *testing.T) {
+	encoder := NewSentenceEncoder(faq(t), nil)
+	encoder.Fit(questions)
+	file := filepath.Join(t.TempDir(), "encoder.model")
+	if err := encoder.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	var loaded SentenceEncoder
+	if err := loaded.Load(file); err != nil {
+		t.Fatal(err)
+	}
+	want, got := encoder.Encode("my money back"), loaded.Encode("my money back")
+	for i := range want {
+		if want[i] != got[i] {
+			t.Errorf("loaded encoder gives %v, want %v", got, want)
+			break
+		}
+	}
+}
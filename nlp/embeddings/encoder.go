@@ -0,0 +1,421 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package embeddings
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"github.com/broosaction/gotext/utils/persist"
+	stringUtils "github.com/broosaction/gotext/utils/strings"
+	"github.com/broosaction/gotext/utils/types"
+	"log"
+	"math"
+	"sort"
+)
+
+type Pooling uint8
+
+const (
+	// the mean of the word vectors weighted by A / (A + p(w)), smooth inverse frequency
+	PL_SIF Pooling = iota
+	// the plain mean of the word vectors
+	PL_Mean
+	// the largest value of every dimension over the word vectors
+	PL_Max
+)
+
+// the rounds of power iteration finding a common component
+const powerRounds = 100
+
+var (
+	errNoEmbeddings = errors.New("the encoder has no embeddings")
+	errNoSentence   = errors.New("no text has a word with a vector")
+)
+
+// Match is a candidate text, by index, and its cosine similarity to a query.
+type Match struct {
+	Index      int
+	Similarity float64
+}
+
+/**
+ * Sentence Encoder
+ *
+ * Turns a sentence or a document into a single vector of the dimension of
+ * its word vectors, close for texts meaning the same. The default pooling
+ * is SIF (Arora et al., 2017): the word vectors are averaged with a weight
+ * of A / (A + p(w)), p(w) being the share of the word in Frequencies, so
+ * "the" or "my" weigh little and "refund" a lot. Fit then finds the common
+ * components of the texts, the directions every sentence vector shares
+ * whatever it says, and Encode removes them. Mean and max pooling skip the
+ * weights.
+ *
+ * Encode the questions of a FAQ or the examples of every intent once with
+ * EncodeAll, then Match the user texts against them.
+ *
+ * @category    Machine Learning
+
+  **usage
+	emb, _ := embeddings.LoadFile("glove.6B.100d.txt", embeddings.FM_GloVe)
+	encoder := embeddings.NewSentenceEncoder(emb, nil)
+	encoder.Fit(faqQuestions)
+	questions := encoder.EncodeAll(faqQuestions)
+	best := encoder.Match("how can I get my money back", questions, 1)
+	fmt.Println(faqAnswers[best[0].Index], best[0].Similarity)
+*/
+type SentenceEncoder struct {
+	Embeddings *Embeddings
+
+	Pooling Pooling
+
+	// The smoothing of the SIF weights, 1e-3 by default, the lower the less frequent words weigh.
+	A float64
+
+	// The word counts of the SIF weights, ideally of a large corpus. Fit counts its texts when nil.
+	Frequencies *types.FreqDist
+
+	// The number of common components Fit removes, 1 by default and 0 for none.
+	Remove int
+
+	// The common components of the texts fitted, unit vectors.
+	Components [][]float64
+
+	// the number of words in Frequencies, counted by NewSentenceEncoder and Fit
+	total float64
+}
+
+// NewSentenceEncoder builds a SIF encoder of the embeddings weighing with the frequencies, nil to count them at Fit.
+func NewSentenceEncoder(e *Embeddings, frequencies *types.FreqDist) *SentenceEncoder {
+	s := &SentenceEncoder{
+		Embeddings:  e,
+		Pooling:     PL_SIF,
+		A:           1e-3,
+		Frequencies: frequencies,
+		Remove:      1,
+	}
+	if frequencies != nil {
+		s.total = frequencies.N()
+	}
+	return s
+}
+
+func (s *SentenceEncoder) getMeta() (string, string) {
+	return "SentenceEncoder", "01"
+}
+
+// weight is the SIF weight of the word, 1 for words not counted.
+func (s *SentenceEncoder) weight(word string, total float64) float64 {
+	count := s.Frequencies.Samples[stringUtils.Cleanup(word)]
+	if count == 0 || total == 0 {
+		return 1
+	}
+	a := s.A
+	if a <= 0 {
+		a = 1e-3
+	}
+	return a / (a + float64(count)/total)
+}
+
+// pool returns the vector of the words by the Pooling, nil when none has a vector.
+func (s *SentenceEncoder) pool(words []string) []float64 {
+	var pooled []float64
+	var sum float64
+	total := s.total
+	if total == 0 && s.Frequencies != nil {
+		total = s.Frequencies.N()
+	}
+	for _, w := range words {
+		v, ok := s.Embeddings.Vector(w)
+		if !ok {
+			continue
+		}
+		if pooled == nil {
+			pooled = make([]float64, s.Embeddings.Dim)
+			if s.Pooling == PL_Max {
+				for i, x := range v {
+					pooled[i] = float64(x)
+				}
+			}
+		}
+		switch s.Pooling {
+		case PL_Max:
+			for i, x := range v {
+				pooled[i] = math.Max(pooled[i], float64(x))
+			}
+		case PL_Mean:
+			for i, x := range v {
+				pooled[i] += float64(x)
+			}
+			sum++
+		default:
+			weight := 1.0
+			if s.Frequencies != nil {
+				weight = s.weight(w, total)
+			}
+			for i, x := range v {
+				pooled[i] += weight * float64(x)
+			}
+			sum++
+		}
+	}
+	if sum > 0 {
+		for i := range pooled {
+			pooled[i] /= sum
+		}
+	}
+	return pooled
+}
+
+/**
+ * Fit finds the Remove common components of the texts, the top singular
+ * vectors of their pooled vectors, replacing those fitted before. Without
+ * Frequencies, it first counts the words of the texts.
+ */
+func (s *SentenceEncoder) Fit(texts []string) error {
+	if s.Embeddings == nil {
+		return errNoEmbeddings
+	}
+	if s.Frequencies == nil && s.Pooling == PL_SIF {
+		samples := make(map[string]int)
+		for _, text := range texts {
+			for _, w := range s.Embeddings.Tokens(text) {
+				samples[stringUtils.Cleanup(w)]++
+			}
+		}
+		s.Frequencies = types.NewFreqDist(samples)
+	}
+	s.total = 0
+	if s.Frequencies != nil {
+		s.total = s.Frequencies.N()
+	}
+	s.Components = nil
+
+	var vectors [][]float64
+	for _, text := range texts {
+		if v := s.pool(s.Embeddings.Tokens(text)); v != nil {
+			vectors = append(vectors, v)
+		}
+	}
+	if len(vectors) == 0 {
+		return errNoSentence
+	}
+	dim := s.Embeddings.Dim
+	// the components are the top eigenvectors of XᵀX, found one after the other
+	gram := make([][]float64, dim)
+	for i := range gram {
+		gram[i] = make([]float64, dim)
+	}
+	for _, v := range vectors {
+		for i, x := range v {
+			for j, y := range v {
+				gram[i][j] += x * y
+			}
+		}
+	}
+	for c := 0; c < s.Remove && c < dim; c++ {
+		u := s.component(gram)
+		if u == nil {
+			break
+		}
+		s.Components = append(s.Components, u)
+	}
+	return nil
+}
+
+// component returns the top eigenvector of the matrix orthogonal to the components found, by power iteration.
+func (s *SentenceEncoder) component(gram [][]float64) []float64 {
+	dim := len(gram)
+	u := make([]float64, dim)
+	for i := range u {
+		u[i] = 1 / math.Sqrt(float64(dim)) * (1 + float64(i)/float64(dim))
+	}
+	for round := 0; round < powerRounds; round++ {
+		s.deflate(u)
+		next := make([]float64, dim)
+		for i, row := range gram {
+			for j, x := range row {
+				next[i] += x * u[j]
+			}
+		}
+		s.deflate(next)
+		var norm float64
+		for _, x := range next {
+			norm += x * x
+		}
+		norm = math.Sqrt(norm)
+		if norm < 1e-12 {
+			return nil
+		}
+		for i := range next {
+			next[i] /= norm
+		}
+		u = next
+	}
+	return u
+}
+
+// deflate removes the components from the vector.
+func (s *SentenceEncoder) deflate(v []float64) {
+	for _, u := range s.Components {
+		var p float64
+		for i, x := range v {
+			p += x * u[i]
+		}
+		for i := range v {
+			v[i] -= p * u[i]
+		}
+	}
+}
+
+// EncodeWords returns the vector of the words, nil when none has a vector.
+func (s *SentenceEncoder) EncodeWords(words []string) []float32 {
+	if s.Embeddings == nil {
+		return nil
+	}
+	pooled := s.pool(words)
+	if pooled == nil {
+		return nil
+	}
+	s.deflate(pooled)
+	v := make([]float32, len(pooled))
+	for i, x := range pooled {
+		v[i] = float32(x)
+	}
+	return v
+}
+
+// Encode returns the vector of the text, nil when none of its words has a vector.
+func (s *SentenceEncoder) Encode(text string) []float32 {
+	if s.Embeddings == nil {
+		return nil
+	}
+	return s.EncodeWords(s.Embeddings.Tokens(text))
+}
+
+// EncodeAll encodes every text, see Encode.
+func (s *SentenceEncoder) EncodeAll(texts []string) [][]float32 {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = s.Encode(text)
+	}
+	return vectors
+}
+
+// EncodeSentence encodes the tokens of the sentence, or its text when it is not tokenized.
+func (s *SentenceEncoder) EncodeSentence(sentence *types.Sentence) []float32 {
+	if len(sentence.Tokens) > 0 {
+		return s.EncodeWords(sentence.Tokens)
+	}
+	return s.Encode(sentence.Text)
+}
+
+// EncodeDocument encodes the whole text of the document, the words of all its sentences pooled together.
+func (s *SentenceEncoder) EncodeDocument(document *types.Document) []float32 {
+	return s.Encode(document.Text)
+}
+
+// Similarity returns the cosine similarity of the vectors of two texts, 0 when one has none.
+func (s *SentenceEncoder) Similarity(a, b string) float64 {
+	return similarity(s.Encode(a), s.Encode(b))
+}
+
+// SentenceSimilarity returns the cosine similarity of two sentences, see EncodeSentence.
+func (s *SentenceEncoder) SentenceSimilarity(a, b *types.Sentence) float64 {
+	return similarity(s.EncodeSentence(a), s.EncodeSentence(b))
+}
+
+// DocumentSimilarity returns the cosine similarity of two documents, see EncodeDocument.
+func (s *SentenceEncoder) DocumentSimilarity(a, b *types.Document) float64 {
+	return similarity(s.EncodeDocument(a), s.EncodeDocument(b))
+}
+
+/**
+ * Match returns the n vectors, from EncodeAll, most similar to the query,
+ * most similar first. Candidates without vector are skipped, and so is
+ * the query without one.
+ */
+func (s *SentenceEncoder) Match(query string, vectors [][]float32, n int) []Match {
+	q := s.Encode(query)
+	if q == nil {
+		return nil
+	}
+	var matches []Match
+	for i, v := range vectors {
+		if v != nil {
+			matches = append(matches, Match{Index: i, Similarity: Cosine(q, v)})
+		}
+	}
+	sort.SliceStable(matches, func(a, b int) bool {
+		return matches[a].Similarity > matches[b].Similarity
+	})
+	if n > 0 && len(matches) > n {
+		matches = matches[:n]
+	}
+	return matches
+}
+
+func similarity(a, b []float32) float64 {
+	if a == nil || b == nil {
+		return 0
+	}
+	return Cosine(a, b)
+}
+
+//save to a file, with its embeddings
+func (s *SentenceEncoder) Save(file string) error {
+	name, version := s.getMeta()
+	buf := new(bytes.Buffer)
+	encoder := gob.NewEncoder(buf)
+
+	err := encoder.Encode(s)
+	if err != nil {
+		return fmt.Errorf("error encoding model: %s", err)
+	}
+
+	persist.Save(file, persist.Modeldata{
+		Data:    buf.Bytes(),
+		Name:    name,
+		Version: version,
+	})
+	return nil
+}
+
+// Load from the output file.
+func (s *SentenceEncoder) Load(filePath string) error {
+	log.Printf("Loading SentenceEncoder from %s...", filePath)
+	meta := persist.Load(filePath)
+	//get the model metadata
+	name, version := s.getMeta()
+	if meta.Name != name {
+		return fmt.Errorf("This file doesn't contain a SentenceEncoder")
+	}
+	if meta.Version != version {
+		return fmt.Errorf("Can't understand this file format")
+	}
+
+	// decode into a fresh one, gob leaves the fields saved as zero values untouched
+	var loaded SentenceEncoder
+	decoder := gob.NewDecoder(bytes.NewBuffer(meta.Data))
+	err := decoder.Decode(&loaded)
+	if err != nil {
+		return fmt.Errorf("error decoding checkpoint file: %s", err)
+	}
+	if loaded.Embeddings == nil {
+		loaded.Embeddings = NewEmbeddings(0)
+	}
+	if loaded.Embeddings.Index == nil {
+		loaded.Embeddings.Index = make(map[string]int)
+	}
+	if loaded.Frequencies != nil {
+		loaded.total = loaded.Frequencies.N()
+	}
+	*s = loaded
+	return nil
+}
@@ -0,0 +1,221 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package embeddings
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type Format uint8
+
+const (
+	// a "words dim" header line, then a word and its values per line, as word2vec -binary 0 writes
+	FM_Word2VecText Format = iota
+	// a "words dim" header line, then every word, a space and its values as little endian float32, as word2vec -binary 1 writes
+	FM_Word2VecBinary
+	// a word and its values per line without header, as the GloVe vectors are published
+	FM_GloVe
+)
+
+const (
+	// the most vectors room is made for from the header, more grow the slices as they are read
+	maxPrealloc = 1 << 16
+	// the largest dimension a header may give
+	maxDim = 1 << 16
+)
+
+var (
+	errFormat        = errors.New("malformed embeddings file")
+	errUnknownFormat = errors.New("unknown embeddings format")
+)
+
+// LoadFile reads pre-trained embeddings from a file in the format.
+func LoadFile(path string, format Format) (*Embeddings, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f, format)
+}
+
+// Read reads embeddings in the format. Words repeated keep their first vector.
+func Read(r io.Reader, format Format) (*Embeddings, error) {
+	br := bufio.NewReaderSize(r, 1<<20)
+	switch format {
+	case FM_Word2VecText:
+		return readText(br, true)
+	case FM_GloVe:
+		return readText(br, false)
+	case FM_Word2VecBinary:
+		return readBinary(br)
+	}
+	return nil, fmt.Errorf("format %d: %w", format, errUnknownFormat)
+}
+
+// readHeader reads the "words dim" line of the word2vec formats.
+func readHeader(br *bufio.Reader) (int, int, error) {
+	line, err := br.ReadString('\n')
+	if err != nil && line == "" {
+		return 0, 0, fmt.Errorf("no header: %w", errFormat)
+	}
+	fields := strings.Fields(line)
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("header %q: %w", strings.TrimSpace(line), errFormat)
+	}
+	words, err1 := strconv.Atoi(fields[0])
+	dim, err2 := strconv.Atoi(fields[1])
+	if err1 != nil || err2 != nil || words < 0 || dim <= 0 || dim > maxDim {
+		return 0, 0, fmt.Errorf("header %q: %w", strings.TrimSpace(line), errFormat)
+	}
+	return words, dim, nil
+}
+
+// capacity is the number of vectors to allocate room for, at most maxPrealloc whatever the header says.
+func capacity(words int) int {
+	if words > maxPrealloc {
+		return maxPrealloc
+	}
+	return words
+}
+
+/**
+ * readText reads a word and its values per line. Without header the
+ * dimension is that of the first line. The word is everything before the
+ * last dim values, some GloVe words having spaces.
+ */
+func readText(br *bufio.Reader, header bool) (*Embeddings, error) {
+	e := NewEmbeddings(0)
+	lineNumber := 0
+	if header {
+		words, dim, err := readHeader(br)
+		if err != nil {
+			return nil, err
+		}
+		e.Dim = dim
+		e.Words = make([]string, 0, capacity(words))
+		e.Vectors = make([][]float32, 0, capacity(words))
+		lineNumber++
+	}
+	for {
+		line, err := br.ReadString('\n')
+		if line == "" && err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		lineNumber++
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if e.Dim == 0 {
+			e.Dim = len(fields) - 1
+		}
+		if len(fields) < e.Dim+1 {
+			return nil, fmt.Errorf("line %d has %d values, not %d: %w", lineNumber, len(fields)-1, e.Dim, errFormat)
+		}
+		at := len(fields) - e.Dim
+		word := strings.Join(fields[:at], " ")
+		vector := make([]float32, e.Dim)
+		for i, field := range fields[at:] {
+			x, err := strconv.ParseFloat(field, 32)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %q: %w", lineNumber, field, errFormat)
+			}
+			vector[i] = float32(x)
+		}
+		if !e.Has(word) {
+			e.Add(word, vector)
+		}
+	}
+	return e, nil
+}
+
+func readBinary(br *bufio.Reader) (*Embeddings, error) {
+	words, dim, err := readHeader(br)
+	if err != nil {
+		return nil, err
+	}
+	e := NewEmbeddings(dim)
+	e.Words = make([]string, 0, capacity(words))
+	e.Vectors = make([][]float32, 0, capacity(words))
+	raw := make([]byte, 4*dim)
+	for n := 0; n < words; n++ {
+		word, err := br.ReadString(' ')
+		if err != nil {
+			return nil, fmt.Errorf("word %d of %d: %w", n+1, words, errFormat)
+		}
+		// the vectors end with a new line in files written by word2vec, not in all others
+		word = strings.TrimLeft(strings.TrimSuffix(word, " "), "\n")
+		if _, err := io.ReadFull(br, raw); err != nil {
+			return nil, fmt.Errorf("vector of %q: %w", word, errFormat)
+		}
+		vector := make([]float32, dim)
+		for i := range vector {
+			vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(raw[4*i:]))
+		}
+		if !e.Has(word) {
+			e.Add(word, vector)
+		}
+	}
+	return e, nil
+}
+
+// SaveFile writes the embeddings to a file in the format, see Write.
+func (e *Embeddings) SaveFile(path string, format Format) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := e.Write(f, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Write writes the vectors of the vocabulary in the format, for other tools. Subwords are not written.
+func (e *Embeddings) Write(w io.Writer, format Format) error {
+	bw := bufio.NewWriter(w)
+	if format > FM_GloVe {
+		return fmt.Errorf("format %d: %w", format, errUnknownFormat)
+	}
+	if format != FM_GloVe {
+		fmt.Fprintf(bw, "%d %d\n", e.Len(), e.Dim)
+	}
+	raw := make([]byte, 4*e.Dim)
+	for i, word := range e.Words {
+		if format == FM_Word2VecBinary {
+			bw.WriteString(word)
+			bw.WriteByte(' ')
+			for j, x := range e.Vectors[i] {
+				binary.LittleEndian.PutUint32(raw[4*j:], math.Float32bits(x))
+			}
+			bw.Write(raw)
+			bw.WriteByte('\n')
+			continue
+		}
+		bw.WriteString(word)
+		for _, x := range e.Vectors[i] {
+			bw.WriteByte(' ')
+			bw.WriteString(strconv.FormatFloat(float64(x), 'g', -1, 32))
+		}
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package embeddings
+
+import (
+	"hash/fnv"
+)
+
+/**
+ * Subwords holds the vectors of character n-grams as fastText does
+ * (Bojanowski et al., 2017). The word is wrapped in "<" and ">" so prefixes
+ * and suffixes differ from the middle of words, "where" giving "<wh", "whe",
+ * "her", "ere", "re>" for trigrams, and every n-gram is hashed into one of
+ * Buckets vectors. An unknown word, a typo or a rare inflection, gets the
+ * mean vector of its n-grams seen in training.
+ */
+type Subwords struct {
+	// The shortest and longest n-grams, in characters.
+	MinN, MaxN int
+
+	// The number of hash buckets, n-grams in the same one share their vector.
+	Buckets int
+
+	// The vector of every bucket seen in training.
+	Vectors map[int][]float32
+}
+
+// ids returns the bucket of every n-gram of the word, not counting the whole word.
+func (s *Subwords) ids(word string) []int {
+	if s.Buckets <= 0 {
+		return nil
+	}
+	runes := []rune("<" + word + ">")
+	var ids []int
+	for n := s.MinN; n <= s.MaxN; n++ {
+		for i := 0; i+n <= len(runes); i++ {
+			if n == len(runes) {
+				continue
+			}
+			h := fnv.New32a()
+			h.Write([]byte(string(runes[i : i+n])))
+			ids = append(ids, int(h.Sum32()%uint32(s.Buckets)))
+		}
+	}
+	return ids
+}
+
+// Vector returns the mean vector of the n-grams of the word seen in training, nil when none was.
+func (s *Subwords) Vector(word string, dim int) []float32 {
+	var mean []float32
+	count := 0
+	for _, id := range s.ids(word) {
+		v, ok := s.Vectors[id]
+		if !ok {
+			continue
+		}
+		if mean == nil {
+			mean = make([]float32, dim)
+		}
+		for i, x := range v {
+			mean[i] += x
+		}
+		count++
+	}
+	for i := range mean {
+		mean[i] /= float32(count)
+	}
+	return mean
+}
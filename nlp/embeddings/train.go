@@ -0,0 +1,412 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package embeddings
+
+import (
+	"errors"
+	"github.com/broosaction/gotext/tokenizers"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+type Model uint8
+
+const (
+	// predict the words around from the word
+	TM_SkipGram Model = iota
+	// predict the word from the mean of the words around
+	TM_CBOW
+)
+
+// the size of the table negative samples are drawn from
+const unigramTable = 1000000
+
+var errEmptyVocabulary = errors.New("no word is frequent enough to train a vector")
+
+/**
+ * Trainer
+ *
+ * Trains word vectors on a corpus as word2vec does (Mikolov et al., 2013).
+ * A window slides over the words of every text, skip-gram learning to
+ * predict the words around from the one in the middle and CBOW the word in
+ * the middle from those around. Rather than a softmax over the whole
+ * vocabulary, every prediction is told apart from Negative words drawn at
+ * random, frequent ones more often, and very frequent words are skipped at
+ * random so "the" doesn't crowd the windows.
+ *
+ * With MinN and MaxN set, as fastText does, a word is also the sum of its
+ * character n-grams, which share what they learn across words and give a
+ * vector to words never seen.
+ *
+ * @category    Machine Learning
+
+  **usage
+	tr := embeddings.NewTrainer()
+	tr.Dim = 50
+	tr.MinN, tr.MaxN = 3, 6
+	emb, err := tr.Train(tickets)
+	fmt.Println(emb.Nearest("refund", 5))
+	emb.SaveFile("tickets.vec", embeddings.FM_Word2VecText)
+*/
+type Trainer struct {
+	Model Model
+
+	// The dimension of the vectors.
+	Dim int
+
+	// The largest distance of the words around, every window is drawn between 1 and Window.
+	Window int
+
+	// The number of negative samples of every prediction.
+	Negative int
+
+	// Words seen fewer times are dropped.
+	MinCount int
+
+	// The passes over the corpus.
+	Epochs int
+
+	// The starting learning rate, going down to 0 along the training.
+	Alpha float64
+
+	// The threshold of frequency above which words are skipped at random, 0 keeps them all.
+	Sample float64
+
+	// The shortest and longest character n-grams, 0 trains without subwords.
+	MinN, MaxN int
+
+	// The number of hash buckets of the n-grams.
+	Buckets int
+
+	Seed int64
+
+	// name of the tokenizer, see tokenizers.GetTokenizer
+	Tokenizer string
+
+	// lower case the text before tokenizing
+	Lowercase bool
+}
+
+func NewTrainer() *Trainer {
+	return &Trainer{
+		Model:     TM_SkipGram,
+		Dim:       100,
+		Window:    5,
+		Negative:  5,
+		MinCount:  5,
+		Epochs:    5,
+		Alpha:     0.025,
+		Sample:    1e-3,
+		Buckets:   2000000,
+		Tokenizer: tokenizers.DefaultTokenizerName,
+		Lowercase: true,
+	}
+}
+
+// training is the state of one Train.
+type training struct {
+	*Trainer
+	rng      *rand.Rand
+	vocab    map[string]int
+	counts   []int
+	input    [][]float32
+	output   [][]float32
+	subwords *Subwords
+	ngrams   [][]int
+	table    []int32
+	keep     []float64
+	grad     []float32
+	hidden   []float32
+}
+
+// defaults replaces the settings out of range by their default.
+func (t *Trainer) defaults() {
+	d := NewTrainer()
+	if t.Dim <= 0 {
+		t.Dim = d.Dim
+	}
+	if t.Window <= 0 {
+		t.Window = d.Window
+	}
+	if t.Negative < 0 {
+		t.Negative = 0
+	}
+	if t.Epochs <= 0 {
+		t.Epochs = d.Epochs
+	}
+	if t.Alpha <= 0 {
+		t.Alpha = d.Alpha
+	}
+	if t.Buckets <= 0 {
+		t.Buckets = d.Buckets
+	}
+	if t.MaxN > 0 && t.MinN < 1 {
+		t.MinN = 1
+	}
+	if t.Tokenizer == "" {
+		t.Tokenizer = d.Tokenizer
+	}
+}
+
+// tokens splits the text into words as the vectors are trained on.
+func (t *Trainer) tokens(text string) []string {
+	if t.Lowercase {
+		text = strings.ToLower(text)
+	}
+	return tokenizers.GetTokenizer(t.Tokenizer).Tokenize(text)
+}
+
+// Train learns the vectors of the words of the texts seen at least MinCount times.
+func (t *Trainer) Train(texts []string) (*Embeddings, error) {
+	settings := *t
+	settings.defaults()
+	t = &settings
+	tr := &training{Trainer: t, rng: rand.New(rand.NewSource(t.Seed))}
+	docs := make([][]string, len(texts))
+	for i, text := range texts {
+		docs[i] = t.tokens(text)
+	}
+	total := tr.buildVocabulary(docs)
+	if len(tr.counts) == 0 {
+		return nil, errEmptyVocabulary
+	}
+	tr.init()
+
+	epochs, window := t.Epochs, t.Window
+	planned := float64(epochs * total)
+	processed := 0
+	ids := make([]int, 0, 64)
+	for epoch := 0; epoch < epochs; epoch++ {
+		for _, doc := range docs {
+			ids = ids[:0]
+			for _, w := range doc {
+				if id, ok := tr.vocab[w]; ok {
+					processed++
+					if tr.rng.Float64() < tr.keep[id] {
+						ids = append(ids, id)
+					}
+				}
+			}
+			lr := t.Alpha * math.Max(1-float64(processed)/planned, 1e-4)
+			for i, id := range ids {
+				b := 1 + tr.rng.Intn(window)
+				from, to := i-b, i+b
+				if from < 0 {
+					from = 0
+				}
+				if to >= len(ids) {
+					to = len(ids) - 1
+				}
+				if t.Model == TM_CBOW {
+					var rows [][]float32
+					for j := from; j <= to; j++ {
+						if j != i {
+							rows = append(rows, tr.rows(ids[j])...)
+						}
+					}
+					if len(rows) > 0 {
+						tr.update(rows, id, lr)
+					}
+					continue
+				}
+				rows := tr.rows(id)
+				for j := from; j <= to; j++ {
+					if j != i {
+						tr.update(rows, ids[j], lr)
+					}
+				}
+			}
+		}
+	}
+	return tr.embeddings(), nil
+}
+
+// buildVocabulary keeps the words seen MinCount times, most frequent first, and returns their count in the corpus.
+func (tr *training) buildVocabulary(docs [][]string) int {
+	counts := make(map[string]int)
+	for _, doc := range docs {
+		for _, w := range doc {
+			counts[w]++
+		}
+	}
+	var words []string
+	for w, c := range counts {
+		if c >= tr.MinCount {
+			words = append(words, w)
+		}
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if counts[words[i]] != counts[words[j]] {
+			return counts[words[i]] > counts[words[j]]
+		}
+		return words[i] < words[j]
+	})
+	tr.vocab = make(map[string]int, len(words))
+	tr.counts = make([]int, len(words))
+	total := 0
+	for i, w := range words {
+		tr.vocab[w] = i
+		tr.counts[i] = counts[w]
+		total += counts[w]
+	}
+
+	// keep a word with a chance of (sqrt(f / s) + 1) * s / f, f being its share of the corpus
+	tr.keep = make([]float64, len(words))
+	for i, c := range tr.counts {
+		tr.keep[i] = 1
+		if tr.Sample > 0 {
+			f := float64(c) / float64(total)
+			tr.keep[i] = (math.Sqrt(f/tr.Sample) + 1) * tr.Sample / f
+		}
+	}
+	return total
+}
+
+// init draws the input vectors at random, the output ones start at 0.
+func (tr *training) init() {
+	dim := tr.Dim
+	tr.input = make([][]float32, len(tr.counts))
+	tr.output = make([][]float32, len(tr.counts))
+	for i := range tr.input {
+		tr.input[i] = tr.random()
+		tr.output[i] = make([]float32, dim)
+	}
+	tr.grad = make([]float32, dim)
+	tr.hidden = make([]float32, dim)
+
+	if tr.MaxN > 0 {
+		tr.subwords = &Subwords{MinN: tr.MinN, MaxN: tr.MaxN, Buckets: tr.Buckets, Vectors: make(map[int][]float32)}
+		tr.ngrams = make([][]int, len(tr.counts))
+		words := tr.words()
+		for i, w := range words {
+			tr.ngrams[i] = tr.subwords.ids(w)
+			for _, id := range tr.ngrams[i] {
+				if _, ok := tr.subwords.Vectors[id]; !ok {
+					tr.subwords.Vectors[id] = tr.random()
+				}
+			}
+		}
+	}
+
+	// negative samples are drawn with a chance proportional to count^0.75
+	var sum float64
+	for _, c := range tr.counts {
+		sum += math.Pow(float64(c), 0.75)
+	}
+	size := unigramTable
+	if size > 100*len(tr.counts) {
+		size = 100 * len(tr.counts)
+	}
+	tr.table = make([]int32, 0, size)
+	for i, c := range tr.counts {
+		n := int(math.Ceil(math.Pow(float64(c), 0.75) / sum * float64(size)))
+		for j := 0; j < n && len(tr.table) < size; j++ {
+			tr.table = append(tr.table, int32(i))
+		}
+	}
+}
+
+func (tr *training) random() []float32 {
+	v := make([]float32, tr.Dim)
+	for i := range v {
+		v[i] = float32((tr.rng.Float64() - 0.5) / float64(tr.Dim))
+	}
+	return v
+}
+
+// words returns the vocabulary by index.
+func (tr *training) words() []string {
+	words := make([]string, len(tr.counts))
+	for w, i := range tr.vocab {
+		words[i] = w
+	}
+	return words
+}
+
+// rows returns the input vectors making the word, itself and its n-grams.
+func (tr *training) rows(id int) [][]float32 {
+	rows := [][]float32{tr.input[id]}
+	if tr.subwords != nil {
+		for _, n := range tr.ngrams[id] {
+			rows = append(rows, tr.subwords.Vectors[n])
+		}
+	}
+	return rows
+}
+
+/**
+ * update learns to predict the target from the mean h of the input rows,
+ * against Negative words drawn at random. Every output vector o moves by
+ * lr * (label - σ(h·o)) * h and every input row by the sum of the same
+ * steps along o.
+ */
+func (tr *training) update(rows [][]float32, target int, lr float64) {
+	for i := range tr.hidden {
+		tr.hidden[i], tr.grad[i] = 0, 0
+	}
+	for _, row := range rows {
+		for i, x := range row {
+			tr.hidden[i] += x
+		}
+	}
+	for i := range tr.hidden {
+		tr.hidden[i] /= float32(len(rows))
+	}
+
+	for n := 0; n <= tr.Negative; n++ {
+		id, label := target, 1.0
+		if n > 0 {
+			id = int(tr.table[tr.rng.Intn(len(tr.table))])
+			if id == target {
+				continue
+			}
+			label = 0
+		}
+		out := tr.output[id]
+		g := float32(lr * (label - sigmoid(dot(tr.hidden, out))))
+		for i := range out {
+			tr.grad[i] += g * out[i]
+			out[i] += g * tr.hidden[i]
+		}
+	}
+	for _, row := range rows {
+		for i := range row {
+			row[i] += tr.grad[i]
+		}
+	}
+}
+
+// embeddings returns the vectors trained, with their n-grams for subwords.
+func (tr *training) embeddings() *Embeddings {
+	e := NewEmbeddings(tr.Dim)
+	e.Tokenizer = tr.Tokenizer
+	for i, w := range tr.words() {
+		rows := tr.rows(i)
+		v := make([]float32, tr.Dim)
+		for _, row := range rows {
+			for j, x := range row {
+				v[j] += x / float32(len(rows))
+			}
+		}
+		e.Add(w, v)
+	}
+	e.Subwords = tr.subwords
+	return e
+}
+
+func sigmoid(x float64) float64 {
+	if x > 6 {
+		return 1
+	}
+	if x < -6 {
+		return 0
+	}
+	return 1 / (1 + math.Exp(-x))
+}
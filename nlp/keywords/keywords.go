@@ -0,0 +1,315 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+// Package keywords extracts the keywords and multi-word keyphrases of a
+// text, to tag or index it. Candidates are the runs of words between stop
+// words and punctuation, optionally only the words a part of speech tagger
+// marks as nouns or adjectives, and they are ranked by TF-IDF against a
+// background corpus, RAKE, YAKE or TextRank.
+package keywords
+
+import (
+	"github.com/broosaction/gotext/nlp/nlptools"
+	stringUtils "github.com/broosaction/gotext/utils/strings"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+type Method uint8
+
+const (
+	// the TF-IDF weight of the words against the Background corpus
+	KM_TFIDF Method = iota
+	// the degree over the frequency of the words in stop word delimited phrases
+	KM_RAKE
+	// statistics of the casing, position, frequency and context of the words
+	KM_YAKE
+	// PageRank over the graph of words occurring together
+	KM_TextRank
+)
+
+var (
+	sentenceEnd   = regexp.MustCompile(`[.!?]+(\s|$)|\n\s*\n`)
+	fragmentEnd   = regexp.MustCompile(`[,;:()\[\]{}"“”<>|/\\\n\t]+|\s[-–—]+\s`)
+	wordPattern   = regexp.MustCompile(`[\p{L}\p{N}]+(['’][\p{L}]+)?`)
+	numberPattern = regexp.MustCompile(`^[\p{N}]+$`)
+)
+
+// Keyword is a word or phrase of the text and how well it describes it.
+type Keyword struct {
+	// The lower cased words, separated by a space.
+	Text string
+
+	// The higher the better, scores compare only within one method and text.
+	Score float64
+
+	// How often the phrase occurs in the text.
+	Count int
+}
+
+// Tagger gives the part of speech of every token as {token, tag}, like pos.Tagger.
+type Tagger interface {
+	Tag(tokens []string) [][]string
+}
+
+/**
+ * Keyword Extractor
+ *
+ * Splits a text into sentences, the sentences into fragments at
+ * punctuation and the fragments into words. Stop words, numbers, words
+ * shorter than MinLength and, with a Tagger, words whose tag is not in
+ * Tags end a candidate phrase, so candidates are runs of content words of
+ * up to MaxWords, longer runs being cut in pieces of MaxWords.
+ *
+ * The methods need no training, but TFIDF needs a Background corpus of
+ * texts like the ones to tag to tell the common words from the telling
+ * ones, without it words weigh by their frequency in the text alone.
+ *
+ * @category    Natural Language Processing
+
+  **usage
+	ex := keywords.NewExtractor()
+	ex.AddBackground(archive...)
+	for _, kw := range ex.Extract(article, keywords.KM_YAKE, 10) {
+		fmt.Println(kw.Text, kw.Score)
+	}
+	// nouns and adjectives only
+	model, _ := pos.LoadModel("./data/models/pos/en")
+	ex.Tagger = pos.NewTagger(model)
+*/
+type Extractor struct {
+	// words ending a phrase, strings.GetStopwords by default
+	StopWords map[string]struct{}
+
+	// The most words of a phrase, 3 by default.
+	MaxWords int
+
+	// Shorter words end a phrase, 2 letters by default.
+	MinLength int
+
+	// Optional part of speech tagger, words tagged outside Tags end a phrase.
+	Tagger Tagger
+	Tags   map[string]bool
+
+	// How many following words a word co-occurs with, for YAKE and TextRank, 2 by default.
+	Window int
+
+	// The corpus the TF-IDF weights are computed against.
+	Background *nlptools.TFIDF
+}
+
+func NewExtractor() *Extractor {
+	return &Extractor{
+		StopWords: stringUtils.GetStopwords(),
+		MaxWords:  3,
+		MinLength: 2,
+		Tags:      map[string]bool{"NOUN": true, "ADJ": true},
+		Window:    2,
+	}
+}
+
+// AddBackground adds texts to the Background corpus of TFIDF.
+func (e *Extractor) AddBackground(texts ...string) {
+	if e.Background == nil {
+		e.Background = nlptools.NewTFIDF()
+	}
+	e.Background.AddDocs(texts...)
+}
+
+// Extract returns the n best keywords of the text by the method, best first. n <= 0 returns all.
+func (e *Extractor) Extract(text string, method Method, n int) []Keyword {
+	switch method {
+	case KM_RAKE:
+		return e.RAKE(text, n)
+	case KM_YAKE:
+		return e.YAKE(text, n)
+	case KM_TextRank:
+		return e.TextRank(text, n)
+	default:
+		return e.TFIDF(text, n)
+	}
+}
+
+// token is a word of the text.
+type token struct {
+	// as written, and lower cased
+	text, word string
+
+	// the sentence and the fragment of the token, counted over the text
+	sentence, fragment int
+
+	// whether the token starts its sentence
+	first bool
+
+	// whether the token can be part of a phrase
+	candidate bool
+}
+
+// document is a text cut into tokens.
+type document struct {
+	tokens    []token
+	sentences int
+}
+
+func (e *Extractor) parse(text string) document {
+	var d document
+	fragment := 0
+	for _, sentence := range sentenceEnd.Split(text, -1) {
+		start := len(d.tokens)
+		for _, part := range fragmentEnd.Split(sentence, -1) {
+			for _, w := range wordPattern.FindAllString(part, -1) {
+				d.tokens = append(d.tokens, token{
+					text:     w,
+					word:     strings.ToLower(w),
+					sentence: d.sentences,
+					fragment: fragment,
+					first:    len(d.tokens) == start,
+				})
+			}
+			fragment++
+		}
+		if len(d.tokens) > start {
+			e.mark(d.tokens[start:])
+			d.sentences++
+		}
+	}
+	return d
+}
+
+// mark tells which tokens of a sentence can be part of a phrase.
+func (e *Extractor) mark(tokens []token) {
+	minLength := e.MinLength
+	if minLength <= 0 {
+		minLength = 2
+	}
+	for i := range tokens {
+		_, stop := e.StopWords[tokens[i].word]
+		tokens[i].candidate = !stop && len([]rune(tokens[i].word)) >= minLength && !numberPattern.MatchString(tokens[i].word)
+	}
+	if e.Tagger == nil || len(e.Tags) == 0 {
+		return
+	}
+	words := make([]string, len(tokens))
+	for i, t := range tokens {
+		words[i] = t.text
+	}
+	for i, tagged := range e.Tagger.Tag(words) {
+		// the pos tagger guesses the tag of unknown words, with a question mark
+		if i < len(tokens) && len(tagged) > 1 && !e.Tags[strings.TrimSuffix(tagged[1], "?")] {
+			tokens[i].candidate = false
+		}
+	}
+}
+
+// runs returns the runs of candidate tokens within a fragment, whatever their length.
+func runs(d document) [][]token {
+	var runs [][]token
+	var run []token
+	for _, t := range d.tokens {
+		if !t.candidate || (len(run) > 0 && run[len(run)-1].fragment != t.fragment) {
+			if len(run) > 0 {
+				runs = append(runs, run)
+			}
+			run = nil
+		}
+		if t.candidate {
+			run = append(run, t)
+		}
+	}
+	if len(run) > 0 {
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+// phrases returns the runs of candidates, those longer than MaxWords cut in pieces.
+func (e *Extractor) phrases(d document) [][]token {
+	maxWords := e.maxWords()
+	var phrases [][]token
+	for _, run := range runs(d) {
+		for len(run) > maxWords {
+			phrases = append(phrases, run[:maxWords])
+			run = run[maxWords:]
+		}
+		phrases = append(phrases, run)
+	}
+	return phrases
+}
+
+// ngrams returns every phrase of up to MaxWords within the runs of candidates, with repeats.
+func (e *Extractor) ngrams(d document) [][]token {
+	maxWords := e.maxWords()
+	var grams [][]token
+	for _, run := range runs(d) {
+		for i := range run {
+			for n := 1; n <= maxWords && i+n <= len(run); n++ {
+				grams = append(grams, run[i:i+n])
+			}
+		}
+	}
+	return grams
+}
+
+func (e *Extractor) maxWords() int {
+	if e.MaxWords <= 0 {
+		return 3
+	}
+	return e.MaxWords
+}
+
+func (e *Extractor) window() int {
+	if e.Window <= 0 {
+		return 2
+	}
+	return e.Window
+}
+
+// phrase joins the lower cased words of tokens.
+func phrase(tokens []token) string {
+	words := make([]string, len(tokens))
+	for i, t := range tokens {
+		words[i] = t.word
+	}
+	return strings.Join(words, " ")
+}
+
+// counts returns how often every phrase occurs.
+func counts(phrases [][]token) map[string]int {
+	n := make(map[string]int)
+	for _, p := range phrases {
+		n[phrase(p)]++
+	}
+	return n
+}
+
+// top sorts the keywords best first, the text breaking ties, and keeps n of them.
+func top(scores map[string]float64, counts map[string]int, n int) []Keyword {
+	keywords := make([]Keyword, 0, len(scores))
+	for text, score := range scores {
+		keywords = append(keywords, Keyword{Text: text, Score: score, Count: counts[text]})
+	}
+	sort.Slice(keywords, func(i, j int) bool {
+		if keywords[i].Score != keywords[j].Score {
+			return keywords[i].Score > keywords[j].Score
+		}
+		return keywords[i].Text < keywords[j].Text
+	})
+	if n > 0 && n < len(keywords) {
+		keywords = keywords[:n]
+	}
+	return keywords
+}
+
+// isUpper tells whether a word starts with a capital letter.
+func isUpper(word string) bool {
+	for _, r := range word {
+		return unicode.IsUpper(r)
+	}
+	return false
+}
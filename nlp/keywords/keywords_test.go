@@ -0,0 +1,204 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package keywords
+
+import (
+	"github.com/broosaction/gotext/nlp/pos"
+	"strings"
+	"testing"
+)
+
+const article = `Compatibility of systems of linear constraints over the set of natural numbers.
+Criteria of compatibility of a system of linear Diophantine equations, strict inequations,
+and nonstrict inequations are considered. Upper bounds for components of a minimal set of
+solutions and algorithms of construction of minimal generating sets of solutions for all
+types of systems are given. These criteria and the corresponding algorithms for constructing
+a minimal supporting set of solutions can be used in solving all the considered types of
+systems and systems of mixed types.`
+
+func texts(keywords []Keyword) []string {
+	out := make([]string, len(keywords))
+	for i, kw := range keywords {
+		out[i] = kw.Text
+	}
+	return out
+}
+
+func contains(keywords []Keyword, text string) bool {
+	for _, kw := range keywords {
+		if kw.Text == text {
+			return true
+		}
+	}
+	return false
+}
+
+func TestCandidates(t *testing.T) {
+	ex := NewExtractor()
+	d := ex.parse("The quick brown fox, jumps over 3 lazy dogs. A second sentence")
+	if d.sentences != 2 {
+		t.Fatalf("sentences = %d, want 2", d.sentences)
+	}
+	var phrases []string
+	for _, p := range ex.phrases(d) {
+		phrases = append(phrases, phrase(p))
+	}
+	// the comma ends a phrase, stop words and numbers too
+	want := []string{"quick brown fox", "jumps", "lazy dogs", "second sentence"}
+	if strings.Join(phrases, "|") != strings.Join(want, "|") {
+		t.Errorf("phrases = %q, want %q", phrases, want)
+	}
+
+	ex.MaxWords = 2
+	phrases = nil
+	for _, p := range ex.phrases(d) {
+		phrases = append(phrases, phrase(p))
+	}
+	if phrases[0] != "quick brown" || phrases[1] != "fox" {
+		t.Errorf("long phrases are not cut: %q", phrases)
+	}
+	// quick, quick brown, brown, brown fox, fox, jumps, ...
+	if n := len(ex.ngrams(d)); n != 5+1+3+3 {
+		t.Errorf("ngrams = %d, want 12", n)
+	}
+}
+
+func TestRAKE(t *testing.T) {
+	ex := NewExtractor()
+	ex.MaxWords = 4
+	keywords := ex.RAKE(article, 0)
+	if len(keywords) == 0 {
+		t.Fatal("no keywords")
+	}
+	// the example of the RAKE paper
+	best := texts(keywords[:3])
+	for _, want := range []string{"minimal generating sets", "linear diophantine equations", "minimal supporting set"} {
+		if !contains(keywords[:3], want) {
+			t.Errorf("best = %q, want %q among them", best, want)
+		}
+	}
+	for i := 1; i < len(keywords); i++ {
+		if keywords[i].Score > keywords[i-1].Score {
+			t.Fatalf("keywords not sorted: %v", keywords)
+		}
+	}
+	if got := ex.RAKE(article, 5); len(got) != 5 {
+		t.Errorf("n = 5 gave %d keywords", len(got))
+	}
+}
+
+func TestYAKE(t *testing.T) {
+	ex := NewExtractor()
+	text := `NASA launched a new telescope. The telescope will study distant galaxies.
+Astronomers at NASA expect the telescope to find young galaxies. Funding was approved in May.`
+	keywords := ex.YAKE(text, 5)
+	if len(keywords) != 5 {
+		t.Fatalf("got %d keywords", len(keywords))
+	}
+	for _, want := range []string{"nasa", "telescope"} {
+		if !contains(keywords, want) {
+			t.Errorf("best = %q, want %q among them", texts(keywords), want)
+		}
+	}
+	for _, kw := range keywords {
+		if kw.Score <= 0 {
+			t.Errorf("%q scores %f", kw.Text, kw.Score)
+		}
+	}
+	if got := ex.YAKE("the and of", 0); len(got) != 0 {
+		t.Errorf("stop words only gave %v", got)
+	}
+}
+
+func TestTextRank(t *testing.T) {
+	ex := NewExtractor()
+	keywords := ex.TextRank(article, 0)
+	if len(keywords) == 0 {
+		t.Fatal("no keywords")
+	}
+	found := false
+	for _, kw := range keywords[:5] {
+		if strings.Contains(kw.Text, "systems") || strings.Contains(kw.Text, "set") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("best = %q, want the most connected words first", texts(keywords[:5]))
+	}
+	for _, kw := range keywords {
+		if kw.Text == "" || kw.Count == 0 {
+			t.Errorf("bad keyword %+v", kw)
+		}
+	}
+}
+
+func TestTFIDF(t *testing.T) {
+	ex := NewExtractor()
+	ex.AddBackground(
+		"The market rallied as the central bank held interest rates.",
+		"The bank said the market expects interest rates to fall.",
+		"Shares fell as the market weighed the bank results.",
+	)
+	text := "The bank opened a new quantum computing lab. Quantum computing could change the market."
+	keywords := ex.TFIDF(text, 3)
+	if keywords[0].Text != "quantum computing" {
+		t.Errorf("best = %q, want quantum computing first", texts(keywords))
+	}
+	all := ex.Extract(text, KM_TFIDF, 0)
+	var bank, quantum float64
+	for _, kw := range all {
+		switch kw.Text {
+		case "bank":
+			bank = kw.Score
+		case "quantum":
+			quantum = kw.Score
+		}
+	}
+	if bank >= quantum {
+		t.Errorf("bank = %f, quantum = %f, the background should lower bank", bank, quantum)
+	}
+
+	// without background, words weigh by their count
+	ex.Background = nil
+	if got := ex.TFIDF(text, 1); got[0].Text != "quantum computing" {
+		t.Errorf("without background best = %q", texts(got))
+	}
+}
+
+// the tagger of the pos package fits
+var _ Tagger = (*pos.Tagger)(nil)
+
+// tagger tags the words of a list as nouns, the others as verbs.
+type tagger map[string]bool
+
+func (tg tagger) Tag(tokens []string) [][]string {
+	tagged := make([][]string, len(tokens))
+	for i, tk := range tokens {
+		tag := "VERB"
+		if tg[strings.ToLower(tk)] {
+			tag = "NOUN?"
+		}
+		tagged[i] = []string{tk, tag}
+	}
+	return tagged
+}
+
+func TestTagger(t *testing.T) {
+	ex := NewExtractor()
+	ex.Tagger = tagger{"cat": true, "mat": true, "dog": true}
+	keywords := ex.RAKE("The cat sat quietly on the mat. The dog barked.", 0)
+	got := texts(keywords)
+	if len(got) != 3 {
+		t.Fatalf("keywords = %q, want the nouns only", got)
+	}
+	for _, kw := range []string{"cat", "mat", "dog"} {
+		if !contains(keywords, kw) {
+			t.Errorf("keywords = %q, missing %q", got, kw)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package keywords
+
+/**
+ * RAKE, Rapid Automatic Keyword Extraction (Rose et al., 2010)
+ *
+ * The candidates are the phrases between stop words and punctuation. A
+ * word scores its degree, the number of words of the phrases it is in
+ * counting itself, over its frequency, so words that mostly come in long
+ * phrases score high, and a phrase scores the sum of its words.
+ *
+ *	score(w) = deg(w) / freq(w)
+ *	score(p) = Σ score(w),  w in p
+ */
+func (e *Extractor) RAKE(text string, n int) []Keyword {
+	phrases := e.phrases(e.parse(text))
+	frequency := make(map[string]float64)
+	degree := make(map[string]float64)
+	for _, p := range phrases {
+		for _, t := range p {
+			frequency[t.word]++
+			degree[t.word] += float64(len(p))
+		}
+	}
+
+	scores := make(map[string]float64)
+	for _, p := range phrases {
+		text := phrase(p)
+		if _, ok := scores[text]; ok {
+			continue
+		}
+		for _, t := range p {
+			scores[text] += degree[t.word] / frequency[t.word]
+		}
+	}
+	return top(scores, counts(phrases), n)
+}
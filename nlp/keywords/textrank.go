@@ -0,0 +1,146 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package keywords
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// the damping of PageRank, the chance of following an edge rather than jumping anywhere
+	damping = 0.85
+
+	rankIterations = 100
+	rankTolerance  = 1e-6
+)
+
+/**
+ * TextRank (Mihalcea and Tarau, 2004)
+ *
+ * Builds a graph of the candidate words, linking the words that come within
+ * Window candidates of each other in a sentence, weighted by how often they
+ * do, and ranks the words by PageRank over it. The best third of the words
+ * are kept and those next to each other in the text are joined into
+ * phrases, scoring the sum of the ranks of their words.
+ */
+func (e *Extractor) TextRank(text string, n int) []Keyword {
+	d := e.parse(text)
+	rank := e.rankWords(d)
+	if len(rank) == 0 {
+		return nil
+	}
+
+	words := make([]string, 0, len(rank))
+	for w := range rank {
+		words = append(words, w)
+	}
+	sort.Slice(words, func(i, j int) bool {
+		if rank[words[i]] != rank[words[j]] {
+			return rank[words[i]] > rank[words[j]]
+		}
+		return words[i] < words[j]
+	})
+	keep := (len(words) + 2) / 3
+	kept := make(map[string]bool, keep)
+	for _, w := range words[:keep] {
+		kept[w] = true
+	}
+
+	var phrases [][]token
+	for _, p := range e.phrases(d) {
+		start := 0
+		for i := 0; i <= len(p); i++ {
+			if i == len(p) || !kept[p[i].word] {
+				if i > start {
+					phrases = append(phrases, p[start:i])
+				}
+				start = i + 1
+			}
+		}
+	}
+	scores := make(map[string]float64)
+	for _, p := range phrases {
+		text := phrase(p)
+		if _, ok := scores[text]; ok {
+			continue
+		}
+		for _, t := range p {
+			scores[text] += rank[t.word]
+		}
+	}
+	return top(scores, counts(phrases), n)
+}
+
+// rankWords returns the PageRank of every candidate word in the co-occurrence graph.
+func (e *Extractor) rankWords(d document) map[string]float64 {
+	window := e.window()
+	edges := make(map[string]map[string]float64)
+	link := func(a, b string) {
+		if edges[a] == nil {
+			edges[a] = make(map[string]float64)
+		}
+		edges[a][b]++
+	}
+	var sentence []string
+	flush := func() {
+		for i, a := range sentence {
+			if _, ok := edges[a]; !ok {
+				edges[a] = make(map[string]float64)
+			}
+			for k := i + 1; k <= i+window && k < len(sentence); k++ {
+				if b := sentence[k]; b != a {
+					link(a, b)
+					link(b, a)
+				}
+			}
+		}
+		sentence = nil
+	}
+	for i, t := range d.tokens {
+		if i > 0 && t.sentence != d.tokens[i-1].sentence {
+			flush()
+		}
+		if t.candidate {
+			sentence = append(sentence, t.word)
+		}
+	}
+	flush()
+	if len(edges) == 0 {
+		return nil
+	}
+
+	out := make(map[string]float64, len(edges))
+	rank := make(map[string]float64, len(edges))
+	for w, neighbours := range edges {
+		for _, weight := range neighbours {
+			out[w] += weight
+		}
+		rank[w] = 1
+	}
+	for iteration := 0; iteration < rankIterations; iteration++ {
+		next := make(map[string]float64, len(rank))
+		for w := range rank {
+			next[w] = 1 - damping
+		}
+		for w, neighbours := range edges {
+			for v, weight := range neighbours {
+				next[v] += damping * rank[w] * weight / out[w]
+			}
+		}
+		var change float64
+		for w := range rank {
+			change = math.Max(change, math.Abs(next[w]-rank[w]))
+		}
+		rank = next
+		if change < rankTolerance {
+			break
+		}
+	}
+	return rank
+}
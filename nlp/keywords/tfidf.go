@@ -0,0 +1,53 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package keywords
+
+import (
+	stringUtils "github.com/broosaction/gotext/utils/strings"
+)
+
+/**
+ * TFIDF ranks the phrases by the TF-IDF weight of their words against the
+ * Background corpus, words common in it weighing little. A phrase earns
+ * the share of the weight of each of its words that its occurrences make,
+ *
+ *	score(p) = Σ weight(w) * count(p) / count(w),  w in p
+ *
+ * so a word that mostly comes in the phrase lends it its whole weight. The
+ * text doesn't need to be in the Background. Without one, words weigh by
+ * their frequency in the text.
+ */
+func (e *Extractor) TFIDF(text string, n int) []Keyword {
+	d := e.parse(text)
+	grams := e.ngrams(d)
+	count := counts(grams)
+
+	var weights map[string]float64
+	if e.Background != nil {
+		weights = e.Background.Cal(text)
+	}
+	weight := func(word string) float64 {
+		if weights == nil {
+			return float64(count[word])
+		}
+		// the TFIDF tokenizer strips the words to letters and digits
+		return weights[stringUtils.Cleanup(word)]
+	}
+
+	scores := make(map[string]float64)
+	for _, g := range grams {
+		text := phrase(g)
+		if _, ok := scores[text]; ok {
+			continue
+		}
+		for _, t := range g {
+			scores[text] += weight(t.word) * float64(count[text]) / float64(count[t.word])
+		}
+	}
+	return top(scores, count, n)
+}
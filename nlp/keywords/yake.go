@@ -0,0 +1,153 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package keywords
+
+import (
+	stringUtils "github.com/broosaction/gotext/utils/strings"
+	"math"
+)
+
+// wordStats are the YAKE statistics of a word over the text.
+type wordStats struct {
+	count, upper, acronym int
+	sentences             []int
+	left, right           map[string]int
+	leftTotal, rightTotal int
+}
+
+/**
+ * YAKE, Yet Another Keyword Extractor (Campos et al., 2020)
+ *
+ * Scores every word from statistics of the text alone: how often it is
+ * capitalized inside a sentence or an acronym (case), how early it comes
+ * (position), how frequent it is next to the other words (frequency), how
+ * many different words surround it, as they do stop words (relatedness),
+ * and in how many sentences it is (spread),
+ *
+ *	S(w) = rel * pos / (case + freq / rel + spread / rel)
+ *	S(p) = Π S(w) / (count(p) * (1 + Σ S(w))),  w in p
+ *
+ * where lower is better. The Score of the keywords is 1 / S(p), so higher
+ * is better like the other methods.
+ */
+func (e *Extractor) YAKE(text string, n int) []Keyword {
+	d := e.parse(text)
+	stats := e.wordStats(d)
+	if len(stats) == 0 {
+		return nil
+	}
+
+	var mean, std, maxCount float64
+	for _, s := range stats {
+		mean += float64(s.count)
+		maxCount = math.Max(maxCount, float64(s.count))
+	}
+	mean /= float64(len(stats))
+	for _, s := range stats {
+		std += (float64(s.count) - mean) * (float64(s.count) - mean)
+	}
+	std = math.Sqrt(std / float64(len(stats)))
+
+	weights := make(map[string]float64, len(stats))
+	for word, s := range stats {
+		count := float64(s.count)
+
+		casing := float64(s.upper)
+		if s.acronym > s.upper {
+			casing = float64(s.acronym)
+		}
+		casing /= 1 + math.Log(count)
+
+		// the sentences are in text order
+		median := float64(s.sentences[len(s.sentences)/2])
+		if len(s.sentences)%2 == 0 {
+			median = float64(s.sentences[len(s.sentences)/2-1]+s.sentences[len(s.sentences)/2]) / 2
+		}
+		position := math.Log(math.Log(3 + median))
+
+		frequency := count / (mean + std)
+
+		relatedness := 1 + (dispersion(s.left, s.leftTotal)+dispersion(s.right, s.rightTotal))*count/maxCount
+
+		distinct := 0
+		for i, sentence := range s.sentences {
+			if i == 0 || sentence != s.sentences[i-1] {
+				distinct++
+			}
+		}
+		spread := float64(distinct) / float64(d.sentences)
+
+		weights[word] = relatedness * position / (casing + frequency/relatedness + spread/relatedness)
+	}
+
+	grams := e.ngrams(d)
+	count := counts(grams)
+	scores := make(map[string]float64)
+	for _, g := range grams {
+		text := phrase(g)
+		if _, ok := scores[text]; ok {
+			continue
+		}
+		product, sum := 1.0, 0.0
+		for _, t := range g {
+			product *= weights[t.word]
+			sum += weights[t.word]
+		}
+		s := product / (float64(count[text]) * (1 + sum))
+		if s <= 0 {
+			// a weight can't be 0 but guard the division anyway
+			s = math.SmallestNonzeroFloat64
+		}
+		scores[text] = 1 / s
+	}
+	return top(scores, count, n)
+}
+
+// wordStats counts the YAKE statistics of the candidate words.
+func (e *Extractor) wordStats(d document) map[string]*wordStats {
+	window := e.window()
+	stats := make(map[string]*wordStats)
+	for i, t := range d.tokens {
+		if !t.candidate {
+			continue
+		}
+		s, ok := stats[t.word]
+		if !ok {
+			s = &wordStats{left: map[string]int{}, right: map[string]int{}}
+			stats[t.word] = s
+		}
+		s.count++
+		if stringUtils.IsAcronym(t.text) {
+			s.acronym++
+		} else if isUpper(t.text) && !t.first {
+			s.upper++
+		}
+		s.sentences = append(s.sentences, t.sentence)
+		for k := i - window; k <= i+window; k++ {
+			if k == i || k < 0 || k >= len(d.tokens) || d.tokens[k].sentence != t.sentence || !d.tokens[k].candidate {
+				continue
+			}
+			if k < i {
+				s.left[d.tokens[k].word]++
+				s.leftTotal++
+			} else {
+				s.right[d.tokens[k].word]++
+				s.rightTotal++
+			}
+		}
+	}
+	return stats
+}
+
+// dispersion is the share of different words among the neighbours of a word, 0 without any.
+func dispersion(neighbours map[string]int, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(len(neighbours)) / float64(total)
+}
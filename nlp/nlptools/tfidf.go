@@ -8,14 +8,55 @@
 package nlptools
 
 import (
+	"bytes"
 	"crypto/md5"
+	"encoding/gob"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"github.com/broosaction/gotext/tokenizers"
+	"github.com/broosaction/gotext/utils/persist"
 	stringUtils "github.com/broosaction/gotext/utils/strings"
+	"log"
 
 	"math"
 )
 
+var (
+	errEmptyDocID  = errors.New("a document needs an id")
+	errDuplicateID = errors.New("a document with this id was already added")
+	errNoSuchDoc   = errors.New("no document has this id")
+	errEmptyDoc    = errors.New("the document has no terms")
+)
+
+type TFVariant uint8
+
+const (
+	// the count of the term divided by the number of terms of the document
+	TF_Normalized TFVariant = iota
+	// the count of the term
+	TF_Raw
+	// 1 + ln(count), repeats count less and less
+	TF_Log
+	// 0.5 + 0.5 * count / the count of the most frequent term of the document
+	TF_Augmented
+	// count * (K1 + 1) / (count + K1 * (1 - B + B * length / average length)), saturating as in BM25
+	TF_BM25
+)
+
+type IDFVariant uint8
+
+const (
+	// ln((1 + N) / (1 + df)), a term in every document weighs almost 0
+	IDF_Smooth IDFVariant = iota
+	// ln(N / df)
+	IDF_Standard
+	// ln(1 + (N - df + 0.5) / (df + 0.5)), the probabilistic idf of BM25
+	IDF_BM25
+	// no idf, every term weighs 1
+	IDF_None
+)
+
 /*
 TFIDF is a Term Frequency- Inverse
 Document Frequency model that is created
@@ -30,13 +71,16 @@ word in a document, though, which is
 useful in, say, keyword tagging.
 
 Term frequency is basically just adjusted
-frequency of a word within a document/sentence:
-termFrequency(word, doc) = 0.5 * ( 0.5 * word.Count ) / max{ w.Count | w ∈ doc }
+frequency of a word within a document/sentence,
+by default the share of the document terms it makes:
+termFrequency(word, doc) = word.Count / len(doc)
 
 Inverse document frequency is basically how
 little the term is mentioned within all of
-your documents:
-invDocumentFrequency(word, Docs) = log( len(Docs) ) - log( 1 + |{ d ∈ Docs | t ∈ d}| )
+your documents, by default:
+invDocumentFrequency(word, Docs) = log( 1 + len(Docs) ) - log( 1 + |{ d ∈ Docs | t ∈ d}| )
+
+Both have variants, see TFVariant and IDFVariant.
 
 TFIDF is the multiplication of those two
 functions, giving you a term that is larger
@@ -45,18 +89,27 @@ the word is less important
 */
 // TFIDF tfidf model
 type TFIDF struct {
-	// train document index in TermFreqs
+	// train document index in TermFreqs, by id, the md5 of the text for AddDocs
 	DocIndex  map[string]int
+	// id of each train document, the reverse of DocIndex
+	DocIDs    []string
 	// term frequency for each train document
 	TermFreqs []map[string]int
 	// documents number for each term in train data
 	TermDocs  map[string]int
 	// number of documents in train data
 	N         int
+	// number of terms of all the train documents, for their average length
+	Terms     int
 	// words to be filtered
 	StopWords map[string]struct{}
 	// tokenizer, space is used as default
 	Tokenizer string
+	// how term frequency and inverse document frequency are computed
+	TF        TFVariant
+	IDF       IDFVariant
+	// saturation and length normalization of TF_BM25, 1.2 and 0.75 when 0
+	K1, B     float64
 }
 
 // New new model with default
@@ -110,26 +163,106 @@ func (f *TFIDF) AddStopWordsFile(file string) (err error) {
 	return
 }
 
-// AddDocs add train documents
+// AddDocs add train documents, keyed by the md5 of their text. Documents
+// already added or without terms are skipped.
 func (f *TFIDF) AddDocs(docs ...string) {
 	for _, doc := range docs {
 		h := f.hash(doc)
 		if f.docHashPos(h) >= 0 {
-			return
+			continue
 		}
 
 		termFreq := f.termFreq(doc)
 		if len(termFreq) == 0 {
-			return
+			continue
 		}
 
-		f.DocIndex[h] = f.N
-		f.N++
+		f.add(h, termFreq)
+	}
+}
 
-		f.TermFreqs = append(f.TermFreqs, termFreq)
+// AddDoc adds a train document under the caller's id.
+func (f *TFIDF) AddDoc(id, doc string) error {
+	if id == "" {
+		return errEmptyDocID
+	}
+	if f.docHashPos(id) >= 0 {
+		return fmt.Errorf("%s: %w", id, errDuplicateID)
+	}
+	termFreq := f.termFreq(doc)
+	if len(termFreq) == 0 {
+		return fmt.Errorf("%s: %w", id, errEmptyDoc)
+	}
+	f.add(id, termFreq)
+	return nil
+}
+
+func (f *TFIDF) add(id string, termFreq map[string]int) {
+	f.syncIDs()
+	if f.DocIndex == nil {
+		f.DocIndex = make(map[string]int)
+	}
+	if f.TermDocs == nil {
+		f.TermDocs = make(map[string]int)
+	}
+	f.DocIndex[id] = len(f.TermFreqs)
+	f.DocIDs = append(f.DocIDs, id)
+	f.TermFreqs = append(f.TermFreqs, termFreq)
+	f.N++
 
-		for term := range termFreq {
-			f.TermDocs[term]++
+	for term, freq := range termFreq {
+		f.TermDocs[term]++
+		f.Terms += freq
+	}
+}
+
+// RemoveDoc removes the train document with the id, the md5 of its text for AddDocs.
+func (f *TFIDF) RemoveDoc(id string) error {
+	pos := f.docHashPos(id)
+	if pos < 0 {
+		return fmt.Errorf("%s: %w", id, errNoSuchDoc)
+	}
+	f.syncIDs()
+	for term, freq := range f.TermFreqs[pos] {
+		f.Terms -= freq
+		if f.TermDocs[term]--; f.TermDocs[term] <= 0 {
+			delete(f.TermDocs, term)
+		}
+	}
+
+	// move the last document into the hole
+	last := len(f.TermFreqs) - 1
+	f.TermFreqs[pos] = f.TermFreqs[last]
+	f.DocIDs[pos] = f.DocIDs[last]
+	f.DocIndex[f.DocIDs[pos]] = pos
+	f.TermFreqs = f.TermFreqs[:last]
+	f.DocIDs = f.DocIDs[:last]
+	delete(f.DocIndex, id)
+	f.N--
+	return nil
+}
+
+// RemoveDocs removes train documents by text, the reverse of AddDocs.
+func (f *TFIDF) RemoveDocs(docs ...string) {
+	for _, doc := range docs {
+		f.RemoveDoc(f.hash(doc))
+	}
+}
+
+// syncIDs rebuilds DocIDs for models saved before it existed.
+func (f *TFIDF) syncIDs() {
+	if len(f.DocIDs) == len(f.TermFreqs) {
+		return
+	}
+	f.DocIDs = make([]string, len(f.TermFreqs))
+	for id, pos := range f.DocIndex {
+		f.DocIDs[pos] = id
+	}
+	if f.Terms == 0 {
+		for _, termFreq := range f.TermFreqs {
+			for _, freq := range termFreq {
+				f.Terms += freq
+			}
 		}
 	}
 }
@@ -147,17 +280,38 @@ func (f *TFIDF) Cal(doc string) (weight map[string]float64) {
 		termFreq = f.TermFreqs[docPos]
 	}
 
-	docTerms := 0
+	return f.weigh(termFreq)
+}
+
+// CalID calculates the tf-idf weight of the train document with the id.
+func (f *TFIDF) CalID(id string) (map[string]float64, error) {
+	pos := f.docHashPos(id)
+	if pos < 0 {
+		return nil, fmt.Errorf("%s: %w", id, errNoSuchDoc)
+	}
+	return f.weigh(f.TermFreqs[pos]), nil
+}
+
+func (f *TFIDF) weigh(termFreq map[string]int) map[string]float64 {
+	weight := make(map[string]float64, len(termFreq))
+	docTerms, maxFreq := 0, 0
 	for _, freq := range termFreq {
 		docTerms += freq
+		if freq > maxFreq {
+			maxFreq = freq
+		}
 	}
 	for term, freq := range termFreq {
-		weight[term] = f.tfidf(freq, docTerms, f.TermDocs[term], f.N)
+		weight[term] = f.tf(freq, docTerms, maxFreq) * f.idf(f.TermDocs[term])
 	}
-
 	return weight
 }
 
+// TermFreq returns the number of times each term occurs in doc, stop words excluded
+func (f *TFIDF) TermFreq(doc string) map[string]int {
+	return f.termFreq(doc)
+}
+
 func (f *TFIDF) termFreq(doc string) (m map[string]int) {
 	m = make(map[string]int)
 
@@ -195,8 +349,105 @@ func (f *TFIDF) hash(text string) string {
 	return hex.EncodeToString(h.Sum(nil))
 }
 
-func (f *TFIDF) tfidf(termFreq, docTerms, termDocs, N int) float64 {
-	tf := float64(termFreq) / float64(docTerms)
-	idf := math.Log(float64(1+N) / (1 + float64(termDocs)))
-	return tf * idf
+// tf is the term frequency of a term counted termFreq times in a document of
+// docTerms terms, whose most frequent term is counted maxFreq times.
+func (f *TFIDF) tf(termFreq, docTerms, maxFreq int) float64 {
+	count := float64(termFreq)
+	switch f.TF {
+	case TF_Raw:
+		return count
+	case TF_Log:
+		return 1 + math.Log(count)
+	case TF_Augmented:
+		return 0.5 + 0.5*count/float64(maxFreq)
+	case TF_BM25:
+		k1, b := f.K1, f.B
+		if k1 == 0 {
+			k1 = 1.2
+		}
+		if b == 0 {
+			b = 0.75
+		}
+		norm := 1.0
+		if f.N > 0 && f.Terms > 0 {
+			norm = 1 - b + b*float64(docTerms)/(float64(f.Terms)/float64(f.N))
+		}
+		return count * (k1 + 1) / (count + k1*norm)
+	default:
+		return count / float64(docTerms)
+	}
+}
+
+// idf is the inverse document frequency of a term in termDocs of the N documents.
+func (f *TFIDF) idf(termDocs int) float64 {
+	n, df := float64(f.N), float64(termDocs)
+	switch f.IDF {
+	case IDF_Standard:
+		if df == 0 {
+			// unseen terms are taken as in one document, rather than infinite
+			df = 1
+		}
+		return math.Log(math.Max(n, 1) / df)
+	case IDF_BM25:
+		return math.Log(1 + (n-df+0.5)/(df+0.5))
+	case IDF_None:
+		return 1
+	default:
+		return math.Log((1 + n) / (1 + df))
+	}
+}
+
+func (f *TFIDF) getMeta() (string, string) {
+	return "TFIDF", "01"
+}
+
+//save to a file
+func (f *TFIDF) Save(file string) error {
+	f.syncIDs()
+
+	buf := new(bytes.Buffer)
+	encoder := gob.NewEncoder(buf)
+
+	err := encoder.Encode(f)
+	if err != nil {
+		return fmt.Errorf("error encoding model: %s", err)
+	}
+
+	name, version := f.getMeta()
+	persist.Save(file, persist.Modeldata{
+		Data:    buf.Bytes(),
+		Name:    name,
+		Version: version,
+	})
+	return nil
+}
+
+// Load from the output file.
+func (f *TFIDF) Load(filePath string) error {
+	log.Printf("Loading TFIDF from %s...", filePath)
+	meta := persist.Load(filePath)
+	//get the model current meta data
+	name, version := f.getMeta()
+	if meta.Name != name {
+		return fmt.Errorf("This file doesn't contain a TFIDF model")
+	}
+	if meta.Version != version {
+		return fmt.Errorf("Can't understand this file format")
+	}
+
+	// decode into a fresh model, gob leaves the fields saved as zero values untouched
+	loaded := TFIDF{}
+	decoder := gob.NewDecoder(bytes.NewBuffer(meta.Data))
+	err := decoder.Decode(&loaded)
+	if err != nil {
+		return fmt.Errorf("error decoding checkpoint file: %s", err)
+	}
+	if loaded.DocIndex == nil {
+		loaded.DocIndex = make(map[string]int)
+	}
+	if loaded.TermDocs == nil {
+		loaded.TermDocs = make(map[string]int)
+	}
+	*f = loaded
+	return nil
 }
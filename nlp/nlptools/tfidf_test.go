@@ -0,0 +1,117 @@
+package nlptools
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestAddDocsSkipsWithoutStopping(t *testing.T) {
+	f := NewTFIDF()
+	f.AddDocs("the cat sat", "the cat sat", "", "a dog ran")
+	if f.N != 2 || f.TermDocs["dog"] != 1 || f.TermDocs["cat"] != 1 {
+		t.Errorf("N %d, term docs %v", f.N, f.TermDocs)
+	}
+}
+
+func TestDocIDsAndRemoval(t *testing.T) {
+	f := NewTFIDF()
+	for id, doc := range map[string]string{"a": "red apple", "b": "green apple", "c": "red car"} {
+		if err := f.AddDoc(id, doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.AddDoc("a", "again"); err == nil {
+		t.Error("added an id twice")
+	}
+	if err := f.AddDoc("d", ""); err == nil {
+		t.Error("added an empty document")
+	}
+	before, _ := f.CalID("c")
+
+	if err := f.RemoveDoc("b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.RemoveDoc("b"); err == nil {
+		t.Error("removed twice")
+	}
+	if f.N != 2 || f.TermDocs["apple"] != 1 || f.TermDocs["green"] != 0 || f.Terms != 4 {
+		t.Errorf("N %d, terms %d, term docs %v", f.N, f.Terms, f.TermDocs)
+	}
+	if _, ok := f.TermDocs["green"]; ok {
+		t.Error("a term in no document is left")
+	}
+	after, err := f.CalID("c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// red is now in one of two documents instead of three
+	if !(after["red"] < before["red"]) || after["car"] <= 0 {
+		t.Errorf("weights %v before, %v after removal", before, after)
+	}
+	for id, pos := range f.DocIndex {
+		if f.DocIDs[pos] != id {
+			t.Errorf("%s is at %d, which holds %s", id, pos, f.DocIDs[pos])
+		}
+	}
+
+	f.AddDocs("blue sky")
+	f.RemoveDocs("blue sky")
+	if f.N != 2 {
+		t.Errorf("N %d", f.N)
+	}
+}
+
+func TestVariants(t *testing.T) {
+	f := NewTFIDF()
+	f.AddDoc("a", "rain rain sun")
+	f.AddDoc("b", "snow")
+	weight := func() float64 {
+		w, _ := f.CalID("a")
+		return w["rain"]
+	}
+	smooth := math.Log(3.0 / 2)
+	cases := []struct {
+		tf   TFVariant
+		idf  IDFVariant
+		want float64
+	}{
+		{TF_Normalized, IDF_Smooth, 2.0 / 3 * smooth},
+		{TF_Raw, IDF_Standard, 2 * math.Log(2)},
+		{TF_Log, IDF_None, 1 + math.Log(2)},
+		{TF_Augmented, IDF_Smooth, smooth},
+		{TF_Raw, IDF_BM25, 2 * math.Log(1+1.5/1.5)},
+		// length 3 over an average of 2
+		{TF_BM25, IDF_None, 2 * 2.2 / (2 + 1.2*(0.25+0.75*3/2))},
+	}
+	for _, c := range cases {
+		f.TF, f.IDF = c.tf, c.idf
+		if got := weight(); math.Abs(got-c.want) > 1e-12 {
+			t.Errorf("tf %d idf %d: got %f, want %f", c.tf, c.idf, got, c.want)
+		}
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	f := NewTFIDF()
+	f.TF = TF_Log
+	f.AddDoc("a", "red apple")
+	f.AddDocs("green apple")
+	file := filepath.Join(t.TempDir(), "tfidf.model")
+	if err := f.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewTFIDF()
+	if err := loaded.Load(file); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.N != 2 || loaded.TF != TF_Log || loaded.Terms != 4 {
+		t.Errorf("loaded %+v", loaded)
+	}
+	if loaded.Cal("green apple")["green"] != f.Cal("green apple")["green"] {
+		t.Error("the loaded model weighs differently")
+	}
+	if err := loaded.RemoveDoc("a"); err != nil || loaded.N != 1 {
+		t.Errorf("removing from the loaded model: %v", err)
+	}
+}
@@ -0,0 +1,343 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+// Package search is an in-memory full text search engine. Documents, made
+// of one or more named fields, go into an inverted index with the position
+// of every term, and queries of words, required and excluded words and
+// quoted phrases return the best documents ranked by BM25, or BM25F when
+// fields weigh differently. The index can be saved and loaded.
+package search
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/broosaction/gotext/tokenizers"
+	"github.com/broosaction/gotext/utils/persist"
+	"log"
+	"sort"
+	"strings"
+)
+
+// DefaultField is the field of the documents added as a single text.
+const DefaultField = "body"
+
+var (
+	errEmptyID     = errors.New("a document needs an id")
+	errNoSuchDoc   = errors.New("no document has this id")
+	errEmptyFields = errors.New("a document needs at least one field")
+)
+
+// FieldOptions tune how a field counts in BM25F.
+type FieldOptions struct {
+	// How much a match in the field is worth next to one in a field of
+	// weight 1, 0 weighs 1.
+	Weight float64
+
+	// How much the field length normalizes its term counts, up to 1 (fully),
+	// the b of BM25. 0 takes the index B, a negative value turns it off.
+	B float64
+}
+
+// Posting is the occurrences of a term in one document.
+type Posting struct {
+	// Position of the document in Docs.
+	Doc int
+
+	// The positions of the term in every field having it.
+	Positions map[string][]int
+}
+
+// Document is what the index keeps of an added document.
+type Document struct {
+	ID string
+
+	// The number of terms of every field.
+	Lengths map[string]int
+
+	// The distinct terms of the document, to remove its postings.
+	Terms []string
+
+	// Deleted documents keep their position so postings stay valid, until Compact.
+	Deleted bool
+}
+
+/**
+ * Inverted Index
+ *
+ * Maps every term to the documents having it and where. Documents are
+ * identified by the caller's ids, adding an id again replaces the document.
+ * Queries are scored with BM25F: the counts of a term in every field are
+ * normalized by the field length, weighed and summed before saturating,
+ *
+ *	score(d, q) = Σ idf(t) * tf(t, d) / (K1 + tf(t, d)),  t in q
+ *	tf(t, d)    = Σ w(f) * count(t, d, f) / (1 - b(f) + b(f) * len(d, f) / avglen(f))
+ *	idf(t)      = ln(1 + (N - df(t) + 0.5) / (df(t) + 0.5))
+ *
+ * which, for a single field of weight 1, is plain BM25.
+ *
+ * @category    Information Retrieval
+
+  **usage
+	ix := search.NewIndex()
+	ix.Fields["title"] = search.FieldOptions{Weight: 3}
+	ix.AddFields("faq-1", map[string]string{"title": "Reset my password", "body": "Open the settings ..."})
+	ix.Add("faq-2", "How do I close my account?")
+	for _, hit := range ix.Search(`password +reset -"admin account"`, 10) {
+		fmt.Println(hit.ID, hit.Score)
+	}
+*/
+type Index struct {
+	// name of the tokenizer, see tokenizers.GetTokenizer
+	Tokenizer string
+
+	// words left out of the index and of the queries
+	StopWords map[string]struct{}
+
+	// The saturation of the term counts, counts beyond a few K1 add little.
+	K1 float64
+
+	// The length normalization of fields without options.
+	B float64
+
+	// The options of every field, fields not in the map weigh 1.
+	Fields map[string]FieldOptions
+
+	// The postings of every term, sorted by document.
+	Postings map[string][]Posting
+
+	// The documents, by position, and the position of every id.
+	Docs []Document
+	IDs  map[string]int
+
+	// The total length of every field over the documents, for the average.
+	TotalLengths map[string]int
+}
+
+func NewIndex() *Index {
+	return &Index{
+		Tokenizer:    tokenizers.DefaultTokenizerName,
+		K1:           1.2,
+		B:            0.75,
+		Fields:       map[string]FieldOptions{},
+		Postings:     map[string][]Posting{},
+		IDs:          map[string]int{},
+		TotalLengths: map[string]int{},
+	}
+}
+
+func (ix *Index) getMeta() (string, string) {
+	return "SearchIndex", "01"
+}
+
+// Len is the number of documents in the index.
+func (ix *Index) Len() int {
+	return len(ix.IDs)
+}
+
+// terms tokenizes and lower cases a text, leaving out the stop words.
+func (ix *Index) terms(text string) []string {
+	var terms []string
+	for _, token := range tokenizers.GetTokenizer(ix.Tokenizer).Tokenize(strings.ToLower(text)) {
+		if _, ok := ix.StopWords[token]; !ok {
+			terms = append(terms, token)
+		}
+	}
+	return terms
+}
+
+// Add indexes a text under id in DefaultField.
+func (ix *Index) Add(id, text string) error {
+	return ix.AddFields(id, map[string]string{DefaultField: text})
+}
+
+/**
+ * AddDocs indexes texts under the hash of their text, like TFIDF.AddDocs,
+ * and returns the ids. A text already in the index is skipped.
+ */
+func (ix *Index) AddDocs(docs ...string) []string {
+	ids := make([]string, len(docs))
+	for i, doc := range docs {
+		h := md5.Sum([]byte(doc))
+		ids[i] = hex.EncodeToString(h[:])
+		if _, ok := ix.IDs[ids[i]]; ok {
+			continue
+		}
+		ix.Add(ids[i], doc)
+	}
+	return ids
+}
+
+// AddFields indexes a document made of named fields under id, replacing any document with that id.
+func (ix *Index) AddFields(id string, fields map[string]string) error {
+	if id == "" {
+		return errEmptyID
+	}
+	if len(fields) == 0 {
+		return errEmptyFields
+	}
+	if _, ok := ix.IDs[id]; ok {
+		ix.Delete(id)
+	}
+	ix.init()
+
+	doc := Document{ID: id, Lengths: make(map[string]int, len(fields))}
+	at := len(ix.Docs)
+	postings := make(map[string]*Posting)
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		terms := ix.terms(fields[name])
+		doc.Lengths[name] = len(terms)
+		ix.TotalLengths[name] += len(terms)
+		for position, term := range terms {
+			p, ok := postings[term]
+			if !ok {
+				p = &Posting{Doc: at, Positions: map[string][]int{}}
+				postings[term] = p
+				doc.Terms = append(doc.Terms, term)
+			}
+			p.Positions[name] = append(p.Positions[name], position)
+		}
+	}
+	// documents are appended, so the postings stay sorted by document
+	for term, p := range postings {
+		ix.Postings[term] = append(ix.Postings[term], *p)
+	}
+	ix.Docs = append(ix.Docs, doc)
+	ix.IDs[id] = at
+	return nil
+}
+
+// Delete removes the document with the id from the index.
+func (ix *Index) Delete(id string) error {
+	at, ok := ix.IDs[id]
+	if !ok {
+		return fmt.Errorf("%s: %w", id, errNoSuchDoc)
+	}
+	doc := &ix.Docs[at]
+	for _, term := range doc.Terms {
+		postings := ix.Postings[term]
+		k := sort.Search(len(postings), func(i int) bool { return postings[i].Doc >= at })
+		if k < len(postings) && postings[k].Doc == at {
+			postings = append(postings[:k], postings[k+1:]...)
+		}
+		if len(postings) == 0 {
+			delete(ix.Postings, term)
+		} else {
+			ix.Postings[term] = postings
+		}
+	}
+	for name, n := range doc.Lengths {
+		ix.TotalLengths[name] -= n
+	}
+	doc.Terms, doc.Lengths, doc.Deleted = nil, nil, true
+	delete(ix.IDs, id)
+	return nil
+}
+
+/**
+ * Compact drops the deleted documents from Docs and renumbers the postings
+ * of the others, which keep their order. Replacing or deleting documents
+ * leaves their place behind until then, Save compacts before writing.
+ */
+func (ix *Index) Compact() {
+	renumber := make([]int, len(ix.Docs))
+	docs := ix.Docs[:0]
+	for i, doc := range ix.Docs {
+		if doc.Deleted {
+			renumber[i] = -1
+			continue
+		}
+		renumber[i] = len(docs)
+		docs = append(docs, doc)
+	}
+	if len(docs) == len(ix.Docs) {
+		return
+	}
+	// clear the tail so the dropped documents can be collected
+	for i := len(docs); i < len(ix.Docs); i++ {
+		ix.Docs[i] = Document{}
+	}
+	ix.Docs = docs
+	for id, at := range ix.IDs {
+		ix.IDs[id] = renumber[at]
+	}
+	// deleted documents have no postings left, and the order of the others is kept
+	for _, postings := range ix.Postings {
+		for i := range postings {
+			postings[i].Doc = renumber[postings[i].Doc]
+		}
+	}
+}
+
+func (ix *Index) init() {
+	if ix.Fields == nil {
+		ix.Fields = map[string]FieldOptions{}
+	}
+	if ix.Postings == nil {
+		ix.Postings = map[string][]Posting{}
+	}
+	if ix.IDs == nil {
+		ix.IDs = map[string]int{}
+	}
+	if ix.TotalLengths == nil {
+		ix.TotalLengths = map[string]int{}
+	}
+}
+
+//save to a file, compacted
+func (ix *Index) Save(file string) error {
+	ix.Compact()
+
+	buf := new(bytes.Buffer)
+	encoder := gob.NewEncoder(buf)
+
+	err := encoder.Encode(ix)
+	if err != nil {
+		return fmt.Errorf("error encoding index: %s", err)
+	}
+
+	name, version := ix.getMeta()
+	persist.Save(file, persist.Modeldata{
+		Data:    buf.Bytes(),
+		Name:    name,
+		Version: version,
+	})
+	return nil
+}
+
+// Load from the output file.
+func (ix *Index) Load(filePath string) error {
+	log.Printf("Loading Index from %s...", filePath)
+	meta := persist.Load(filePath)
+	//get the index current meta data
+	name, version := ix.getMeta()
+	if meta.Name != name {
+		return fmt.Errorf("This file doesn't contain a search index")
+	}
+	if meta.Version != version {
+		return fmt.Errorf("Can't understand this file format")
+	}
+
+	// decode into a fresh index, gob leaves the fields saved as zero values untouched
+	var loaded Index
+	decoder := gob.NewDecoder(bytes.NewBuffer(meta.Data))
+	err := decoder.Decode(&loaded)
+	if err != nil {
+		return fmt.Errorf("error decoding checkpoint file: %s", err)
+	}
+	loaded.init()
+	*ix = loaded
+	return nil
+}
@@ -0,0 +1,305 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package search
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+	"strings"
+)
+
+/**
+ * Query
+ *
+ * A document matches when it has every Must term and phrase, none of the
+ * MustNot terms and NotPhrases, and, when there is nothing it must have,
+ * at least one Should term. Matches are ranked by the BM25F score of all
+ * their terms.
+ */
+type Query struct {
+	Should  []string
+	Must    []string
+	MustNot []string
+
+	// Phrases are terms that must follow each other in one field, a
+	// document must have every phrase and none of the NotPhrases.
+	Phrases    [][]string
+	NotPhrases [][]string
+}
+
+/**
+ * ParseQuery reads a query string: words are optional, +word or AND word
+ * required, -word or NOT word excluded, and "quoted words" a phrase. OR
+ * between words is the default and can be left out.
+ *
+ *	reset password           either word
+ *	+reset password          reset, ranked higher with password
+ *	reset AND password       both
+ *	"reset password" -admin  the phrase and not admin
+ */
+func (ix *Index) ParseQuery(query string) Query {
+	var q Query
+	next := "" // the operator before the next word
+	for len(query) > 0 {
+		query = strings.TrimLeft(query, " \t\n")
+		if query == "" {
+			break
+		}
+		prefix := next
+		next = ""
+		if query[0] == '+' || query[0] == '-' {
+			prefix = query[:1]
+			query = query[1:]
+		}
+
+		if strings.HasPrefix(query, `"`) {
+			end := strings.Index(query[1:], `"`)
+			var phrase string
+			if end < 0 {
+				phrase, query = query[1:], ""
+			} else {
+				phrase, query = query[1:end+1], query[end+2:]
+			}
+			terms := ix.terms(phrase)
+			switch {
+			case len(terms) == 0:
+			case prefix == "-":
+				q.NotPhrases = append(q.NotPhrases, terms)
+			case len(terms) == 1 && prefix != "+":
+				q.Should = append(q.Should, terms[0])
+			default:
+				q.Phrases = append(q.Phrases, terms)
+			}
+			continue
+		}
+
+		word := query
+		if end := strings.IndexAny(query, " \t\n"); end >= 0 {
+			word, query = query[:end], query[end:]
+		} else {
+			query = ""
+		}
+		switch word {
+		case "AND":
+			next = "+"
+			// the word before an AND is required too
+			if n := len(q.Should); n > 0 && prefix == "" {
+				q.Must = append(q.Must, q.Should[n-1])
+				q.Should = q.Should[:n-1]
+			}
+			continue
+		case "NOT":
+			next = "-"
+			continue
+		case "OR":
+			continue
+		}
+		for _, term := range ix.terms(word) {
+			switch prefix {
+			case "+":
+				q.Must = append(q.Must, term)
+			case "-":
+				q.MustNot = append(q.MustNot, term)
+			default:
+				q.Should = append(q.Should, term)
+			}
+		}
+	}
+	return q
+}
+
+// Hit is a document found by a search.
+type Hit struct {
+	ID    string
+	Score float64
+}
+
+// Search parses the query and returns the k best documents, best first. k <= 0 returns all matches.
+func (ix *Index) Search(query string, k int) []Hit {
+	return ix.SearchQuery(ix.ParseQuery(query), k)
+}
+
+// SearchQuery returns the k best documents for the query, best first. k <= 0 returns all matches.
+func (ix *Index) SearchQuery(q Query, k int) []Hit {
+	var required [][]string
+	for _, term := range q.Must {
+		required = append(required, []string{term})
+	}
+	required = append(required, q.Phrases...)
+
+	var candidates map[int]bool
+	if len(required) > 0 {
+		for _, phrase := range required {
+			matches := ix.phraseDocs(phrase)
+			if candidates == nil {
+				candidates = matches
+				continue
+			}
+			for doc := range candidates {
+				if !matches[doc] {
+					delete(candidates, doc)
+				}
+			}
+		}
+	} else {
+		candidates = make(map[int]bool)
+		for _, term := range q.Should {
+			for _, p := range ix.Postings[term] {
+				candidates[p.Doc] = true
+			}
+		}
+	}
+	for _, term := range q.MustNot {
+		for _, p := range ix.Postings[term] {
+			delete(candidates, p.Doc)
+		}
+	}
+	for _, phrase := range q.NotPhrases {
+		for doc := range ix.phraseDocs(phrase) {
+			delete(candidates, doc)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	var terms []string
+	terms = append(terms, q.Should...)
+	terms = append(terms, q.Must...)
+	for _, phrase := range q.Phrases {
+		terms = append(terms, phrase...)
+	}
+	scores := ix.score(terms, candidates)
+
+	h := &hits{}
+	for doc := range candidates {
+		hit := Hit{ID: ix.Docs[doc].ID, Score: scores[doc]}
+		if k > 0 && h.Len() == k {
+			if !better(hit, (*h)[0]) {
+				continue
+			}
+			heap.Pop(h)
+		}
+		heap.Push(h, hit)
+	}
+	result := make([]Hit, h.Len())
+	for i := len(result) - 1; i >= 0; i-- {
+		result[i] = heap.Pop(h).(Hit)
+	}
+	return result
+}
+
+// score returns the BM25F score of the candidates for the terms, a term repeated in the query counting again.
+func (ix *Index) score(terms []string, candidates map[int]bool) map[int]float64 {
+	n := float64(ix.Len())
+	scores := make(map[int]float64, len(candidates))
+	for _, term := range terms {
+		postings := ix.Postings[term]
+		if len(postings) == 0 {
+			continue
+		}
+		df := float64(len(postings))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		for _, p := range postings {
+			if !candidates[p.Doc] {
+				continue
+			}
+			var tf float64
+			for field, positions := range p.Positions {
+				weight, b := ix.fieldOptions(field)
+				norm := 1.0
+				if avg := float64(ix.TotalLengths[field]) / n; b > 0 && avg > 0 {
+					norm = 1 - b + b*float64(ix.Docs[p.Doc].Lengths[field])/avg
+				}
+				tf += weight * float64(len(positions)) / norm
+			}
+			scores[p.Doc] += idf * tf / (ix.K1 + tf)
+		}
+	}
+	return scores
+}
+
+func (ix *Index) fieldOptions(field string) (float64, float64) {
+	o := ix.Fields[field]
+	weight, b := o.Weight, o.B
+	if weight == 0 {
+		weight = 1
+	}
+	if b == 0 {
+		b = ix.B
+	}
+	return weight, b
+}
+
+// phraseDocs returns the documents having the terms next to each other in one field.
+func (ix *Index) phraseDocs(phrase []string) map[int]bool {
+	docs := make(map[int]bool)
+	if len(phrase) == 0 {
+		return docs
+	}
+	// positions of every later term, by document
+	later := make([]map[int]Posting, len(phrase)-1)
+	for i, term := range phrase[1:] {
+		later[i] = make(map[int]Posting, len(ix.Postings[term]))
+		for _, p := range ix.Postings[term] {
+			later[i][p.Doc] = p
+		}
+	}
+	for _, first := range ix.Postings[phrase[0]] {
+	fields:
+		for field, starts := range first.Positions {
+			for _, start := range starts {
+				if followed(later, first.Doc, field, start) {
+					docs[first.Doc] = true
+					break fields
+				}
+			}
+		}
+	}
+	return docs
+}
+
+// followed tells whether every later term of a phrase comes right after start.
+func followed(later []map[int]Posting, doc int, field string, start int) bool {
+	for i, postings := range later {
+		p, ok := postings[doc]
+		if !ok || !contains(p.Positions[field], start+i+1) {
+			return false
+		}
+	}
+	return true
+}
+
+// contains searches the sorted positions.
+func contains(positions []int, position int) bool {
+	at := sort.SearchInts(positions, position)
+	return at < len(positions) && positions[at] == position
+}
+
+// better ranks by score, then by id so equal scores come out in a stable order.
+func better(a, b Hit) bool {
+	if a.Score != b.Score {
+		return a.Score > b.Score
+	}
+	return a.ID < b.ID
+}
+
+// hits is a min-heap of the best hits so far, the worst on top.
+type hits []Hit
+
+func (h hits) Len() int            { return len(h) }
+func (h hits) Less(i, j int) bool  { return better(h[j], h[i]) }
+func (h hits) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *hits) Push(x interface{}) { *h = append(*h, x.(Hit)) }
+func (h *hits) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
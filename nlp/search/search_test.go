@@ -0,0 +1,157 @@
+package search
+
+import (
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func faq() *Index {
+	ix := NewIndex()
+	ix.Add("reset", "How do I reset my password? Open the settings and choose reset password.")
+	ix.Add("close", "How do I close my account? Write to support to close the account.")
+	ix.Add("admin", "Admin accounts can reset the password of any account.")
+	ix.Add("billing", "Where do I find my invoices? Invoices are under billing.")
+	return ix
+}
+
+func ids(hits []Hit) []string {
+	out := make([]string, len(hits))
+	for i, h := range hits {
+		out[i] = h.ID
+	}
+	return out
+}
+
+func TestBM25(t *testing.T) {
+	ix := NewIndex()
+	ix.Add("a", "cat cat dog")
+	ix.Add("b", "dog bird")
+	hits := ix.Search("cat", 0)
+	if len(hits) != 1 || hits[0].ID != "a" {
+		t.Fatalf("hits %v", hits)
+	}
+	// tf 2, length 3 over an average of 2.5, idf of a term in 1 of 2 documents
+	tf := 2 / (1 - 0.75 + 0.75*3/2.5)
+	want := math.Log(1+(2-1+0.5)/(1+0.5)) * tf / (1.2 + tf)
+	if math.Abs(hits[0].Score-want) > 1e-12 {
+		t.Errorf("score %f, want %f", hits[0].Score, want)
+	}
+}
+
+func TestQueries(t *testing.T) {
+	ix := faq()
+	cases := map[string][]string{
+		"password":                   {"reset", "admin"},
+		"invoices OR account":        {"billing", "close", "admin"},
+		"+account password":          {"admin", "close"},
+		"reset AND account":          {"admin"},
+		"password -admin":            {"reset"},
+		"password NOT admin":         {"reset"},
+		`"reset password"`:           {"reset"},
+		`password -"reset password"`: {"admin"},
+		`"password reset"`:           nil,
+		"unknown":                    nil,
+	}
+	for query, want := range cases {
+		got := ids(ix.Search(query, 0))
+		if len(got) == 0 && len(want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %v, want %v", query, got, want)
+		}
+	}
+	if hits := ix.Search("password account invoices", 2); len(hits) != 2 || hits[0].Score < hits[1].Score {
+		t.Errorf("top 2 %v", hits)
+	}
+}
+
+func TestFieldsAndDelete(t *testing.T) {
+	ix := NewIndex()
+	ix.Fields["title"] = FieldOptions{Weight: 5}
+	ix.AddFields("1", map[string]string{"title": "shipping times", "body": "orders leave in two days"})
+	ix.AddFields("2", map[string]string{"title": "returns", "body": "send it back, shipping is free for returns"})
+	if got := ids(ix.Search("shipping", 0)); !reflect.DeepEqual(got, []string{"1", "2"}) {
+		t.Errorf("the title match should rank first: %v", got)
+	}
+
+	if err := ix.Delete("1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ix.Delete("1"); err == nil {
+		t.Error("deleted twice")
+	}
+	if got := ids(ix.Search("shipping", 0)); !reflect.DeepEqual(got, []string{"2"}) || ix.Len() != 1 {
+		t.Errorf("after delete %v", got)
+	}
+	if _, ok := ix.Postings["times"]; ok {
+		t.Error("the postings of the deleted document are left")
+	}
+	ix.Add("2", "replaced text")
+	if len(ix.Search("returns", 0)) != 0 || len(ix.Search("replaced", 0)) != 1 {
+		t.Error("adding an id again should replace the document")
+	}
+
+	added := ix.AddDocs("one text", "one text", "another text")
+	if ix.Len() != 3 || added[0] != added[1] {
+		t.Errorf("%d documents, ids %v", ix.Len(), added)
+	}
+}
+
+func TestCompact(t *testing.T) {
+	ix := faq()
+	ix.Delete("reset")
+	ix.Add("close", "How do I close my account? Write to support to close the account.")
+	ix.Add("reset", "Reset your password from the settings.")
+	if len(ix.Docs) != 6 {
+		t.Fatalf("%d documents before compacting", len(ix.Docs))
+	}
+	want := ix.Search("password account", 0)
+
+	ix.Compact()
+	if len(ix.Docs) != 4 || ix.Len() != 4 {
+		t.Errorf("%d documents, %d ids after compacting", len(ix.Docs), ix.Len())
+	}
+	for id, at := range ix.IDs {
+		if ix.Docs[at].ID != id || ix.Docs[at].Deleted {
+			t.Errorf("%s at %d is %+v", id, at, ix.Docs[at])
+		}
+	}
+	for term, postings := range ix.Postings {
+		for i, p := range postings {
+			if p.Doc >= len(ix.Docs) || (i > 0 && postings[i-1].Doc >= p.Doc) {
+				t.Errorf("%s: postings %v", term, postings)
+				break
+			}
+		}
+	}
+	if got := ix.Search("password account", 0); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := ids(ix.Search(`"close the account"`, 0)); !reflect.DeepEqual(got, []string{"close"}) {
+		t.Errorf("phrase after compacting %v", got)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	ix := faq()
+	ix.Delete("billing")
+	file := filepath.Join(t.TempDir(), "index.model")
+	if err := ix.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewIndex()
+	if err := loaded.Load(file); err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Docs) != 3 {
+		t.Errorf("%d documents saved, the deleted one is kept", len(loaded.Docs))
+	}
+	for _, query := range []string{"password", `"close the account"`, "invoices"} {
+		if a, b := ix.Search(query, 0), loaded.Search(query, 0); !reflect.DeepEqual(a, b) {
+			t.Errorf("%s: %v before, %v after loading", query, a, b)
+		}
+	}
+}
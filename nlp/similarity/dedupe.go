@@ -0,0 +1,246 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package similarity
+
+import (
+	"sort"
+	"strconv"
+)
+
+type DedupeMethod uint8
+
+const (
+	// MinHash signatures banded by LSH, pairs checked by the Jaccard similarity of their shingles
+	DM_MinHash DedupeMethod = iota
+	// SimHash fingerprints, pairs checked by their Hamming distance
+	DM_SimHash
+)
+
+// Pair is two near duplicate texts, by position, A before B.
+type Pair struct {
+	A, B int
+
+	// The Jaccard similarity of their shingles for MinHash, the share of
+	// equal fingerprint bits for SimHash.
+	Similarity float64
+}
+
+/**
+ * Deduper
+ *
+ * Finds the near duplicates of a corpus without comparing every pair of
+ * texts. With MinHash, texts sharing an LSH bucket are compared by the
+ * Jaccard similarity of their shingles and kept from Threshold, the Bands
+ * and Rows setting how likely a pair at the threshold is to be found, see
+ * LSH. With SimHash, fingerprints are cut into Distance + 1 blocks, two
+ * fingerprints at most Distance bits apart having one block in common,
+ * and texts sharing a block are kept when their fingerprints are.
+ *
+ * MinHash is the more accurate, SimHash the lighter, a fingerprint being
+ * a single number.
+ *
+ * @category    Natural Language Processing
+
+  **usage
+	d := similarity.NewDeduper()
+	d.Threshold = 0.9
+	for _, i := range d.Unique(scraped) {
+		train = append(train, scraped[i])
+	}
+*/
+type Deduper struct {
+	Method DedupeMethod
+
+	// The words per shingle, 3 by default.
+	Shingle int
+
+	// The least Jaccard similarity of MinHash pairs, 0.8 by default.
+	Threshold float64
+
+	// The LSH banding of the MinHash signatures, 16 bands of 8 rows by default.
+	Bands, Rows int
+
+	// Seed of the MinHash functions.
+	Seed int64
+
+	// The most bits the fingerprints of SimHash pairs differ by, 3 by default.
+	Distance int
+}
+
+func NewDeduper() *Deduper {
+	return &Deduper{
+		Method:    DM_MinHash,
+		Shingle:   3,
+		Threshold: 0.8,
+		Bands:     16,
+		Rows:      8,
+		Distance:  3,
+	}
+}
+
+// Pairs returns every pair of near duplicate texts, sorted by position.
+func (d *Deduper) Pairs(texts []string) []Pair {
+	var pairs []Pair
+	if d.Method == DM_SimHash {
+		pairs = d.simHashPairs(texts)
+	} else {
+		pairs = d.minHashPairs(texts)
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].A != pairs[j].A {
+			return pairs[i].A < pairs[j].A
+		}
+		return pairs[i].B < pairs[j].B
+	})
+	return pairs
+}
+
+// Groups returns the groups of two or more texts linked by near duplicate pairs, each sorted, by their first text.
+func (d *Deduper) Groups(texts []string) [][]int {
+	return groups(len(texts), d.Pairs(texts))
+}
+
+// Unique returns the positions of the texts to keep, the first of every group and every text without duplicate.
+func (d *Deduper) Unique(texts []string) []int {
+	dropped := make([]bool, len(texts))
+	for _, group := range d.Groups(texts) {
+		for _, i := range group[1:] {
+			dropped[i] = true
+		}
+	}
+	var kept []int
+	for i := range texts {
+		if !dropped[i] {
+			kept = append(kept, i)
+		}
+	}
+	return kept
+}
+
+func (d *Deduper) minHashPairs(texts []string) []Pair {
+	shingle, bands, rows := d.Shingle, d.Bands, d.Rows
+	if shingle <= 0 {
+		shingle = 3
+	}
+	if bands <= 0 || rows <= 0 {
+		bands, rows = 16, 8
+	}
+	mh := NewMinHash(bands*rows, d.Seed)
+	lsh := NewLSH(bands, rows)
+	sets := make([]Set, len(texts))
+	for i, text := range texts {
+		sets[i] = Shingles(text, shingle)
+		if len(sets[i]) == 0 {
+			continue
+		}
+		// the signature has bands * rows hashes, so Add can't fail
+		lsh.Add(strconv.Itoa(i), mh.Signature(sets[i]))
+	}
+
+	var pairs []Pair
+	for _, c := range lsh.Candidates() {
+		a, _ := strconv.Atoi(c[0])
+		b, _ := strconv.Atoi(c[1])
+		if a > b {
+			a, b = b, a
+		}
+		if sim := Jaccard(sets[a], sets[b]); sim >= d.Threshold {
+			pairs = append(pairs, Pair{A: a, B: b, Similarity: sim})
+		}
+	}
+	return pairs
+}
+
+func (d *Deduper) simHashPairs(texts []string) []Pair {
+	shingle, distance := d.Shingle, d.Distance
+	if shingle <= 0 {
+		shingle = 3
+	}
+	if distance < 0 {
+		distance = 3
+	}
+	fingerprints := make([]uint64, len(texts))
+	empty := make([]bool, len(texts))
+	for i, text := range texts {
+		fingerprints[i] = SimHashText(text, shingle)
+		empty[i] = len(Tokens(text)) == 0
+	}
+
+	// two fingerprints at most distance bits apart agree on one of distance + 1 blocks
+	blocks := distance + 1
+	if blocks > 64 {
+		blocks = 64
+	}
+	seen := make(map[[2]int]bool)
+	var pairs []Pair
+	for b := 0; b < blocks; b++ {
+		from, to := b*64/blocks, (b+1)*64/blocks
+		mask := uint64(1)<<uint(to-from) - 1
+		if to-from == 64 {
+			mask = ^uint64(0)
+		}
+		buckets := make(map[uint64][]int)
+		for i, fp := range fingerprints {
+			if !empty[i] {
+				key := fp >> uint(from) & mask
+				buckets[key] = append(buckets[key], i)
+			}
+		}
+		for _, bucket := range buckets {
+			for x := 0; x < len(bucket); x++ {
+				for y := x + 1; y < len(bucket); y++ {
+					pair := [2]int{bucket[x], bucket[y]}
+					if seen[pair] {
+						continue
+					}
+					seen[pair] = true
+					if h := Hamming(fingerprints[pair[0]], fingerprints[pair[1]]); h <= distance {
+						pairs = append(pairs, Pair{A: pair[0], B: pair[1], Similarity: 1 - float64(h)/64})
+					}
+				}
+			}
+		}
+	}
+	return pairs
+}
+
+// groups returns the connected components of two or more of the n texts linked by the pairs.
+func groups(n int, pairs []Pair) [][]int {
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for _, p := range pairs {
+		a, b := find(p.A), find(p.B)
+		if a < b {
+			parent[b] = a
+		} else if b < a {
+			parent[a] = b
+		}
+	}
+	members := make(map[int][]int)
+	for i := 0; i < n; i++ {
+		root := find(i)
+		members[root] = append(members[root], i)
+	}
+	var out [][]int
+	for _, group := range members {
+		if len(group) > 1 {
+			out = append(out, group)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i][0] < out[j][0] })
+	return out
+}
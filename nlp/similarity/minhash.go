@@ -0,0 +1,243 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package similarity
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+var (
+	errSignatureSize = errors.New("the signature size doesn't match bands * rows")
+)
+
+// Signature is the MinHash signature of a set, the smallest hash of its members under every hash function.
+type Signature []uint64
+
+// Similarity estimates the Jaccard similarity of the sets of two signatures
+// of the same MinHash, the share of hash functions where their minimums agree.
+func (s Signature) Similarity(o Signature) float64 {
+	n := len(s)
+	if len(o) < n {
+		n = len(o)
+	}
+	if n == 0 {
+		return 0
+	}
+	same := 0
+	for i := 0; i < n; i++ {
+		if s[i] == o[i] {
+			same++
+		}
+	}
+	return float64(same) / float64(n)
+}
+
+/**
+ * MinHash
+ *
+ * Hashes a set with many hash functions and keeps the smallest hash of
+ * each. The chance that two sets share the minimum of a hash function is
+ * their Jaccard similarity, so comparing signatures of a fixed size
+ * estimates it, with an error around 1 / sqrt(hashes). Signatures can only
+ * be compared when made with the same seeds.
+ *
+ * @category    Natural Language Processing
+
+  **usage
+	mh := similarity.NewMinHash(128, 1)
+	a := mh.Signature(similarity.Shingles(first, 3))
+	b := mh.Signature(similarity.Shingles(second, 3))
+	fmt.Println(a.Similarity(b))
+*/
+type MinHash struct {
+	// One seed per hash function.
+	Seeds []uint64
+}
+
+func NewMinHash(hashes int, seed int64) *MinHash {
+	r := rand.New(rand.NewSource(seed))
+	seeds := make([]uint64, hashes)
+	for i := range seeds {
+		seeds[i] = r.Uint64()
+	}
+	return &MinHash{Seeds: seeds}
+}
+
+// Signature returns the MinHash signature of the set, all math.MaxUint64 for the empty set.
+func (m *MinHash) Signature(set Set) Signature {
+	sig := make(Signature, len(m.Seeds))
+	for i := range sig {
+		sig[i] = math.MaxUint64
+	}
+	for s := range set {
+		h := hash64(s)
+		for i, seed := range m.Seeds {
+			if v := mix(h ^ seed); v < sig[i] {
+				sig[i] = v
+			}
+		}
+	}
+	return sig
+}
+
+/**
+ * LSH, Locality Sensitive Hashing of MinHash signatures
+ *
+ * Cuts every signature into Bands of Rows hashes and puts it in one bucket
+ * per band. Signatures sharing a bucket are candidates, which two sets of
+ * Jaccard similarity s are with a chance of 1 - (1 - s^Rows)^Bands, an S
+ * curve rising around (1 / Bands)^(1 / Rows). More rows make candidates
+ * stricter, more bands looser.
+ *
+ * @category    Natural Language Processing
+
+  **usage
+	mh := similarity.NewMinHash(128, 1)
+	lsh := similarity.NewLSH(32, 4)
+	for id, text := range texts {
+		lsh.Add(id, mh.Signature(similarity.Shingles(text, 3)))
+	}
+	fmt.Println(lsh.Query(mh.Signature(similarity.Shingles(query, 3))))
+*/
+type LSH struct {
+	Bands, Rows int
+
+	// The signature of every id added.
+	Signatures map[string]Signature
+
+	// The ids in every bucket of every band.
+	buckets []map[uint64][]string
+}
+
+func NewLSH(bands, rows int) *LSH {
+	return &LSH{Bands: bands, Rows: rows, Signatures: map[string]Signature{}}
+}
+
+// Threshold is the similarity at which sets become likely candidates, (1 / Bands)^(1 / Rows).
+func (l *LSH) Threshold() float64 {
+	return math.Pow(1/float64(l.Bands), 1/float64(l.Rows))
+}
+
+// Add puts the signature of id in its buckets, the signature must have Bands * Rows hashes.
+func (l *LSH) Add(id string, sig Signature) error {
+	if len(sig) != l.Bands*l.Rows {
+		return fmt.Errorf("%d hashes for %d bands of %d rows: %w", len(sig), l.Bands, l.Rows, errSignatureSize)
+	}
+	if l.Signatures == nil {
+		l.Signatures = make(map[string]Signature)
+	}
+	if _, ok := l.Signatures[id]; ok {
+		l.remove(id)
+	}
+	if l.buckets == nil {
+		l.buckets = make([]map[uint64][]string, l.Bands)
+		for b := range l.buckets {
+			l.buckets[b] = make(map[uint64][]string)
+		}
+	}
+	l.Signatures[id] = sig
+	for b := 0; b < l.Bands; b++ {
+		key := l.band(sig, b)
+		l.buckets[b][key] = append(l.buckets[b][key], id)
+	}
+	return nil
+}
+
+func (l *LSH) remove(id string) {
+	sig := l.Signatures[id]
+	for b := 0; b < l.Bands; b++ {
+		key := l.band(sig, b)
+		bucket := l.buckets[b][key]
+		for i, other := range bucket {
+			if other == id {
+				bucket = append(bucket[:i], bucket[i+1:]...)
+				break
+			}
+		}
+		l.buckets[b][key] = bucket
+	}
+	delete(l.Signatures, id)
+}
+
+// Query returns the ids sharing a bucket with the signature, sorted.
+func (l *LSH) Query(sig Signature) []string {
+	if len(sig) != l.Bands*l.Rows || l.buckets == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var ids []string
+	for b := 0; b < l.Bands; b++ {
+		for _, id := range l.buckets[b][l.band(sig, b)] {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Candidates returns every pair of ids sharing a bucket, each pair once and sorted.
+func (l *LSH) Candidates() [][2]string {
+	seen := make(map[[2]string]bool)
+	var pairs [][2]string
+	for _, buckets := range l.buckets {
+		for _, ids := range buckets {
+			for i := 0; i < len(ids); i++ {
+				for j := i + 1; j < len(ids); j++ {
+					pair := [2]string{ids[i], ids[j]}
+					if pair[0] > pair[1] {
+						pair[0], pair[1] = pair[1], pair[0]
+					}
+					if !seen[pair] {
+						seen[pair] = true
+						pairs = append(pairs, pair)
+					}
+				}
+			}
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i][0] != pairs[j][0] {
+			return pairs[i][0] < pairs[j][0]
+		}
+		return pairs[i][1] < pairs[j][1]
+	})
+	return pairs
+}
+
+// band hashes the rows of band b of the signature.
+func (l *LSH) band(sig Signature, b int) uint64 {
+	h := uint64(b) + 1
+	for _, v := range sig[b*l.Rows : (b+1)*l.Rows] {
+		h = mix(h ^ v)
+	}
+	return h
+}
+
+func hash64(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+// mix scrambles the bits of x, the finalizer of splitmix64, so every seed gives another hash function.
+func mix(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package similarity
+
+import (
+	"math/bits"
+	"strings"
+)
+
+/**
+ * SimHash (Charikar, 2002)
+ *
+ * Folds weighted features into a 64-bit fingerprint: every bit is set when
+ * the features whose hash has it set outweigh those whose hash doesn't.
+ * Texts with nearly the same features get fingerprints a few bits apart,
+ * so the Hamming distance of fingerprints tells near duplicates, a handful
+ * of bits out of 64 being the usual threshold.
+ */
+func SimHash(features map[string]float64) uint64 {
+	var votes [64]float64
+	for feature, weight := range features {
+		h := hash64(feature)
+		for b := 0; b < 64; b++ {
+			if h&(1<<uint(b)) != 0 {
+				votes[b] += weight
+			} else {
+				votes[b] -= weight
+			}
+		}
+	}
+	var fingerprint uint64
+	for b, v := range votes {
+		if v > 0 {
+			fingerprint |= 1 << uint(b)
+		}
+	}
+	return fingerprint
+}
+
+// SimHashText returns the SimHash of the shingles of k words of the text, each weighing its count.
+func SimHashText(text string, k int) uint64 {
+	tokens := Tokens(text)
+	if k < 1 {
+		k = 1
+	}
+	features := make(map[string]float64)
+	if len(tokens) > 0 && len(tokens) < k {
+		k = len(tokens)
+	}
+	for i := 0; i+k <= len(tokens); i++ {
+		features[strings.Join(tokens[i:i+k], " ")]++
+	}
+	return SimHash(features)
+}
+
+// Hamming is the number of bits two fingerprints differ by.
+func Hamming(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
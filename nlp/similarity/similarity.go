@@ -0,0 +1,88 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+// Package similarity compares documents and finds the near duplicates of a
+// corpus. Cosine compares TF-IDF vectors and Jaccard sets of shingles, the
+// runs of k words of a text. MinHash signatures estimate the Jaccard
+// similarity and, banded by LSH, find the similar pairs of a large corpus
+// without comparing every pair, SimHash fingerprints do the same for small
+// edits by their Hamming distance.
+package similarity
+
+import (
+	"github.com/broosaction/gotext/nlp/vectorizer"
+	"github.com/broosaction/gotext/tokenizers"
+	"github.com/broosaction/gotext/utils/sparse"
+	"strings"
+)
+
+// Set is a set of shingles.
+type Set map[string]struct{}
+
+// Tokens lower cases the text and cuts it into words.
+func Tokens(text string) []string {
+	return tokenizers.GetTokenizer(tokenizers.DefaultTokenizerName).Tokenize(strings.ToLower(text))
+}
+
+// Shingles returns the runs of k words of the text, the whole text when it has fewer words.
+func Shingles(text string, k int) Set {
+	tokens := Tokens(text)
+	if k < 1 {
+		k = 1
+	}
+	set := make(Set)
+	if len(tokens) > 0 && len(tokens) < k {
+		set[strings.Join(tokens, " ")] = struct{}{}
+	}
+	for i := 0; i+k <= len(tokens); i++ {
+		set[strings.Join(tokens[i:i+k], " ")] = struct{}{}
+	}
+	return set
+}
+
+// CharShingles returns the runs of k characters of the text, lower cased and with its spaces collapsed.
+func CharShingles(text string, k int) Set {
+	runes := []rune(strings.Join(strings.Fields(strings.ToLower(text)), " "))
+	if k < 1 {
+		k = 1
+	}
+	set := make(Set)
+	if len(runes) > 0 && len(runes) < k {
+		set[string(runes)] = struct{}{}
+	}
+	for i := 0; i+k <= len(runes); i++ {
+		set[string(runes[i:i+k])] = struct{}{}
+	}
+	return set
+}
+
+// Jaccard is the size of the intersection of two sets over the size of their union, 0 when both are empty.
+func Jaccard(a, b Set) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	shared := 0
+	for s := range a {
+		if _, ok := b[s]; ok {
+			shared++
+		}
+	}
+	union := len(a) + len(b) - shared
+	if union == 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
+
+/**
+ * Cosine compares two texts by the angle between their vectors, such as
+ * those of a TfidfVectorizer fitted on the corpus, 1 for the same terms
+ * in the same proportions and 0 for no term in common.
+ */
+func Cosine(v vectorizer.Vectorizer, a, b string) float64 {
+	return sparse.Cosine(v.Transform(a), v.Transform(b))
+}
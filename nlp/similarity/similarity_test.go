@@ -0,0 +1,152 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package similarity
+
+import (
+	"fmt"
+	"github.com/broosaction/gotext/nlp/vectorizer"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const (
+	original = "The city council approved the new budget on Tuesday after a long debate about road repairs, school funding and the public library opening hours for the next year"
+	copied   = "The city council approved the new budget on Tuesday after a long debate about road repairs, school funding and the public library opening hours for the next year."
+	edited   = "The city council approved the new budget on Tuesday after a long debate about road repairs, school funding and the public library opening hours for the coming year"
+	other    = "Heavy rain is expected across the north of the country this weekend, with flood warnings issued for several rivers and travel disruption likely on Sunday"
+)
+
+func TestShingles(t *testing.T) {
+	got := Shingles("A b c D", 2)
+	want := Set{"a b": {}, "b c": {}, "c d": {}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Shingles = %v, want %v", got, want)
+	}
+	if got := Shingles("short", 3); len(got) != 1 {
+		t.Errorf("short text gave %v", got)
+	}
+	if got := CharShingles("Ab  c", 3); len(got) != 2 {
+		t.Errorf("CharShingles = %v", got)
+	}
+	if sim := Jaccard(Shingles("a b c", 1), Shingles("b c d", 1)); sim != 0.5 {
+		t.Errorf("Jaccard = %f, want 0.5", sim)
+	}
+	if sim := Jaccard(Set{}, Set{}); sim != 0 {
+		t.Errorf("Jaccard of empty sets = %f", sim)
+	}
+}
+
+func TestCosine(t *testing.T) {
+	v := vectorizer.NewTfidfVectorizer()
+	if err := v.Fit([]string{original, edited, other}); err != nil {
+		t.Fatal(err)
+	}
+	near, far := Cosine(v, original, edited), Cosine(v, original, other)
+	if near < 0.9 || far > 0.2 {
+		t.Errorf("near = %f, far = %f", near, far)
+	}
+	if sim := Cosine(v, original, copied); math.Abs(sim-1) > 1e-9 {
+		t.Errorf("copy = %f, want 1", sim)
+	}
+}
+
+func TestMinHash(t *testing.T) {
+	mh := NewMinHash(256, 7)
+	a, b := Shingles(original, 2), Shingles(edited, 2)
+	estimate := mh.Signature(a).Similarity(mh.Signature(b))
+	if exact := Jaccard(a, b); math.Abs(estimate-exact) > 0.1 {
+		t.Errorf("estimate = %f, exact = %f", estimate, exact)
+	}
+	if sim := mh.Signature(a).Similarity(mh.Signature(Shingles(other, 2))); sim > 0.1 {
+		t.Errorf("unrelated texts estimate %f", sim)
+	}
+
+	lsh := NewLSH(16, 8)
+	if err := lsh.Add("bad", mh.Signature(a)); err == nil {
+		t.Error("a signature of the wrong size was added")
+	}
+	mh = NewMinHash(128, 7)
+	lsh.Add("original", mh.Signature(Shingles(original, 3)))
+	lsh.Add("other", mh.Signature(Shingles(other, 3)))
+	lsh.Add("copied", mh.Signature(Shingles(copied, 3)))
+	if got := lsh.Query(mh.Signature(Shingles(copied, 3))); !reflect.DeepEqual(got, []string{"copied", "original"}) {
+		t.Errorf("Query = %v", got)
+	}
+	if got := lsh.Candidates(); !reflect.DeepEqual(got, [][2]string{{"copied", "original"}}) {
+		t.Errorf("Candidates = %v", got)
+	}
+	// adding an id again replaces it
+	lsh.Add("copied", mh.Signature(Shingles(other, 3)))
+	if got := lsh.Candidates(); !reflect.DeepEqual(got, [][2]string{{"copied", "other"}}) {
+		t.Errorf("Candidates after replace = %v", got)
+	}
+}
+
+func TestSimHash(t *testing.T) {
+	a, b, c := SimHashText(original, 1), SimHashText(copied, 1), SimHashText(other, 1)
+	if a != b {
+		t.Errorf("the same words gave %x and %x", a, b)
+	}
+	if d := Hamming(a, SimHashText(edited, 1)); d > 10 {
+		t.Errorf("an edit moved the fingerprint by %d bits", d)
+	}
+	if d := Hamming(a, c); d < 15 {
+		t.Errorf("unrelated texts are %d bits apart", d)
+	}
+	if Hamming(0, 7) != 3 {
+		t.Error("Hamming(0, 7) != 3")
+	}
+}
+
+func corpus() []string {
+	texts := []string{original, other, copied, edited}
+	for i := 0; i < 20; i++ {
+		texts = append(texts, fmt.Sprintf("Report %d covers %s", i, strings.Repeat(fmt.Sprintf("topic%d ", i*7), 3+i%4)))
+	}
+	return texts
+}
+
+func TestDeduper(t *testing.T) {
+	texts := corpus()
+	d := NewDeduper()
+	pairs := d.Pairs(texts)
+	if len(pairs) == 0 || pairs[0].A != 0 || pairs[0].B != 2 || pairs[0].Similarity != 1 {
+		t.Fatalf("MinHash pairs = %v", pairs)
+	}
+	for _, p := range pairs {
+		if p.A >= 4 || p.B >= 4 || p.A == 1 || p.B == 1 {
+			t.Errorf("unexpected pair %v", p)
+		}
+	}
+	if got := d.Unique(texts); got[0] != 0 || got[1] != 1 || len(got) < len(texts)-2 || len(got) > len(texts)-1 {
+		t.Errorf("Unique = %v", got)
+	}
+
+	d.Method = DM_SimHash
+	d.Shingle = 1
+	d.Distance = 6
+	groups := d.Groups(texts)
+	if len(groups) == 0 || !reflect.DeepEqual(groups[0][:2], []int{0, 2}) {
+		t.Errorf("SimHash groups = %v", groups)
+	}
+	for _, p := range d.Pairs(texts) {
+		if Hamming(SimHashText(texts[p.A], 1), SimHashText(texts[p.B], 1)) > 6 {
+			t.Errorf("pair %v is too far", p)
+		}
+	}
+}
+
+func TestGroups(t *testing.T) {
+	got := groups(6, []Pair{{A: 3, B: 5}, {A: 0, B: 4}, {A: 4, B: 5}, {A: 1, B: 2}})
+	want := [][]int{{0, 3, 4, 5}, {1, 2}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("groups = %v, want %v", got, want)
+	}
+}
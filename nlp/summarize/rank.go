@@ -0,0 +1,149 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package summarize
+
+import (
+	"github.com/broosaction/gotext/tokenizers"
+	"github.com/broosaction/gotext/utils/sparse"
+	"math"
+	"strings"
+)
+
+const (
+	// the damping of PageRank, the chance of following an edge rather than jumping anywhere
+	damping = 0.85
+
+	rankIterations = 100
+	rankTolerance  = 1e-6
+)
+
+// rank scores the sentences and returns them with their pairwise similarities.
+func (s *Summarizer) rank(sentences []string) ([]Sentence, [][]float64) {
+	n := len(sentences)
+	if n == 0 {
+		return nil, nil
+	}
+	vectors := s.vectors(sentences)
+	similarity := make([][]float64, n)
+	for i := range similarity {
+		similarity[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			sim := sparse.Cosine(vectors[i], vectors[j])
+			similarity[i][j], similarity[j][i] = sim, sim
+		}
+	}
+
+	// the graph of the method
+	edges := similarity
+	if s.Method == SM_LexRank {
+		edges = make([][]float64, n)
+		for i := range edges {
+			edges[i] = make([]float64, n)
+			for j, sim := range similarity[i] {
+				if i != j && sim > s.Threshold {
+					edges[i][j] = 1
+				}
+			}
+		}
+	}
+	centrality := pageRank(edges)
+
+	minWords := s.MinWords
+	ranked := make([]Sentence, n)
+	var best float64
+	for i, text := range sentences {
+		score := centrality[i] * (1 + s.PositionWeight*(1-float64(i)/float64(n)))
+		if words := len(strings.Fields(text)); minWords > 0 && words < minWords {
+			score *= float64(words) / float64(minWords)
+		}
+		ranked[i] = Sentence{Index: i, Text: text, Score: score}
+		best = math.Max(best, score)
+	}
+	if best > 0 {
+		for i := range ranked {
+			ranked[i].Score /= best
+		}
+	}
+	return ranked, similarity
+}
+
+// vectors returns the unit TF-IDF vector of every sentence, the sentences being the documents.
+func (s *Summarizer) vectors(sentences []string) []sparse.Vector {
+	tokenizer := tokenizers.GetTokenizer(tokenizers.DefaultTokenizerName)
+	vocabulary := make(map[string]int)
+	var df []int
+	counts := make([]map[int]float64, len(sentences))
+	for i, text := range sentences {
+		counts[i] = make(map[int]float64)
+		for _, term := range tokenizer.Tokenize(strings.ToLower(text)) {
+			if _, ok := s.StopWords[term]; ok {
+				continue
+			}
+			k, ok := vocabulary[term]
+			if !ok {
+				k = len(df)
+				vocabulary[term] = k
+				df = append(df, 0)
+			}
+			if counts[i][k] == 0 {
+				df[k]++
+			}
+			counts[i][k]++
+		}
+	}
+
+	n := float64(len(sentences))
+	vectors := make([]sparse.Vector, len(sentences))
+	for i, c := range counts {
+		for k, tf := range c {
+			c[k] = tf * (math.Log((1+n)/(1+float64(df[k]))) + 1)
+		}
+		vectors[i] = sparse.New(c).Normalize()
+	}
+	return vectors
+}
+
+// pageRank ranks the nodes of a weighted graph given as a matrix.
+func pageRank(edges [][]float64) []float64 {
+	n := len(edges)
+	out := make([]float64, n)
+	rank := make([]float64, n)
+	for i := range edges {
+		for _, w := range edges[i] {
+			out[i] += w
+		}
+		rank[i] = 1
+	}
+	for iteration := 0; iteration < rankIterations; iteration++ {
+		next := make([]float64, n)
+		for i := range next {
+			next[i] = 1 - damping
+		}
+		for i := range edges {
+			if out[i] == 0 {
+				continue
+			}
+			for j, w := range edges[i] {
+				if w > 0 {
+					next[j] += damping * rank[i] * w / out[i]
+				}
+			}
+		}
+		var change float64
+		for i := range rank {
+			change = math.Max(change, math.Abs(next[i]-rank[i]))
+		}
+		rank = next
+		if change < rankTolerance {
+			break
+		}
+	}
+	return rank
+}
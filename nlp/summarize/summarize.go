@@ -0,0 +1,195 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+// Package summarize shortens a text to its most telling sentences. The
+// sentences are ranked by how central they are in the graph of their TF-IDF
+// similarities, TextRank or LexRank, nudged by their position and length,
+// and picked with Maximal Marginal Relevance so the summary doesn't repeat
+// itself, until it reaches a number of sentences or of words.
+package summarize
+
+import (
+	"github.com/broosaction/gotext/tokenizers"
+	stringUtils "github.com/broosaction/gotext/utils/strings"
+	"sort"
+	"strings"
+)
+
+type Method uint8
+
+const (
+	// PageRank over the graph of sentences weighted by their similarity
+	SM_TextRank Method = iota
+	// PageRank over the graph of sentences more similar than Threshold, unweighted
+	SM_LexRank
+)
+
+// Sentence is a sentence of the text and how well it sums the text up.
+type Sentence struct {
+	// Position of the sentence in the text.
+	Index int
+	Text  string
+
+	// Centrality weighed by position and length, from 0 to 1.
+	Score float64
+}
+
+/**
+ * Summarizer
+ *
+ * Extractive summarization: the summary is made of sentences of the text,
+ * in their order. Every sentence scores its centrality times a bonus for
+ * coming early and a penalty for being shorter than MinWords,
+ *
+ *	score(i) = rank(i) * (1 + PositionWeight * (1 - i / n)) * min(1, words(i) / MinWords)
+ *
+ * scaled so the best sentence scores 1. Sentences are then picked by
+ * Maximal Marginal Relevance, the next one being the best of
+ *
+ *	Lambda * score(i) - (1 - Lambda) * max similarity(i, picked)
+ *
+ * so a sentence repeating a picked one loses to a less central new one.
+ *
+ * @category    Natural Language Processing
+
+  **usage
+	s := summarize.NewSummarizer()
+	s.Sentences = 0
+	s.Words = 80
+	fmt.Println(s.SummarizeText(thread))
+*/
+type Summarizer struct {
+	Method Method
+
+	// The number of sentences of the summary, 3 by default. When 0, Words sets the length.
+	Sentences int
+
+	// The most words of the summary, used when Sentences is 0. The best
+	// sentence is kept even when it is longer.
+	Words int
+
+	// The share of relevance against novelty in MMR, up to 1 which turns redundancy removal off, 0.5 by default.
+	Lambda float64
+
+	// How much the first sentences are favored, 0 not at all.
+	PositionWeight float64
+
+	// Sentences with fewer words score less.
+	MinWords int
+
+	// The similarity two sentences need to be linked in LexRank.
+	Threshold float64
+
+	// words left out of the similarities, strings.GetStopwords by default
+	StopWords map[string]struct{}
+}
+
+func NewSummarizer() *Summarizer {
+	return &Summarizer{
+		Method:         SM_TextRank,
+		Sentences:      3,
+		Lambda:         0.5,
+		PositionWeight: 0.1,
+		MinWords:       5,
+		Threshold:      0.1,
+		StopWords:      stringUtils.GetStopwords(),
+	}
+}
+
+// Rank scores every sentence, in text order.
+func (s *Summarizer) Rank(sentences []string) []Sentence {
+	ranked, _ := s.rank(sentences)
+	return ranked
+}
+
+// Summarize picks the sentences of the summary and returns them in text order.
+func (s *Summarizer) Summarize(sentences []string) []Sentence {
+	ranked, similarity := s.rank(sentences)
+	if len(ranked) == 0 {
+		return nil
+	}
+	lambda := s.Lambda
+	if lambda <= 0 || lambda > 1 {
+		lambda = 0.5
+	}
+	maxSentences := s.Sentences
+	if maxSentences <= 0 && s.Words <= 0 {
+		maxSentences = 3
+	}
+
+	var picked []int
+	used := make([]bool, len(ranked))
+	words := 0
+	for maxSentences <= 0 || len(picked) < maxSentences {
+		best, bestMMR := -1, 0.0
+		for i, sentence := range ranked {
+			if used[i] {
+				continue
+			}
+			var redundancy float64
+			for _, j := range picked {
+				if similarity[i][j] > redundancy {
+					redundancy = similarity[i][j]
+				}
+			}
+			mmr := lambda*sentence.Score - (1-lambda)*redundancy
+			if best < 0 || mmr > bestMMR {
+				best, bestMMR = i, mmr
+			}
+		}
+		if best < 0 {
+			break
+		}
+		used[best] = true
+		if maxSentences <= 0 {
+			n := len(strings.Fields(ranked[best].Text))
+			// a longer sentence is skipped, a shorter one may still fit
+			if len(picked) > 0 && words+n > s.Words {
+				continue
+			}
+			words += n
+		}
+		picked = append(picked, best)
+	}
+
+	sort.Ints(picked)
+	summary := make([]Sentence, len(picked))
+	for k, i := range picked {
+		summary[k] = ranked[i]
+	}
+	return summary
+}
+
+// Pick returns the positions of the sentences of the summary, in text order, see types.Document.PrepareSummary.
+func (s *Summarizer) Pick(sentences []string) []int {
+	summary := s.Summarize(sentences)
+	picked := make([]int, len(summary))
+	for i, sentence := range summary {
+		picked[i] = sentence.Index
+	}
+	return picked
+}
+
+// SummarizeText splits the text into sentences and returns their summary, the sentences joined by a space.
+func (s *Summarizer) SummarizeText(text string) string {
+	var texts []string
+	for _, sentence := range tokenizers.GetTokenizer(tokenizers.SentenceTokenizerName).Tokenize(text) {
+		if sentence = strings.TrimSpace(sentence); sentence != "" {
+			texts = append(texts, sentence)
+		}
+	}
+	return Join(s.Summarize(texts))
+}
+
+// Join joins the texts of the sentences by a space.
+func Join(sentences []Sentence) string {
+	texts := make([]string, len(sentences))
+	for i, sentence := range sentences {
+		texts[i] = strings.TrimSpace(sentence.Text)
+	}
+	return strings.Join(texts, " ")
+}
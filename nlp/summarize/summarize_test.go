@@ -0,0 +1,162 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package summarize
+
+import (
+	"github.com/broosaction/gotext/utils/types"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+var thread = []string{
+	"My printer stopped printing after the latest driver update.",
+	"The printer shows a paper jam error but there is no paper stuck inside.",
+	"I restarted the printer and the computer twice.",
+	"Thanks for reaching out, we are sorry about the trouble.",
+	"The driver update changed the paper jam sensor settings on some printer models.",
+	"Rolling back the printer driver to the previous version clears the paper jam error.",
+	"Rolling back the driver to the previous version cleared the paper jam error, thanks.",
+	"Have a nice day.",
+}
+
+func TestRank(t *testing.T) {
+	s := NewSummarizer()
+	for _, method := range []Method{SM_TextRank, SM_LexRank} {
+		s.Method = method
+		ranked := s.Rank(thread)
+		if len(ranked) != len(thread) {
+			t.Fatalf("method %d ranked %d sentences", method, len(ranked))
+		}
+		best := 0.0
+		for i, sentence := range ranked {
+			if sentence.Index != i || sentence.Text != thread[i] {
+				t.Errorf("sentence %d is %+v", i, sentence)
+			}
+			if sentence.Score < 0 || sentence.Score > 1 {
+				t.Errorf("score %f out of [0, 1]", sentence.Score)
+			}
+			if sentence.Score > best {
+				best = sentence.Score
+			}
+		}
+		if best != 1 {
+			t.Errorf("best score %f, want 1", best)
+		}
+		// short and off topic
+		if ranked[7].Score >= ranked[5].Score {
+			t.Errorf("method %d: %q ranks above %q", method, thread[7], thread[5])
+		}
+	}
+	if got := s.Rank(nil); got != nil {
+		t.Errorf("no sentences gave %v", got)
+	}
+	if got := s.Summarize([]string{"Only one."}); len(got) != 1 {
+		t.Errorf("one sentence gave %v", got)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	s := NewSummarizer()
+	s.Sentences = 2
+	summary := s.Summarize(thread)
+	if len(summary) != 2 {
+		t.Fatalf("got %d sentences", len(summary))
+	}
+	if summary[0].Index > summary[1].Index {
+		t.Errorf("summary not in text order: %v", summary)
+	}
+
+	// the two roll back sentences say the same, MMR keeps one
+	both := func(summary []Sentence) bool {
+		n := 0
+		for _, sentence := range summary {
+			if sentence.Index == 5 || sentence.Index == 6 {
+				n++
+			}
+		}
+		return n == 2
+	}
+	s.Sentences = 3
+	if summary = s.Summarize(thread); both(summary) {
+		t.Errorf("redundant summary: %q", Join(summary))
+	}
+	s.Lambda = 1
+	ranked := s.Rank(thread)
+	if ranked[5].Score > ranked[1].Score && ranked[6].Score > ranked[1].Score && !both(s.Summarize(thread)) {
+		t.Errorf("without MMR the best sentences should be kept")
+	}
+}
+
+func TestWords(t *testing.T) {
+	s := NewSummarizer()
+	s.Sentences = 0
+	s.Words = 25
+	summary := s.Summarize(thread)
+	if len(summary) == 0 {
+		t.Fatal("empty summary")
+	}
+	if words := len(strings.Fields(Join(summary))); words > 25 {
+		t.Errorf("summary has %d words: %q", words, Join(summary))
+	}
+
+	// the best sentence is kept even when it is too long
+	s.Words = 1
+	if summary = s.Summarize(thread); len(summary) != 1 {
+		t.Errorf("got %d sentences", len(summary))
+	}
+}
+
+func TestSummarizeText(t *testing.T) {
+	s := NewSummarizer()
+	s.Sentences = 2
+	got := s.SummarizeText(strings.Join(thread, " "))
+	if got == "" {
+		t.Fatal("empty summary")
+	}
+	if !strings.Contains(got, "paper jam") {
+		t.Errorf("summary %q misses the topic", got)
+	}
+}
+
+func TestPickDocument(t *testing.T) {
+	s := NewSummarizer()
+	s.Sentences = 2
+	picked := s.Pick(thread)
+	var want []int
+	for _, sentence := range s.Summarize(thread) {
+		want = append(want, sentence.Index)
+	}
+	if !reflect.DeepEqual(picked, want) {
+		t.Errorf("picked %v, summarized %v", picked, want)
+	}
+
+	doc := types.NewDocument(strings.Join(thread, " "))
+	doc.Learn()
+	if want := strings.TrimSpace(doc.Sentences[0].Text); doc.Summary != want || !doc.Sentences[0].InSummary {
+		t.Errorf("Learn summed the document up as %q, not its first sentence", doc.Summary)
+	}
+	if got := doc.Sentences[0].Summary; got != "printer stopped printing latest driver update" {
+		t.Errorf("sentence summary %q", got)
+	}
+
+	doc = types.NewDocument(strings.Join(thread, " "))
+	doc.Learn(s.Pick)
+	var in []string
+	for _, sentence := range doc.Sentences {
+		if sentence.Summary == "" {
+			t.Errorf("no summary for %q", sentence.Text)
+		}
+		if sentence.InSummary {
+			in = append(in, strings.TrimSpace(sentence.Text))
+		}
+	}
+	if len(in) != 2 || doc.Summary != strings.Join(in, " ") {
+		t.Errorf("summary %q of %v", doc.Summary, in)
+	}
+}
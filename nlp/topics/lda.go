@@ -0,0 +1,325 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package topics
+
+import (
+	"fmt"
+	"github.com/broosaction/gotext/nlp/vectorizer"
+	"math"
+	"math/rand"
+)
+
+/**
+ * Latent Dirichlet Allocation (Blei et al., 2003)
+ *
+ * Every text is a mix of topics and every topic a distribution over the
+ * words. Fit learns them by collapsed Gibbs sampling (Griffiths and
+ * Steyvers, 2004): every word of every text is given a topic at random,
+ * then, round after round, each word draws its topic again knowing all
+ * the others,
+ *
+ *	p(z = k) ∝ (n(d, k) + Alpha) * (n(k, w) + Beta) / (n(k) + V * Beta)
+ *
+ * n counting the words of text d and the occurrences of word w given topic
+ * k. A small Alpha gives texts few topics, a small Beta gives topics few
+ * words. The vocabulary and its pruning are those of Counts, fitted on the
+ * same texts.
+ *
+ * @category    Machine Learning
+
+  **usage
+	lda := topics.NewLDA(10)
+	lda.Counts.StopWords = stringUtils.GetStopwords()
+	lda.Counts.MinDF = 2
+	err := lda.Fit(tickets)
+	for k := 0; k < lda.Topics(); k++ {
+		fmt.Println(k, lda.TopWords(k, 8))
+	}
+	fmt.Println(lda.Infer("my invoice is wrong"), lda.Perplexity(heldOut))
+	lda.Save("lda.model")
+*/
+type LDA struct {
+	// The number of topics.
+	K int
+
+	// The Dirichlet priors of the topics of a text and the words of a topic.
+	Alpha, Beta float64
+
+	// The rounds of sampling of Fit and Infer, 200 and 50 by default.
+	Iterations, InferIterations int
+
+	Seed int64
+
+	// The vocabulary, fitted with the model.
+	Counts *vectorizer.CountVectorizer
+
+	// The number of times every word was drawn for every topic, and their totals.
+	TopicWords  [][]int
+	TopicTotals []int
+
+	// The topic mix of every text fitted.
+	DocTopics [][]float64
+}
+
+func NewLDA(k int) *LDA {
+	return &LDA{
+		K:               k,
+		Alpha:           0.1,
+		Beta:            0.01,
+		Iterations:      200,
+		InferIterations: 50,
+		Counts:          vectorizer.NewCountVectorizer(),
+	}
+}
+
+func (m *LDA) getMeta() (string, string) {
+	return "LDA", "01"
+}
+
+// Topics is the number of topics.
+func (m *LDA) Topics() int {
+	return len(m.TopicTotals)
+}
+
+// Terms returns the words of the text in the vocabulary, in order.
+func (m *LDA) Terms(text string) []string {
+	var terms []string
+	for _, term := range m.Counts.Terms(text) {
+		if _, ok := m.Counts.Vocabulary[term]; ok {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// ids returns the vocabulary index of the words of the text.
+func (m *LDA) ids(text string) []int {
+	var ids []int
+	for _, term := range m.Counts.Terms(text) {
+		if id, ok := m.Counts.Vocabulary[term]; ok {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// Fit learns the vocabulary and the topics of the texts, replacing what was learned before.
+func (m *LDA) Fit(texts []string) error {
+	if m.K < 1 {
+		return fmt.Errorf("K = %d: %w", m.K, errBadK)
+	}
+	if m.Counts == nil {
+		m.Counts = vectorizer.NewCountVectorizer()
+	}
+	if err := m.Counts.Fit(texts); err != nil {
+		return err
+	}
+	alpha, beta := m.priors()
+	iterations := m.Iterations
+	if iterations <= 0 {
+		iterations = 200
+	}
+	v := m.Counts.Size()
+	rng := rand.New(rand.NewSource(m.Seed))
+
+	docs := make([][]int, len(texts))
+	topics := make([][]int, len(texts))
+	docTopics := make([][]int, len(texts))
+	m.TopicWords = make([][]int, m.K)
+	for k := range m.TopicWords {
+		m.TopicWords[k] = make([]int, v)
+	}
+	m.TopicTotals = make([]int, m.K)
+	for d, text := range texts {
+		docs[d] = m.ids(text)
+		topics[d] = make([]int, len(docs[d]))
+		docTopics[d] = make([]int, m.K)
+		for i, w := range docs[d] {
+			k := rng.Intn(m.K)
+			topics[d][i] = k
+			docTopics[d][k]++
+			m.TopicWords[k][w]++
+			m.TopicTotals[k]++
+		}
+	}
+
+	p := make([]float64, m.K)
+	vBeta := float64(v) * beta
+	for iteration := 0; iteration < iterations; iteration++ {
+		for d, words := range docs {
+			for i, w := range words {
+				k := topics[d][i]
+				docTopics[d][k]--
+				m.TopicWords[k][w]--
+				m.TopicTotals[k]--
+				for k := range p {
+					p[k] = (float64(docTopics[d][k]) + alpha) * (float64(m.TopicWords[k][w]) + beta) / (float64(m.TopicTotals[k]) + vBeta)
+				}
+				k = draw(p, rng)
+				topics[d][i] = k
+				docTopics[d][k]++
+				m.TopicWords[k][w]++
+				m.TopicTotals[k]++
+			}
+		}
+	}
+
+	m.DocTopics = make([][]float64, len(texts))
+	for d := range docs {
+		m.DocTopics[d] = mix(docTopics[d], alpha)
+	}
+	return nil
+}
+
+// priors returns Alpha and Beta, or their defaults.
+func (m *LDA) priors() (float64, float64) {
+	alpha, beta := m.Alpha, m.Beta
+	if alpha <= 0 {
+		alpha = 0.1
+	}
+	if beta <= 0 {
+		beta = 0.01
+	}
+	return alpha, beta
+}
+
+// phi is the probability of word w given topic k.
+func (m *LDA) phi(k, w int, beta float64) float64 {
+	v := float64(len(m.TopicWords[k]))
+	return (float64(m.TopicWords[k][w]) + beta) / (float64(m.TopicTotals[k]) + v*beta)
+}
+
+// TopWords returns the n most likely words of the topic, weighing their probability.
+func (m *LDA) TopWords(topic, n int) []Term {
+	if topic < 0 || topic >= m.Topics() {
+		return nil
+	}
+	_, beta := m.priors()
+	weights := make([]float64, len(m.TopicWords[topic]))
+	for w := range weights {
+		if m.TopicWords[topic][w] > 0 {
+			weights[w] = m.phi(topic, w, beta)
+		}
+	}
+	return topWords(weights, m.Counts.FeatureNames(), n)
+}
+
+/**
+ * Infer returns the topic mix of a new text, sampling the topics of its
+ * words while the topics stay as fitted. Words out of the vocabulary are
+ * skipped, a text without known words gets the same share of every topic.
+ * The same text always gets the same mix.
+ */
+func (m *LDA) Infer(text string) []float64 {
+	if m.Topics() == 0 {
+		return nil
+	}
+	alpha, beta := m.priors()
+	iterations := m.InferIterations
+	if iterations <= 0 {
+		iterations = 50
+	}
+	rng := rand.New(rand.NewSource(m.Seed))
+	words := m.ids(text)
+	topics := make([]int, len(words))
+	counts := make([]int, m.Topics())
+	for i := range words {
+		topics[i] = rng.Intn(m.Topics())
+		counts[topics[i]]++
+	}
+	p := make([]float64, m.Topics())
+	for iteration := 0; iteration < iterations; iteration++ {
+		for i, w := range words {
+			counts[topics[i]]--
+			for k := range p {
+				p[k] = (float64(counts[k]) + alpha) * m.phi(k, w, beta)
+			}
+			topics[i] = draw(p, rng)
+			counts[topics[i]]++
+		}
+	}
+	return mix(counts, alpha)
+}
+
+/**
+ * Perplexity returns how surprised the model is by the words of the texts,
+ * exp of minus their mean log-likelihood given the topic mix inferred for
+ * their text. The lower the better, compare it on texts not fitted. Words
+ * out of the vocabulary are skipped, 0 means no known word.
+ */
+func (m *LDA) Perplexity(texts []string) float64 {
+	if m.Topics() == 0 {
+		return 0
+	}
+	_, beta := m.priors()
+	var logLikelihood float64
+	words := 0
+	for _, text := range texts {
+		theta := m.Infer(text)
+		for _, w := range m.ids(text) {
+			var p float64
+			for k, share := range theta {
+				p += share * m.phi(k, w, beta)
+			}
+			logLikelihood += math.Log(p)
+			words++
+		}
+	}
+	if words == 0 {
+		return 0
+	}
+	return math.Exp(-logLikelihood / float64(words))
+}
+
+//save to a file
+func (m *LDA) Save(file string) error {
+	if m.Topics() == 0 {
+		return errNotFit
+	}
+	name, version := m.getMeta()
+	return save(file, name, version, m)
+}
+
+// Load from the output file.
+func (m *LDA) Load(filePath string) error {
+	name, version := m.getMeta()
+	// decode into a fresh one, gob leaves the fields saved as zero values untouched
+	var loaded LDA
+	if err := load(filePath, name, version, &loaded); err != nil {
+		return err
+	}
+	if loaded.Counts == nil {
+		loaded.Counts = vectorizer.NewCountVectorizer()
+	}
+	*m = loaded
+	return nil
+}
+
+// mix turns topic counts into shares smoothed by alpha.
+func mix(counts []int, alpha float64) []float64 {
+	shares := make([]float64, len(counts))
+	for k, c := range counts {
+		shares[k] = float64(c) + alpha
+	}
+	return normalize(shares)
+}
+
+// draw picks an index with a chance proportional to its weight.
+func draw(weights []float64, rng *rand.Rand) int {
+	var sum float64
+	for _, w := range weights {
+		sum += w
+	}
+	r := rng.Float64() * sum
+	for i, w := range weights {
+		if r -= w; r < 0 {
+			return i
+		}
+	}
+	return len(weights) - 1
+}
@@ -0,0 +1,315 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package topics
+
+import (
+	"fmt"
+	"github.com/broosaction/gotext/nlp/vectorizer"
+	"github.com/broosaction/gotext/utils/sparse"
+	"math"
+	"math/rand"
+)
+
+// keeps the multiplicative updates from dividing by 0
+const epsilon = 1e-10
+
+/**
+ * Non-negative Matrix Factorization
+ *
+ * Approximates the TF-IDF matrix of the texts V, one row per text, by the
+ * product of two smaller non-negative matrices V ≈ W H, W giving the
+ * weight of every topic in every text and H the weight of every word in
+ * every topic. Fit starts from random matrices and applies the
+ * multiplicative updates of Lee and Seung, which keep them non-negative
+ * and never raise the squared error ||V - W H||,
+ *
+ *	H ← H ∘ (Wᵀ V) / (Wᵀ W H)
+ *	W ← W ∘ (V Hᵀ) / (W H Hᵀ)
+ *
+ * NMF tends to give sharper topics than LDA on short texts.
+ *
+ * @category    Machine Learning
+
+  **usage
+	nmf := topics.NewNMF(10)
+	nmf.Tfidf.Counts.StopWords = stringUtils.GetStopwords()
+	err := nmf.Fit(tickets)
+	fmt.Println(nmf.TopWords(0, 8), nmf.Error)
+*/
+type NMF struct {
+	// The number of topics.
+	K int
+
+	// The rounds of updates of Fit and Infer, 200 and 100 by default.
+	Iterations, InferIterations int
+
+	Seed int64
+
+	// The vocabulary and weights of the texts, fitted with the model.
+	Tfidf *vectorizer.TfidfVectorizer
+
+	// The weight of every word in every topic, H.
+	TopicWords [][]float64
+
+	// The topic mix of every text fitted, the rows of W scaled to sum to 1.
+	DocTopics [][]float64
+
+	// The Frobenius norm of V - W H after fitting.
+	Error float64
+}
+
+func NewNMF(k int) *NMF {
+	return &NMF{
+		K:               k,
+		Iterations:      200,
+		InferIterations: 100,
+		Tfidf:           vectorizer.NewTfidfVectorizer(),
+	}
+}
+
+func (m *NMF) getMeta() (string, string) {
+	return "NMF", "01"
+}
+
+// Topics is the number of topics.
+func (m *NMF) Topics() int {
+	return len(m.TopicWords)
+}
+
+// Terms returns the words of the text in the vocabulary, in order.
+func (m *NMF) Terms(text string) []string {
+	var terms []string
+	for _, term := range m.Tfidf.Counts.Terms(text) {
+		if _, ok := m.Tfidf.Counts.Vocabulary[term]; ok {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// Fit learns the vocabulary and the topics of the texts, replacing what was learned before.
+func (m *NMF) Fit(texts []string) error {
+	if m.K < 1 {
+		return fmt.Errorf("K = %d: %w", m.K, errBadK)
+	}
+	if m.Tfidf == nil {
+		m.Tfidf = vectorizer.NewTfidfVectorizer()
+	}
+	rows, err := m.Tfidf.FitTransform(texts)
+	if err != nil {
+		return err
+	}
+	iterations := m.Iterations
+	if iterations <= 0 {
+		iterations = 200
+	}
+	k, v := m.K, m.Tfidf.Size()
+	rng := rand.New(rand.NewSource(m.Seed))
+
+	// start around the scale of V so W H is of the right size
+	var mean float64
+	for _, row := range rows {
+		for _, x := range row.Values {
+			mean += x
+		}
+	}
+	mean /= float64(len(rows) * v)
+	scale := math.Sqrt(mean / float64(k))
+	w := randomMatrix(len(rows), k, scale, rng)
+	h := randomMatrix(k, v, scale, rng)
+
+	for iteration := 0; iteration < iterations; iteration++ {
+		// H ← H ∘ (Wᵀ V) / (Wᵀ W H)
+		wtv := zeros(k, v)
+		for d, row := range rows {
+			for t := 0; t < k; t++ {
+				row.AddTo(wtv[t], w[d][t])
+			}
+		}
+		wtwh := mul(gram(w, k), h)
+		for t := range h {
+			for j := range h[t] {
+				h[t][j] *= wtv[t][j] / (wtwh[t][j] + epsilon)
+			}
+		}
+
+		// W ← W ∘ (V Hᵀ) / (W H Hᵀ)
+		hht := mul(h, transpose(h))
+		for d, row := range rows {
+			whht := make([]float64, k)
+			for t := range whht {
+				for s := 0; s < k; s++ {
+					whht[t] += w[d][s] * hht[s][t]
+				}
+			}
+			for t := 0; t < k; t++ {
+				w[d][t] *= row.Dot(h[t]) / (whht[t] + epsilon)
+			}
+		}
+	}
+
+	m.TopicWords = h
+	m.DocTopics = make([][]float64, len(rows))
+	m.Error = 0
+	for d, row := range rows {
+		m.Error += squaredError(row, w[d], h)
+		m.DocTopics[d] = normalize(append([]float64(nil), w[d]...))
+	}
+	m.Error = math.Sqrt(m.Error)
+	return nil
+}
+
+// TopWords returns the n heaviest words of the topic.
+func (m *NMF) TopWords(topic, n int) []Term {
+	if topic < 0 || topic >= m.Topics() {
+		return nil
+	}
+	return topWords(m.TopicWords[topic], m.Tfidf.Counts.FeatureNames(), n)
+}
+
+/**
+ * Infer returns the topic mix of a new text, the non-negative weights w
+ * minimizing ||v - w H|| for its TF-IDF vector v with H as fitted, scaled
+ * to sum to 1. A text without known words has no topic, all zeros.
+ */
+func (m *NMF) Infer(text string) []float64 {
+	k := m.Topics()
+	if k == 0 {
+		return nil
+	}
+	iterations := m.InferIterations
+	if iterations <= 0 {
+		iterations = 100
+	}
+	v := m.Tfidf.Transform(text)
+	w := make([]float64, k)
+	if v.Len() == 0 {
+		return w
+	}
+	for t := range w {
+		w[t] = 1 / float64(k)
+	}
+	hht := mul(m.TopicWords, transpose(m.TopicWords))
+	vht := make([]float64, k)
+	for t := range vht {
+		vht[t] = v.Dot(m.TopicWords[t])
+	}
+	for iteration := 0; iteration < iterations; iteration++ {
+		for t := range w {
+			var whht float64
+			for s := range w {
+				whht += w[s] * hht[s][t]
+			}
+			w[t] *= vht[t] / (whht + epsilon)
+		}
+	}
+	return normalize(w)
+}
+
+//save to a file
+func (m *NMF) Save(file string) error {
+	if m.Topics() == 0 {
+		return errNotFit
+	}
+	name, version := m.getMeta()
+	return save(file, name, version, m)
+}
+
+// Load from the output file.
+func (m *NMF) Load(filePath string) error {
+	name, version := m.getMeta()
+	// decode into a fresh one, gob leaves the fields saved as zero values untouched
+	var loaded NMF
+	if err := load(filePath, name, version, &loaded); err != nil {
+		return err
+	}
+	if loaded.Tfidf == nil {
+		loaded.Tfidf = vectorizer.NewTfidfVectorizer()
+	}
+	if loaded.Tfidf.Counts == nil {
+		loaded.Tfidf.Counts = vectorizer.NewCountVectorizer()
+	}
+	if loaded.Tfidf.Tfidf == nil {
+		loaded.Tfidf.Tfidf = vectorizer.NewTfidfTransformer()
+	}
+	*m = loaded
+	return nil
+}
+
+// squaredError is ||v - w H||² for one row.
+func squaredError(v sparse.Vector, w []float64, h [][]float64) float64 {
+	approx := make([]float64, len(h[0]))
+	for t, row := range h {
+		for j, x := range row {
+			approx[j] += w[t] * x
+		}
+	}
+	var sum float64
+	for j, x := range approx {
+		d := v.Get(j) - x
+		sum += d * d
+	}
+	return sum
+}
+
+func randomMatrix(rows, cols int, scale float64, rng *rand.Rand) [][]float64 {
+	m := zeros(rows, cols)
+	for i := range m {
+		for j := range m[i] {
+			m[i][j] = scale * (0.1 + rng.Float64())
+		}
+	}
+	return m
+}
+
+func zeros(rows, cols int) [][]float64 {
+	m := make([][]float64, rows)
+	for i := range m {
+		m[i] = make([]float64, cols)
+	}
+	return m
+}
+
+func transpose(a [][]float64) [][]float64 {
+	t := zeros(len(a[0]), len(a))
+	for i := range a {
+		for j, x := range a[i] {
+			t[j][i] = x
+		}
+	}
+	return t
+}
+
+// mul is the matrix product a b.
+func mul(a, b [][]float64) [][]float64 {
+	out := zeros(len(a), len(b[0]))
+	for i := range a {
+		for k, x := range a[i] {
+			if x == 0 {
+				continue
+			}
+			for j, y := range b[k] {
+				out[i][j] += x * y
+			}
+		}
+	}
+	return out
+}
+
+// gram is Wᵀ W, k by k.
+func gram(w [][]float64, k int) [][]float64 {
+	out := zeros(k, k)
+	for _, row := range w {
+		for s := 0; s < k; s++ {
+			for t := 0; t < k; t++ {
+				out[s][t] += row[s] * row[t]
+			}
+		}
+	}
+	return out
+}
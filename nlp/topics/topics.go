@@ -0,0 +1,196 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+// Package topics finds the themes running through a collection of texts.
+// Latent Dirichlet Allocation, fitted by collapsed Gibbs sampling, and
+// Non-negative Matrix Factorization of the TF-IDF matrix both describe a
+// topic by its heaviest words and a text by its mix of topics, for the
+// texts fitted and new ones. Models are scored by perplexity or coherence
+// and can be saved and loaded.
+package topics
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"github.com/broosaction/gotext/utils/persist"
+	"log"
+	"math"
+	"sort"
+	"strings"
+)
+
+var (
+	errBadK   = errors.New("the number of topics must be at least 1")
+	errNotFit = errors.New("the model is not fitted")
+)
+
+// Model is a fitted topic model.
+type Model interface {
+	// The number of topics.
+	Topics() int
+
+	// The n heaviest words of the topic, heaviest first.
+	TopWords(topic, n int) []Term
+
+	// The share of every topic in the text, summing to 1.
+	Infer(text string) []float64
+
+	// The words of the text the model knows, in order.
+	Terms(text string) []string
+}
+
+// Term is a word of a topic and its weight.
+type Term struct {
+	Text   string
+	Weight float64
+}
+
+// Dominant returns the topic with the largest share, -1 for no share.
+func Dominant(mix []float64) int {
+	best := -1
+	for k, p := range mix {
+		if best < 0 || p > mix[best] {
+			best = k
+		}
+	}
+	return best
+}
+
+/**
+ * Label describes a text by the n top words of its dominant topic, such as
+ * "password login reset", the empty string when the model knows none of
+ * its words.
+ */
+func Label(m Model, text string, n int) string {
+	mix := m.Infer(text)
+	k := Dominant(mix)
+	if k < 0 || mix[k] == 0 {
+		return ""
+	}
+	words := make([]string, 0, n)
+	for _, t := range m.TopWords(k, n) {
+		words = append(words, t.Text)
+	}
+	return strings.Join(words, " ")
+}
+
+/**
+ * Coherence returns the UMass coherence of every topic over reference
+ * texts, the texts fitted or others,
+ *
+ *	C(t) = Σ log((D(wi, wj) + 1) / D(wj)),  j < i <= n
+ *
+ * D counting the texts having the words and w1..wn being the n top words
+ * of t, heaviest first. The closer to 0 the more the top words come
+ * together, so the more the topic makes sense to a reader.
+ */
+func Coherence(m Model, texts []string, n int) []float64 {
+	docs := make([]map[string]bool, len(texts))
+	for i, text := range texts {
+		docs[i] = make(map[string]bool)
+		for _, term := range m.Terms(text) {
+			docs[i][term] = true
+		}
+	}
+	count := func(words ...string) int {
+		c := 0
+		for _, doc := range docs {
+			all := true
+			for _, w := range words {
+				all = all && doc[w]
+			}
+			if all {
+				c++
+			}
+		}
+		return c
+	}
+
+	scores := make([]float64, m.Topics())
+	for k := range scores {
+		top := m.TopWords(k, n)
+		for i := 1; i < len(top); i++ {
+			for j := 0; j < i; j++ {
+				if dj := count(top[j].Text); dj > 0 {
+					scores[k] += math.Log(float64(count(top[i].Text, top[j].Text)+1) / float64(dj))
+				}
+			}
+		}
+	}
+	return scores
+}
+
+// topWords returns the n heaviest words of the weights, by feature, heaviest first.
+func topWords(weights []float64, names []string, n int) []Term {
+	terms := make([]Term, 0, len(weights))
+	for i, w := range weights {
+		if i < len(names) && w > 0 {
+			terms = append(terms, Term{Text: names[i], Weight: w})
+		}
+	}
+	sort.Slice(terms, func(a, b int) bool {
+		if terms[a].Weight != terms[b].Weight {
+			return terms[a].Weight > terms[b].Weight
+		}
+		return terms[a].Text < terms[b].Text
+	})
+	if n > 0 && len(terms) > n {
+		terms = terms[:n]
+	}
+	return terms
+}
+
+// normalize scales the values to sum to 1, leaving all zeros as they are.
+func normalize(values []float64) []float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	if sum > 0 {
+		for i := range values {
+			values[i] /= sum
+		}
+	}
+	return values
+}
+
+func save(file, name, version string, model interface{}) error {
+	buf := new(bytes.Buffer)
+	encoder := gob.NewEncoder(buf)
+
+	err := encoder.Encode(model)
+	if err != nil {
+		return fmt.Errorf("error encoding model: %s", err)
+	}
+
+	persist.Save(file, persist.Modeldata{
+		Data:    buf.Bytes(),
+		Name:    name,
+		Version: version,
+	})
+	return nil
+}
+
+func load(filePath, name, version string, model interface{}) error {
+	log.Printf("Loading %s from %s...", name, filePath)
+	meta := persist.Load(filePath)
+	if meta.Name != name {
+		return fmt.Errorf("This file doesn't contain a %s model", name)
+	}
+	if meta.Version != version {
+		return fmt.Errorf("Can't understand this file format")
+	}
+
+	decoder := gob.NewDecoder(bytes.NewBuffer(meta.Data))
+	err := decoder.Decode(model)
+	if err != nil {
+		return fmt.Errorf("error decoding checkpoint file: %s", err)
+	}
+	return nil
+}
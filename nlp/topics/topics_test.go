@@ -0,0 +1,238 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package topics
+
+import (
+	"errors"
+	stringUtils "github.com/broosaction/gotext/utils/strings"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+var tickets = []string{
+	"cannot login my password is rejected",
+	"login fails after a password reset",
+	"password reset done but login still fails",
+	"login says my password is not accepted",
+	"invoice charged twice please refund",
+	"refund the double charge on my invoice",
+	"wrong charge on the invoice please refund",
+	"charged twice for one invoice need a refund",
+	"app crashes when uploading a photo",
+	"photo upload crashes the app",
+	"app crashes uploading a photo on android",
+	"uploading a photo crashes the app",
+}
+
+// anchors are a word only the tickets of each topic have.
+var anchors = []string{"password", "invoice", "photo"}
+
+// separates tells whether the dominant topic of every ticket is that of the tickets of its group only.
+func separates(t *testing.T, m Model, mixes [][]float64) {
+	t.Helper()
+	topicOf := make(map[int]int)
+	groupOf := make(map[int]int)
+	for i, mix := range mixes {
+		k, g := Dominant(mix), i/4
+		if tg, ok := topicOf[g]; ok && tg != k {
+			t.Errorf("group %d spreads over topics %d and %d", g, tg, k)
+		}
+		if gk, ok := groupOf[k]; ok && gk != g {
+			t.Errorf("topic %d mixes groups %d and %d", k, gk, g)
+		}
+		topicOf[g], groupOf[k] = k, g
+	}
+	for g, anchor := range anchors {
+		found := false
+		for _, term := range m.TopWords(topicOf[g], 4) {
+			found = found || term.Text == anchor
+		}
+		if !found {
+			t.Errorf("top words of topic %d = %v, want %q among them", topicOf[g], m.TopWords(topicOf[g], 4), anchor)
+		}
+	}
+}
+
+func sums1(t *testing.T, mix []float64) {
+	t.Helper()
+	var sum float64
+	for _, p := range mix {
+		sum += p
+	}
+	if math.Abs(sum-1) > 1e-9 {
+		t.Errorf("mix %v sums to %f", mix, sum)
+	}
+}
+
+func TestLDA(t *testing.T) {
+	lda := NewLDA(3)
+	lda.Counts.StopWords = stringUtils.GetStopwords()
+	lda.Seed = 7
+	if err := lda.Fit(tickets); err != nil {
+		t.Fatal(err)
+	}
+	separates(t, lda, lda.DocTopics)
+
+	mix := lda.Infer("my password is rejected at login")
+	sums1(t, mix)
+	if Dominant(mix) != Dominant(lda.DocTopics[0]) {
+		t.Errorf("inferred %v, want topic %d", mix, Dominant(lda.DocTopics[0]))
+	}
+	again := lda.Infer("my password is rejected at login")
+	for k := range mix {
+		if mix[k] != again[k] {
+			t.Errorf("inference is not repeatable: %v and %v", mix, again)
+		}
+	}
+
+	fitted := lda.Perplexity(tickets[:4])
+	if fitted <= 1 || fitted >= float64(lda.Counts.Size()) {
+		t.Errorf("perplexity %f with %d words", fitted, lda.Counts.Size())
+	}
+	one := NewLDA(1)
+	one.Counts.StopWords = stringUtils.GetStopwords()
+	one.Fit(tickets)
+	if p := one.Perplexity(tickets[:4]); p <= fitted {
+		t.Errorf("one topic perplexity %f, three %f", p, fitted)
+	}
+	if p := lda.Perplexity([]string{"nothing known here"}); p != 0 {
+		t.Errorf("perplexity without known words %f", p)
+	}
+
+	if err := NewLDA(0).Fit(tickets); !errors.Is(err, errBadK) {
+		t.Errorf("K = 0 gave %v", err)
+	}
+}
+
+func TestNMF(t *testing.T) {
+	nmf := NewNMF(3)
+	nmf.Tfidf.Counts.StopWords = stringUtils.GetStopwords()
+	nmf.Seed = 1
+	if err := nmf.Fit(tickets); err != nil {
+		t.Fatal(err)
+	}
+	separates(t, nmf, nmf.DocTopics)
+	for _, mix := range nmf.DocTopics {
+		sums1(t, mix)
+	}
+
+	mix := nmf.Infer("the invoice charge is wrong")
+	sums1(t, mix)
+	if Dominant(mix) != Dominant(nmf.DocTopics[4]) {
+		t.Errorf("inferred %v, want topic %d", mix, Dominant(nmf.DocTopics[4]))
+	}
+	for _, p := range nmf.Infer("nothing known here") {
+		if p != 0 {
+			t.Errorf("a text without known words has a topic")
+		}
+	}
+
+	one := NewNMF(1)
+	one.Tfidf.Counts.StopWords = stringUtils.GetStopwords()
+	one.Fit(tickets)
+	if nmf.Error <= 0 || nmf.Error >= one.Error {
+		t.Errorf("error %f with 3 topics, %f with 1", nmf.Error, one.Error)
+	}
+}
+
+// fixed is a model with set top words, for coherence.
+type fixed struct {
+	*NMF
+	words [][]string
+}
+
+func (f fixed) Topics() int {
+	return len(f.words)
+}
+
+func (f fixed) TopWords(topic, n int) []Term {
+	var terms []Term
+	for _, w := range f.words[topic][:n] {
+		terms = append(terms, Term{Text: w, Weight: 1})
+	}
+	return terms
+}
+
+func TestCoherence(t *testing.T) {
+	nmf := NewNMF(3)
+	nmf.Tfidf.Counts.StopWords = stringUtils.GetStopwords()
+	nmf.Fit(tickets)
+	if c := Coherence(nmf, tickets, 4); len(c) != 3 {
+		t.Fatalf("coherence = %v", c)
+	}
+
+	m := fixed{NMF: nmf, words: [][]string{
+		{"invoice", "refund", "charged", "twice"},
+		{"invoice", "photo", "password", "app"},
+	}}
+	c := Coherence(m, tickets, 4)
+	if c[0] <= c[1] {
+		t.Errorf("words of one group score %f, of all groups %f", c[0], c[1])
+	}
+}
+
+func TestLabel(t *testing.T) {
+	nmf := NewNMF(3)
+	nmf.Tfidf.Counts.StopWords = stringUtils.GetStopwords()
+	nmf.Fit(tickets)
+	label := Label(nmf, "the app crashes", 2)
+	k := Dominant(nmf.DocTopics[8])
+	if want := nmf.TopWords(k, 2)[0].Text + " " + nmf.TopWords(k, 2)[1].Text; label != want {
+		t.Errorf("label %q, want %q", label, want)
+	}
+	if label := Label(nmf, "nothing known here", 2); label != "" {
+		t.Errorf("label without known words %q", label)
+	}
+	if k := Dominant(nil); k != -1 {
+		t.Errorf("dominant of no topic %d", k)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	lda := NewLDA(3)
+	lda.Counts.StopWords = stringUtils.GetStopwords()
+	if err := NewLDA(3).Save(filepath.Join(dir, "empty.model")); !errors.Is(err, errNotFit) {
+		t.Errorf("saving an unfitted model gave %v", err)
+	}
+	lda.Fit(tickets)
+	file := filepath.Join(dir, "lda.model")
+	if err := lda.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	var loadedLDA LDA
+	if err := loadedLDA.Load(file); err != nil {
+		t.Fatal(err)
+	}
+	want, got := lda.Infer("login password"), loadedLDA.Infer("login password")
+	for k := range want {
+		if want[k] != got[k] {
+			t.Errorf("loaded LDA infers %v, want %v", got, want)
+		}
+	}
+	if err := new(NMF).Load(file); err == nil {
+		t.Errorf("NMF loaded an LDA model")
+	}
+
+	nmf := NewNMF(3)
+	nmf.Tfidf.Counts.StopWords = stringUtils.GetStopwords()
+	nmf.Fit(tickets)
+	file = filepath.Join(dir, "nmf.model")
+	if err := nmf.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	var loadedNMF NMF
+	if err := loadedNMF.Load(file); err != nil {
+		t.Fatal(err)
+	}
+	if a, b := Label(nmf, "refund my invoice", 3), Label(&loadedNMF, "refund my invoice", 3); a != b {
+		t.Errorf("loaded NMF labels %q, want %q", b, a)
+	}
+}
@@ -0,0 +1,299 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+// Package vectorizer turns texts into sparse vectors with a fixed feature
+// index, the input of the vector classifiers. CountVectorizer learns a
+// vocabulary, pruned by document frequency, and counts its terms,
+// TfidfTransformer reweighs counts by TF-IDF and HashingVectorizer hashes
+// terms into a fixed number of features without any vocabulary.
+package vectorizer
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"github.com/broosaction/gotext/tokenizers"
+	"github.com/broosaction/gotext/utils/persist"
+	"github.com/broosaction/gotext/utils/sparse"
+	"log"
+	"sort"
+	"strings"
+)
+
+var (
+	errEmptyVocabulary = errors.New("no term is left in the vocabulary, lower MinDF or raise MaxDF")
+	errNoDocuments     = errors.New("there are no documents to fit")
+)
+
+// Vectorizer turns a text into a vector of Size features.
+type Vectorizer interface {
+	Transform(text string) sparse.Vector
+	Size() int
+}
+
+// Dense expands the vectors to rows of size values, for the classifiers taking [][]float64.
+func Dense(vectors []sparse.Vector, size int) [][]float64 {
+	rows := make([][]float64, len(vectors))
+	for i, v := range vectors {
+		rows[i] = v.Dense(size)
+	}
+	return rows
+}
+
+// TransformAll transforms every text.
+func TransformAll(v Vectorizer, texts []string) []sparse.Vector {
+	vectors := make([]sparse.Vector, len(texts))
+	for i, text := range texts {
+		vectors[i] = v.Transform(text)
+	}
+	return vectors
+}
+
+// TermOptions say how a text is cut into terms, shared by the vectorizers.
+// It is exported so it is saved along with them.
+type TermOptions struct {
+	// name of the tokenizer, see tokenizers.GetTokenizer
+	Tokenizer string
+
+	// lower case the text before tokenizing
+	Lowercase bool
+
+	// the shortest and longest n-grams, 1 and 1 for single words
+	MinN, MaxN int
+
+	// words dropped before making n-grams, see strings.GetStopwords
+	StopWords map[string]struct{}
+}
+
+func defaultTermOptions() TermOptions {
+	return TermOptions{
+		Tokenizer: tokenizers.DefaultTokenizerName,
+		Lowercase: true,
+		MinN:      1,
+		MaxN:      1,
+	}
+}
+
+// Terms returns the n-grams of the text, in order and with repeats.
+func (o TermOptions) Terms(text string) []string {
+	if o.Lowercase {
+		text = strings.ToLower(text)
+	}
+	var tokens []string
+	for _, token := range tokenizers.GetTokenizer(o.Tokenizer).Tokenize(text) {
+		if _, ok := o.StopWords[token]; !ok {
+			tokens = append(tokens, token)
+		}
+	}
+	minN, maxN := o.MinN, o.MaxN
+	if minN < 1 {
+		minN = 1
+	}
+	if maxN < minN {
+		maxN = minN
+	}
+	var terms []string
+	for n := minN; n <= maxN; n++ {
+		for i := 0; i+n <= len(tokens); i++ {
+			terms = append(terms, strings.Join(tokens[i:i+n], " "))
+		}
+	}
+	return terms
+}
+
+/**
+ * Count Vectorizer
+ *
+ * Learns a vocabulary from a collection of texts and turns every text into
+ * the counts of the vocabulary terms it has. Terms in too few documents
+ * (typos, names) or too many (stop words) can be pruned, and the vocabulary
+ * capped to the most frequent terms. The features are the terms sorted, so
+ * the same texts always give the same index.
+ *
+ * @category    Machine Learning
+
+  **usage
+	cv := vectorizer.NewCountVectorizer()
+	cv.MinDF = 2
+	cv.MaxDF = 0.9
+	vectors, err := cv.FitTransform(texts)
+	knn.LearnBatch(vectorizer.Dense(vectors, cv.Size()), labels)
+*/
+type CountVectorizer struct {
+	TermOptions
+
+	// Terms in fewer documents are dropped, 1 keeps them all.
+	MinDF int
+
+	// Terms in a larger share of the documents are dropped, 1 keeps them all.
+	MaxDF float64
+
+	// Keep only the MaxFeatures terms with the highest counts, 0 keeps them all.
+	MaxFeatures int
+
+	// Count a term once however often it occurs.
+	Binary bool
+
+	// Feature index of every term.
+	Vocabulary map[string]int
+
+	// The number of fitted documents having each feature, and the number of fitted documents.
+	DocFreqs  []int
+	Documents int
+}
+
+func NewCountVectorizer() *CountVectorizer {
+	return &CountVectorizer{
+		TermOptions: defaultTermOptions(),
+		MinDF:       1,
+		MaxDF:       1,
+	}
+}
+
+func (v *CountVectorizer) getMeta() (string, string) {
+	return "CountVectorizer", "01"
+}
+
+// Size is the number of features, the vocabulary size.
+func (v *CountVectorizer) Size() int {
+	return len(v.Vocabulary)
+}
+
+// FeatureNames returns the term of every feature, by index.
+func (v *CountVectorizer) FeatureNames() []string {
+	names := make([]string, len(v.Vocabulary))
+	for term, i := range v.Vocabulary {
+		names[i] = term
+	}
+	return names
+}
+
+// Fit learns the vocabulary of the texts, replacing the one learned before.
+func (v *CountVectorizer) Fit(texts []string) error {
+	df := make(map[string]int)
+	total := make(map[string]int)
+	for _, text := range texts {
+		seen := make(map[string]bool)
+		for _, term := range v.Terms(text) {
+			total[term]++
+			if !seen[term] {
+				seen[term] = true
+				df[term]++
+			}
+		}
+	}
+
+	maxDF := v.MaxDF
+	if maxDF <= 0 || maxDF > 1 {
+		maxDF = 1
+	}
+	var kept []string
+	for term, n := range df {
+		if n >= v.MinDF && float64(n) <= maxDF*float64(len(texts)) {
+			kept = append(kept, term)
+		}
+	}
+	if len(kept) == 0 {
+		return errEmptyVocabulary
+	}
+	if v.MaxFeatures > 0 && len(kept) > v.MaxFeatures {
+		sort.Slice(kept, func(i, j int) bool {
+			if total[kept[i]] != total[kept[j]] {
+				return total[kept[i]] > total[kept[j]]
+			}
+			return kept[i] < kept[j]
+		})
+		kept = kept[:v.MaxFeatures]
+	}
+	sort.Strings(kept)
+
+	v.Vocabulary = make(map[string]int, len(kept))
+	v.DocFreqs = make([]int, len(kept))
+	for i, term := range kept {
+		v.Vocabulary[term] = i
+		v.DocFreqs[i] = df[term]
+	}
+	v.Documents = len(texts)
+	return nil
+}
+
+// Transform counts the vocabulary terms of the text, unknown terms are dropped.
+func (v *CountVectorizer) Transform(text string) sparse.Vector {
+	counts := make(map[int]float64)
+	for _, term := range v.Terms(text) {
+		if i, ok := v.Vocabulary[term]; ok {
+			if v.Binary {
+				counts[i] = 1
+			} else {
+				counts[i]++
+			}
+		}
+	}
+	return sparse.New(counts)
+}
+
+// FitTransform fits the texts and transforms them.
+func (v *CountVectorizer) FitTransform(texts []string) ([]sparse.Vector, error) {
+	if err := v.Fit(texts); err != nil {
+		return nil, err
+	}
+	return TransformAll(v, texts), nil
+}
+
+//save to a file
+func (v *CountVectorizer) Save(file string) error {
+	name, version := v.getMeta()
+	return save(file, name, version, v)
+}
+
+// Load from the output file.
+func (v *CountVectorizer) Load(filePath string) error {
+	name, version := v.getMeta()
+	// decode into a fresh one, gob leaves the fields saved as zero values untouched
+	var loaded CountVectorizer
+	if err := load(filePath, name, version, &loaded); err != nil {
+		return err
+	}
+	*v = loaded
+	return nil
+}
+
+func save(file, name, version string, model interface{}) error {
+	buf := new(bytes.Buffer)
+	encoder := gob.NewEncoder(buf)
+
+	err := encoder.Encode(model)
+	if err != nil {
+		return fmt.Errorf("error encoding model: %s", err)
+	}
+
+	persist.Save(file, persist.Modeldata{
+		Data:    buf.Bytes(),
+		Name:    name,
+		Version: version,
+	})
+	return nil
+}
+
+func load(filePath, name, version string, model interface{}) error {
+	log.Printf("Loading Vectorizer from %s...", filePath)
+	meta := persist.Load(filePath)
+	if meta.Name != name {
+		return fmt.Errorf("This file doesn't contain a %s", name)
+	}
+	if meta.Version != version {
+		return fmt.Errorf("Can't understand this file format")
+	}
+
+	decoder := gob.NewDecoder(bytes.NewBuffer(meta.Data))
+	err := decoder.Decode(model)
+	if err != nil {
+		return fmt.Errorf("error decoding checkpoint file: %s", err)
+	}
+	return nil
+}
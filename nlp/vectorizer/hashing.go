@@ -0,0 +1,115 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package vectorizer
+
+import (
+	"github.com/broosaction/gotext/utils/sparse"
+	"hash/fnv"
+)
+
+/**
+ * Hashing Vectorizer
+ *
+ * Counts the terms of a text into 2^Bits features picked by hashing the
+ * term, so it needs no fitting and no vocabulary: new terms just work and
+ * memory stays fixed however large the collection. Different terms may share
+ * a feature, with AlternateSign half the terms count negatively so those
+ * collisions tend to cancel out. Features can't be mapped back to terms.
+ *
+ * @category    Machine Learning
+
+  **usage
+	hv := vectorizer.NewHashingVectorizer()
+	hv.Bits = 18
+	x := hv.Transform("will it rain tomorrow")
+*/
+type HashingVectorizer struct {
+	TermOptions
+
+	// The number of features is 2^Bits.
+	Bits int
+
+	// Give every term a sign from its hash.
+	AlternateSign bool
+
+	// Count a term once however often it occurs.
+	Binary bool
+
+	// Scale every vector to unit length (L2).
+	Norm bool
+}
+
+func NewHashingVectorizer() *HashingVectorizer {
+	return &HashingVectorizer{
+		TermOptions:   defaultTermOptions(),
+		Bits:          20,
+		AlternateSign: true,
+		Norm:          true,
+	}
+}
+
+func (v *HashingVectorizer) getMeta() (string, string) {
+	return "HashingVectorizer", "01"
+}
+
+// Size is the number of features, 2^Bits.
+func (v *HashingVectorizer) Size() int {
+	return 1 << uint(v.bits())
+}
+
+func (v *HashingVectorizer) bits() int {
+	if v.Bits <= 0 || v.Bits > 30 {
+		return 20
+	}
+	return v.Bits
+}
+
+// Transform counts the hashed terms of the text.
+func (v *HashingVectorizer) Transform(text string) sparse.Vector {
+	mask := uint32(v.Size() - 1)
+	counts := make(map[int]float64)
+	for _, term := range v.Terms(text) {
+		h := fnv.New32a()
+		h.Write([]byte(term))
+		sum := h.Sum32()
+		value := 1.0
+		// the top bit is never part of the index, bits is at most 30
+		if v.AlternateSign && sum>>31 == 1 {
+			value = -1
+		}
+		i := int(sum & mask)
+		if v.Binary {
+			counts[i] = value
+		} else {
+			counts[i] += value
+		}
+	}
+	out := sparse.New(counts)
+	if v.Norm {
+		out = out.Normalize()
+	}
+	return out
+}
+
+//save to a file
+func (v *HashingVectorizer) Save(file string) error {
+	name, version := v.getMeta()
+	return save(file, name, version, v)
+}
+
+// Load from the output file.
+func (v *HashingVectorizer) Load(filePath string) error {
+	name, version := v.getMeta()
+	// decode into a fresh one, gob leaves the fields saved as zero values untouched
+	var loaded HashingVectorizer
+	if err := load(filePath, name, version, &loaded); err != nil {
+		return err
+	}
+	*v = loaded
+	return nil
+}
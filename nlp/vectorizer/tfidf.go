@@ -0,0 +1,182 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package vectorizer
+
+import (
+	"github.com/broosaction/gotext/utils/sparse"
+	"math"
+)
+
+/**
+ * TF-IDF Transformer
+ *
+ * Reweighs term counts so terms frequent in a text but rare in the
+ * collection weigh most:
+ *
+ *	tfidf(t, d) = tf(t, d) * idf(t)
+ *	tf(t, d)    = count(t, d), or 1 + ln(count(t, d)) with SublinearTF
+ *	idf(t)      = ln(n / df(t)) + 1, or ln((1 + n) / (1 + df(t))) + 1 with SmoothIDF
+ *
+ * where n is the number of documents and df(t) the number having t. The
+ * smoothing acts as if one extra document had every term, so no idf is
+ * infinite. The vectors are then scaled to unit length with Norm.
+ */
+type TfidfTransformer struct {
+	// Multiply by the idf, without it only tf and the norm apply.
+	UseIDF bool
+
+	// Add one to the document frequencies, as if one document had every term.
+	SmoothIDF bool
+
+	// Dampen repeated terms with 1 + ln(tf).
+	SublinearTF bool
+
+	// Scale every vector to unit length (L2).
+	Norm bool
+
+	// The idf of every feature.
+	IDF []float64
+}
+
+func NewTfidfTransformer() *TfidfTransformer {
+	return &TfidfTransformer{
+		UseIDF:    true,
+		SmoothIDF: true,
+		Norm:      true,
+	}
+}
+
+// FitDocFreqs computes the idf from the number of documents having each feature among n documents.
+func (t *TfidfTransformer) FitDocFreqs(docFreqs []int, n int) error {
+	if n == 0 {
+		return errNoDocuments
+	}
+	t.IDF = make([]float64, len(docFreqs))
+	for i, df := range docFreqs {
+		if t.SmoothIDF {
+			t.IDF[i] = math.Log(float64(1+n)/float64(1+df)) + 1
+		} else {
+			t.IDF[i] = math.Log(float64(n)/float64(df)) + 1
+		}
+	}
+	return nil
+}
+
+// Fit computes the idf of size features from count vectors.
+func (t *TfidfTransformer) Fit(counts []sparse.Vector, size int) error {
+	docFreqs := make([]int, size)
+	for _, v := range counts {
+		for k, i := range v.Indices {
+			if i < size && v.Values[k] != 0 {
+				docFreqs[i]++
+			}
+		}
+	}
+	return t.FitDocFreqs(docFreqs, len(counts))
+}
+
+// Transform reweighs a count vector, features without an idf keep their tf.
+func (t *TfidfTransformer) Transform(counts sparse.Vector) sparse.Vector {
+	out := sparse.Vector{Indices: counts.Indices, Values: make([]float64, len(counts.Values))}
+	for k, i := range counts.Indices {
+		tf := counts.Values[k]
+		if t.SublinearTF && tf > 0 {
+			tf = 1 + math.Log(tf)
+		}
+		if t.UseIDF && i < len(t.IDF) {
+			tf *= t.IDF[i]
+		}
+		out.Values[k] = tf
+	}
+	if t.Norm {
+		out = out.Normalize()
+	}
+	return out
+}
+
+/**
+ * TF-IDF Vectorizer
+ *
+ * A CountVectorizer followed by a TfidfTransformer, the usual way to feed
+ * texts to a vector classifier.
+ *
+ * @category    Machine Learning
+
+  **usage
+	tv := vectorizer.NewTfidfVectorizer()
+	tv.Counts.MaxN = 2
+	tv.Tfidf.SublinearTF = true
+	vectors, err := tv.FitTransform(texts)
+	tv.Save("tfidf.model")
+*/
+type TfidfVectorizer struct {
+	Counts *CountVectorizer
+	Tfidf  *TfidfTransformer
+}
+
+func NewTfidfVectorizer() *TfidfVectorizer {
+	return &TfidfVectorizer{
+		Counts: NewCountVectorizer(),
+		Tfidf:  NewTfidfTransformer(),
+	}
+}
+
+func (v *TfidfVectorizer) getMeta() (string, string) {
+	return "TfidfVectorizer", "01"
+}
+
+// Size is the number of features, the vocabulary size.
+func (v *TfidfVectorizer) Size() int {
+	return v.Counts.Size()
+}
+
+// Fit learns the vocabulary and the idf of the texts.
+func (v *TfidfVectorizer) Fit(texts []string) error {
+	if err := v.Counts.Fit(texts); err != nil {
+		return err
+	}
+	return v.Tfidf.FitDocFreqs(v.Counts.DocFreqs, v.Counts.Documents)
+}
+
+// Transform returns the TF-IDF vector of the text.
+func (v *TfidfVectorizer) Transform(text string) sparse.Vector {
+	return v.Tfidf.Transform(v.Counts.Transform(text))
+}
+
+// FitTransform fits the texts and transforms them.
+func (v *TfidfVectorizer) FitTransform(texts []string) ([]sparse.Vector, error) {
+	if err := v.Fit(texts); err != nil {
+		return nil, err
+	}
+	return TransformAll(v, texts), nil
+}
+
+//save to a file
+func (v *TfidfVectorizer) Save(file string) error {
+	name, version := v.getMeta()
+	return save(file, name, version, v)
+}
+
+// Load from the output file.
+func (v *TfidfVectorizer) Load(filePath string) error {
+	name, version := v.getMeta()
+	// decode into a fresh one, gob leaves the fields saved as zero values untouched
+	var loaded TfidfVectorizer
+	if err := load(filePath, name, version, &loaded); err != nil {
+		return err
+	}
+	// gob drops parts saved with only zero values
+	if loaded.Counts == nil {
+		loaded.Counts = &CountVectorizer{}
+	}
+	if loaded.Tfidf == nil {
+		loaded.Tfidf = &TfidfTransformer{}
+	}
+	*v = loaded
+	return nil
+}
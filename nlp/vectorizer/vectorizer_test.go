@@ -0,0 +1,170 @@
+package vectorizer
+
+import (
+	"github.com/broosaction/gotext/classifiers"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+var docs = []string{
+	"the cat sat on the mat",
+	"the dog sat on the log",
+	"the cat chased the dog",
+	"a bird sang",
+}
+
+func TestCountVectorizer(t *testing.T) {
+	cv := NewCountVectorizer()
+	if err := cv.Fit(docs); err != nil {
+		t.Fatal(err)
+	}
+	names := cv.FeatureNames()
+	for i := 1; i < len(names); i++ {
+		if names[i-1] >= names[i] {
+			t.Fatalf("features not sorted: %v", names)
+		}
+	}
+	x := cv.Transform("The cat and the other cat")
+	if x.Get(cv.Vocabulary["cat"]) != 2 || x.Get(cv.Vocabulary["the"]) != 2 || x.Len() != 2 {
+		t.Errorf("counts %+v", x)
+	}
+	if cv.DocFreqs[cv.Vocabulary["the"]] != 3 || cv.Documents != 4 {
+		t.Error("wrong document frequencies")
+	}
+
+	cv.MinDF = 2
+	cv.MaxDF = 0.5
+	cv.Fit(docs)
+	for _, term := range cv.FeatureNames() {
+		if df := cv.DocFreqs[cv.Vocabulary[term]]; df < 2 || df > 2 {
+			t.Errorf("%s is in %d documents", term, df)
+		}
+	}
+	if _, ok := cv.Vocabulary["the"]; ok {
+		t.Error("the should be pruned by MaxDF")
+	}
+
+	cv = NewCountVectorizer()
+	cv.MaxFeatures = 2
+	cv.Fit(docs)
+	if cv.Size() != 2 || cv.Vocabulary["the"] != 1 {
+		t.Errorf("vocabulary %v", cv.Vocabulary)
+	}
+	cv.MinDF = 10
+	if err := cv.Fit(docs); err != errEmptyVocabulary {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestTfidfVectorizer(t *testing.T) {
+	tv := NewTfidfVectorizer()
+	tv.Tfidf.Norm = false
+	if err := tv.Fit(docs); err != nil {
+		t.Fatal(err)
+	}
+	x := tv.Transform("cat cat bird")
+	cat, bird := tv.Counts.Vocabulary["cat"], tv.Counts.Vocabulary["bird"]
+	if want := 2 * (math.Log(5.0/3) + 1); math.Abs(x.Get(cat)-want) > 1e-9 {
+		t.Errorf("cat %f, want %f", x.Get(cat), want)
+	}
+	if want := math.Log(5.0/2) + 1; math.Abs(x.Get(bird)-want) > 1e-9 {
+		t.Errorf("bird %f, want %f", x.Get(bird), want)
+	}
+
+	tv.Tfidf.SublinearTF = true
+	tv.Tfidf.SmoothIDF = false
+	tv.Tfidf.Norm = true
+	tv.Fit(docs)
+	x = tv.Transform("cat cat bird")
+	if math.Abs(x.Norm()-1) > 1e-9 {
+		t.Errorf("norm %f", x.Norm())
+	}
+	ratio := x.Get(cat) / x.Get(bird)
+	if want := (1 + math.Log(2)) * (math.Log(2) + 1) / (math.Log(4) + 1); math.Abs(ratio-want) > 1e-9 {
+		t.Errorf("ratio %f, want %f", ratio, want)
+	}
+
+	file := filepath.Join(t.TempDir(), "tfidf.model")
+	if err := tv.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewTfidfVectorizer()
+	if err := loaded.Load(file); err != nil {
+		t.Fatal(err)
+	}
+	if y := loaded.Transform("cat cat bird"); math.Abs(y.DotVector(x)-1) > 1e-9 || loaded.Tfidf.SmoothIDF {
+		t.Errorf("the loaded vectorizer differs: %+v", loaded.Tfidf)
+	}
+}
+
+func TestHashingVectorizer(t *testing.T) {
+	hv := NewHashingVectorizer()
+	hv.Bits = 8
+	hv.Norm = false
+	hv.AlternateSign = false
+	x := hv.Transform("rain rain today")
+	if hv.Size() != 256 || x.MaxIndex() >= 256 || x.Len() > 2 {
+		t.Fatalf("vector %+v", x)
+	}
+	var sum float64
+	for _, v := range x.Values {
+		sum += v
+	}
+	if sum != 3 {
+		t.Errorf("the counts sum to %f", sum)
+	}
+	hv.Norm = true
+	hv.AlternateSign = true
+	if y := hv.Transform("Rain rain today"); math.Abs(y.Norm()-1) > 1e-9 {
+		t.Errorf("norm %f", y.Norm())
+	}
+
+	file := filepath.Join(t.TempDir(), "hashing.model")
+	hv.Save(file)
+	loaded := NewHashingVectorizer()
+	if err := loaded.Load(file); err != nil || loaded.Bits != 8 {
+		t.Errorf("loaded %+v, %v", loaded, err)
+	}
+}
+
+func TestVectorsFeedClassifiers(t *testing.T) {
+	texts := []string{
+		"will it rain tomorrow", "is it sunny today", "weather forecast please",
+		"play some music", "play my song", "turn the music up",
+	}
+	labels := []string{"weather", "weather", "weather", "music", "music", "music"}
+	tv := NewTfidfVectorizer()
+	vectors, err := tv.FitTransform(texts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	knn := classifiers.NewKNearestNeighbors(1, classifiers.DMT_CosineMethod, nil)
+	if err := knn.Fit(Dense(vectors, tv.Size()), labels); err != nil {
+		t.Fatal(err)
+	}
+	got, err := knn.Predict(Dense(TransformAll(tv, []string{"rain forecast", "some loud music"}), tv.Size()))
+	if err != nil || got[0] != "weather" || got[1] != "music" {
+		t.Errorf("knn got %v, %v", got, err)
+	}
+
+	lr := classifiers.NewLogisticRegression()
+	for i, x := range vectors {
+		lr.LearnVector(x, labels[i])
+	}
+	if err := lr.Train(); err != nil {
+		t.Fatal(err)
+	}
+	if class, _ := lr.ClassifyVector(tv.Transform("play a song")); class != "music" {
+		t.Errorf("logistic regression got %s", class)
+	}
+	svm := classifiers.NewLinearSVM()
+	for i, x := range vectors {
+		svm.LearnVector(x, labels[i])
+	}
+	svm.Train()
+	if class, _ := svm.ClassifyVector(tv.Transform("sunny tomorrow")); class != "weather" {
+		t.Errorf("svm got %s", class)
+	}
+}
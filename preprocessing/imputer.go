@@ -0,0 +1,113 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package preprocessing
+
+import (
+	"math"
+	"sort"
+)
+
+type ImputeStrategy uint8
+
+const (
+	// the mean of the known values of the column
+	IS_Mean ImputeStrategy = iota
+	// the median of the known values of the column
+	IS_Median
+	// the value that occurs most often in the column
+	IS_MostFrequent
+	// always FillValue
+	IS_Constant
+)
+
+// Missing is how a missing value is written in a sample.
+var Missing = math.NaN()
+
+func isMissing(v float64) bool {
+	return math.IsNaN(v)
+}
+
+/**
+ * Imputer
+ *
+ * Replaces missing values, written as Missing (NaN), by a statistic of the
+ * column learned while fitting. It should come first in a pipeline since the
+ * other transformers skip missing values when fitting but not when transforming.
+ */
+type Imputer struct {
+	Strategy ImputeStrategy
+
+	// value used by IS_Constant, and for columns without a single known value
+	FillValue float64
+
+	// the replacement of every column
+	Statistics []float64
+}
+
+func NewImputer(strategy ImputeStrategy) *Imputer {
+	return &Imputer{Strategy: strategy}
+}
+
+func (m *Imputer) Fit(samples [][]float64) error {
+	dim, err := columns(samples)
+	if err != nil {
+		return err
+	}
+	m.Statistics = make([]float64, dim)
+	for c := 0; c < dim; c++ {
+		values := column(samples, c)
+		if len(values) == 0 || m.Strategy == IS_Constant {
+			m.Statistics[c] = m.FillValue
+			continue
+		}
+		switch m.Strategy {
+		case IS_Median:
+			sort.Float64s(values)
+			m.Statistics[c] = quantile(values, 50)
+		case IS_MostFrequent:
+			m.Statistics[c] = mostFrequent(values)
+		default:
+			var sum float64
+			for _, v := range values {
+				sum += v
+			}
+			m.Statistics[c] = sum / float64(len(values))
+		}
+	}
+	return nil
+}
+
+func (m *Imputer) Transform(samples [][]float64) ([][]float64, error) {
+	if err := checkColumns(samples, len(m.Statistics)); err != nil {
+		return nil, err
+	}
+	out := copySamples(samples)
+	for _, row := range out {
+		for c, v := range row {
+			if isMissing(v) {
+				row[c] = m.Statistics[c]
+			}
+		}
+	}
+	return out, nil
+}
+
+// mostFrequent value, the smallest one on ties so the result doesn't depend on map order.
+func mostFrequent(values []float64) float64 {
+	counts := make(map[float64]int)
+	for _, v := range values {
+		counts[v]++
+	}
+	best, bestCount := 0.0, 0
+	for v, n := range counts {
+		if n > bestCount || (n == bestCount && v < best) {
+			best, bestCount = v, n
+		}
+	}
+	return best
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package preprocessing
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+var (
+	errUnknownCategory = errors.New("the category was not seen while fitting")
+)
+
+/**
+ * One-Hot Encoder
+ *
+ * Replaces categorical columns, holding category codes such as 0 = red,
+ * 1 = green, 2 = blue, by one indicator column per category. Distances
+ * between codes are meaningless, between indicators they are not. The other
+ * columns are passed through in their original order.
+ */
+type OneHotEncoder struct {
+	// positions of the categorical columns
+	Columns []int
+
+	// return an error for categories that weren't seen while fitting,
+	// instead of leaving all of their indicators at 0
+	ErrorOnUnknown bool
+
+	// the sorted categories of every column that is encoded, by column
+	Categories map[int][]float64
+
+	// number of input columns seen while fitting
+	Dim int
+}
+
+func NewOneHotEncoder(columns ...int) *OneHotEncoder {
+	return &OneHotEncoder{Columns: columns}
+}
+
+func (e *OneHotEncoder) Fit(samples [][]float64) error {
+	dim, err := columns(samples)
+	if err != nil {
+		return err
+	}
+	e.Dim = dim
+	e.Categories = make(map[int][]float64, len(e.Columns))
+	for _, c := range e.Columns {
+		if c < 0 || c >= dim {
+			return fmt.Errorf("column %d doesn't exist in %d columns: %w", c, dim, errDimension)
+		}
+		seen := make(map[float64]struct{})
+		for _, v := range column(samples, c) {
+			seen[v] = struct{}{}
+		}
+		categories := make([]float64, 0, len(seen))
+		for v := range seen {
+			categories = append(categories, v)
+		}
+		sort.Float64s(categories)
+		e.Categories[c] = categories
+	}
+	return nil
+}
+
+func (e *OneHotEncoder) Transform(samples [][]float64) ([][]float64, error) {
+	if err := checkColumns(samples, e.Dim); err != nil {
+		return nil, err
+	}
+	width := e.Dim
+	for _, categories := range e.Categories {
+		width += len(categories) - 1
+	}
+
+	out := make([][]float64, len(samples))
+	for i, s := range samples {
+		row := make([]float64, 0, width)
+		for c, v := range s {
+			categories, ok := e.Categories[c]
+			if !ok {
+				row = append(row, v)
+				continue
+			}
+			at := sort.SearchFloat64s(categories, v)
+			if (at == len(categories) || categories[at] != v) && e.ErrorOnUnknown {
+				return nil, fmt.Errorf("sample %d, column %d, value %v: %w", i, c, v, errUnknownCategory)
+			}
+			for _, category := range categories {
+				if category == v {
+					row = append(row, 1)
+				} else {
+					row = append(row, 0)
+				}
+			}
+		}
+		out[i] = row
+	}
+	return out, nil
+}
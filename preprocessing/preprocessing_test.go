@@ -0,0 +1,119 @@
+package preprocessing
+
+import (
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestScalers(t *testing.T) {
+	samples := [][]float64{{1, 10}, {2, 10}, {3, 10}, {100, 10}}
+
+	standard := NewStandardScaler()
+	standard.Fit(samples)
+	out, _ := standard.Transform(samples)
+	var mean float64
+	for _, row := range out {
+		mean += row[0] / 4
+		if row[1] != 0 {
+			t.Errorf("a constant column should become 0, got %v", row[1])
+		}
+	}
+	if math.Abs(mean) > 1e-9 {
+		t.Errorf("standard scaled mean is %v", mean)
+	}
+
+	minmax := NewMinMaxScaler()
+	minmax.Fit(samples)
+	out, _ = minmax.Transform(samples)
+	if out[0][0] != 0 || out[3][0] != 1 {
+		t.Errorf("min-max scaled to %v", out)
+	}
+
+	robust := NewRobustScaler()
+	robust.Fit(samples)
+	out, _ = robust.Transform([][]float64{{2.5, 10}})
+	if out[0][0] != 0 {
+		t.Errorf("the median should scale to 0, got %v", out[0][0])
+	}
+	if samples[0][0] != 1 {
+		t.Error("transform changed its input")
+	}
+}
+
+func TestOneHotEncoder(t *testing.T) {
+	e := NewOneHotEncoder(1)
+	e.Fit([][]float64{{0.5, 2}, {1.5, 0}, {2.5, 1}})
+	out, err := e.Transform([][]float64{{7, 1}, {8, 5}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]float64{{7, 0, 1, 0}, {8, 0, 0, 0}}
+	if !reflect.DeepEqual(out, want) {
+		t.Errorf("got %v, want %v", out, want)
+	}
+
+	e.ErrorOnUnknown = true
+	if _, err := e.Transform([][]float64{{8, 5}}); err == nil {
+		t.Error("expected an error for an unknown category")
+	}
+}
+
+func TestImputer(t *testing.T) {
+	samples := [][]float64{{1, Missing}, {Missing, 4}, {3, 4}, {8, 5}}
+	for strategy, want := range map[ImputeStrategy][]float64{
+		IS_Mean:         {4, 13.0 / 3},
+		IS_Median:       {3, 4},
+		IS_MostFrequent: {1, 4},
+	} {
+		m := NewImputer(strategy)
+		m.Fit(samples)
+		out, _ := m.Transform(samples)
+		if out[1][0] != want[0] || out[0][1] != want[1] {
+			t.Errorf("strategy %d: got %v", strategy, out)
+		}
+	}
+}
+
+func TestPipelinePersistence(t *testing.T) {
+	p := NewPipeline(NewImputer(IS_Median), NewOneHotEncoder(0), NewStandardScaler())
+	samples := [][]float64{{0, 1.5}, {1, Missing}, {2, 3.5}, {1, 2}}
+	want, err := p.FitTransform(samples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(want[0]) != 4 {
+		t.Fatalf("expected 3 indicator columns and 1 numeric column, got %v", want[0])
+	}
+
+	file := filepath.Join(t.TempDir(), "pipeline.joi")
+	if err := p.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	loaded := &Pipeline{}
+	if err := loaded.Load(file); err != nil {
+		t.Fatal(err)
+	}
+	got, err := loaded.Transform(samples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("loaded pipeline gives %v, want %v", got, want)
+	}
+	if _, err := loaded.Transform([][]float64{{1}}); err == nil {
+		t.Error("expected a dimension error")
+	}
+
+	// an empty pipeline loaded over this one leaves nothing of it
+	if err := NewPipeline().Save(file); err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Load(file); err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Steps) != 0 {
+		t.Errorf("%d steps kept", len(loaded.Steps))
+	}
+}
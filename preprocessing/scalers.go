@@ -0,0 +1,186 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+package preprocessing
+
+import (
+	"math"
+	"sort"
+)
+
+/**
+ * Standard Scaler
+ *
+ * Centers every column on its mean and divides it by its standard deviation,
+ * so all features end up with mean 0 and variance 1 whatever their unit.
+ * Constant columns are only centered.
+ */
+type StandardScaler struct {
+	Mean  []float64
+	Scale []float64
+}
+
+func NewStandardScaler() *StandardScaler {
+	return &StandardScaler{}
+}
+
+func (s *StandardScaler) Fit(samples [][]float64) error {
+	dim, err := columns(samples)
+	if err != nil {
+		return err
+	}
+	s.Mean = make([]float64, dim)
+	s.Scale = make([]float64, dim)
+	for c := 0; c < dim; c++ {
+		values := column(samples, c)
+		var mean, variance float64
+		for _, v := range values {
+			mean += v
+		}
+		mean /= math.Max(1, float64(len(values)))
+		for _, v := range values {
+			variance += (v - mean) * (v - mean)
+		}
+		variance /= math.Max(1, float64(len(values)))
+
+		s.Mean[c] = mean
+		s.Scale[c] = nonZero(math.Sqrt(variance))
+	}
+	return nil
+}
+
+func (s *StandardScaler) Transform(samples [][]float64) ([][]float64, error) {
+	if err := checkColumns(samples, len(s.Mean)); err != nil {
+		return nil, err
+	}
+	out := copySamples(samples)
+	for _, row := range out {
+		for c := range row {
+			row[c] = (row[c] - s.Mean[c]) / s.Scale[c]
+		}
+	}
+	return out, nil
+}
+
+/**
+ * Min-Max Scaler
+ *
+ * Maps every column linearly onto [Min, Max], [0, 1] by default, using the
+ * smallest and largest value seen while fitting. Test values outside the
+ * fitted range end up outside [Min, Max].
+ */
+type MinMaxScaler struct {
+	Min, Max float64
+
+	DataMin []float64
+	DataMax []float64
+}
+
+func NewMinMaxScaler() *MinMaxScaler {
+	return &MinMaxScaler{Min: 0, Max: 1}
+}
+
+func (s *MinMaxScaler) Fit(samples [][]float64) error {
+	dim, err := columns(samples)
+	if err != nil {
+		return err
+	}
+	s.DataMin = make([]float64, dim)
+	s.DataMax = make([]float64, dim)
+	for c := 0; c < dim; c++ {
+		s.DataMin[c], s.DataMax[c] = math.Inf(1), math.Inf(-1)
+		for _, v := range column(samples, c) {
+			s.DataMin[c] = math.Min(s.DataMin[c], v)
+			s.DataMax[c] = math.Max(s.DataMax[c], v)
+		}
+		if math.IsInf(s.DataMin[c], 1) {
+			// nothing but missing values
+			s.DataMin[c], s.DataMax[c] = 0, 0
+		}
+	}
+	return nil
+}
+
+func (s *MinMaxScaler) Transform(samples [][]float64) ([][]float64, error) {
+	if err := checkColumns(samples, len(s.DataMin)); err != nil {
+		return nil, err
+	}
+	out := copySamples(samples)
+	for _, row := range out {
+		for c := range row {
+			unit := (row[c] - s.DataMin[c]) / nonZero(s.DataMax[c]-s.DataMin[c])
+			row[c] = s.Min + unit*(s.Max-s.Min)
+		}
+	}
+	return out, nil
+}
+
+/**
+ * Robust Scaler
+ *
+ * Centers every column on its median and divides it by its interquartile
+ * range. Unlike the standard scaler a few extreme values barely move it.
+ */
+type RobustScaler struct {
+	// quantiles of the range used as scale, 25 and 75 by default
+	LowQuantile, HighQuantile float64
+
+	Center []float64
+	Scale  []float64
+}
+
+func NewRobustScaler() *RobustScaler {
+	return &RobustScaler{LowQuantile: 25, HighQuantile: 75}
+}
+
+func (s *RobustScaler) Fit(samples [][]float64) error {
+	dim, err := columns(samples)
+	if err != nil {
+		return err
+	}
+	s.Center = make([]float64, dim)
+	s.Scale = make([]float64, dim)
+	for c := 0; c < dim; c++ {
+		values := column(samples, c)
+		sort.Float64s(values)
+		s.Center[c] = quantile(values, 50)
+		s.Scale[c] = nonZero(quantile(values, s.HighQuantile) - quantile(values, s.LowQuantile))
+	}
+	return nil
+}
+
+func (s *RobustScaler) Transform(samples [][]float64) ([][]float64, error) {
+	if err := checkColumns(samples, len(s.Center)); err != nil {
+		return nil, err
+	}
+	out := copySamples(samples)
+	for _, row := range out {
+		for c := range row {
+			row[c] = (row[c] - s.Center[c]) / s.Scale[c]
+		}
+	}
+	return out, nil
+}
+
+// quantile q (0 to 100) of sorted values, interpolating between the two closest ranks.
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := q / 100 * float64(len(sorted)-1)
+	lo := int(math.Floor(pos))
+	hi := int(math.Ceil(pos))
+	return sorted[lo] + (pos-float64(lo))*(sorted[hi]-sorted[lo])
+}
+
+// nonZero avoids dividing by the zero spread of a constant column.
+func nonZero(scale float64) float64 {
+	if scale == 0 {
+		return 1
+	}
+	return scale
+}
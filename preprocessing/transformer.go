@@ -0,0 +1,196 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+// Package preprocessing prepares numeric features before they reach a
+// classifier: scaling, encoding of categorical columns and filling in missing
+// values. Every transformer learns its parameters with Fit and applies them
+// with Transform, so that test data is transformed exactly like the training data.
+package preprocessing
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"github.com/broosaction/gotext/utils/persist"
+	"log"
+)
+
+var (
+	errNotFitted = errors.New("the transformer has not been fitted")
+	errNoSamples = errors.New("there are no samples to fit")
+	errDimension = errors.New("the sample dimension doesn't match the fitted data")
+)
+
+// Transformer learns how to transform samples and applies it.
+type Transformer interface {
+	// Fit learns the parameters of the transformation from the samples.
+	Fit(samples [][]float64) error
+
+	// Transform returns transformed copies of the samples, the input is left untouched.
+	Transform(samples [][]float64) ([][]float64, error)
+}
+
+func init() {
+	// the steps of a pipeline are encoded as interfaces
+	gob.Register(&StandardScaler{})
+	gob.Register(&MinMaxScaler{})
+	gob.Register(&RobustScaler{})
+	gob.Register(&OneHotEncoder{})
+	gob.Register(&Imputer{})
+	gob.Register(&Pipeline{})
+}
+
+/**
+ * Pipeline
+ *
+ * Chains transformers, every step is fitted on the output of the steps before it.
+
+  **usage
+	pipeline := preprocessing.NewPipeline(
+		preprocessing.NewImputer(preprocessing.IS_Median),
+		preprocessing.NewOneHotEncoder(3),
+		preprocessing.NewStandardScaler(),
+	)
+
+	knn := classifiers.NewKNearestNeighbors(5, classifiers.DMT_EulerMethod, nil)
+	knn.Preprocess = pipeline
+	knn.LearnBatch(train, labels)
+*/
+type Pipeline struct {
+	Steps []Transformer
+}
+
+func NewPipeline(steps ...Transformer) *Pipeline {
+	return &Pipeline{Steps: steps}
+}
+
+func (p *Pipeline) getMeta() (string, string) {
+	return "Pipeline", "01"
+}
+
+// Fit fits every step in turn.
+func (p *Pipeline) Fit(samples [][]float64) error {
+	_, err := p.FitTransform(samples)
+	return err
+}
+
+// FitTransform fits every step in turn and returns the transformed samples.
+func (p *Pipeline) FitTransform(samples [][]float64) ([][]float64, error) {
+	for i, step := range p.Steps {
+		if err := step.Fit(samples); err != nil {
+			return nil, fmt.Errorf("pipeline step %d: %w", i, err)
+		}
+		var err error
+		if samples, err = step.Transform(samples); err != nil {
+			return nil, fmt.Errorf("pipeline step %d: %w", i, err)
+		}
+	}
+	return samples, nil
+}
+
+// Transform runs the samples through every step.
+func (p *Pipeline) Transform(samples [][]float64) ([][]float64, error) {
+	for i, step := range p.Steps {
+		var err error
+		if samples, err = step.Transform(samples); err != nil {
+			return nil, fmt.Errorf("pipeline step %d: %w", i, err)
+		}
+	}
+	return samples, nil
+}
+
+//save to a file
+func (p *Pipeline) Save(file string) error {
+
+	buf := new(bytes.Buffer)
+	encoder := gob.NewEncoder(buf)
+
+	err := encoder.Encode(p)
+	if err != nil {
+		return fmt.Errorf("error encoding pipeline: %s", err)
+	}
+
+	name, version := p.getMeta()
+	persist.Save(file, persist.Modeldata{
+		Data:    buf.Bytes(),
+		Name:    name,
+		Version: version,
+	})
+	return nil
+}
+
+// Load from the output file.
+func (p *Pipeline) Load(filePath string) error {
+	log.Printf("Loading Pipeline from %s...", filePath)
+	meta := persist.Load(filePath)
+	//get the pipeline current meta data
+	name, version := p.getMeta()
+	if meta.Name != name {
+		return fmt.Errorf("This file doesn't contain a preprocessing Pipeline")
+	}
+	if meta.Version != version {
+		return fmt.Errorf("Can't understand this file format")
+	}
+
+	// decode into a fresh one, gob leaves the fields saved as zero values untouched
+	var loaded Pipeline
+	decoder := gob.NewDecoder(bytes.NewBuffer(meta.Data))
+	err := decoder.Decode(&loaded)
+	if err != nil {
+		return fmt.Errorf("error decoding pipeline file: %s", err)
+	}
+	*p = loaded
+	return nil
+}
+
+// copySamples returns a deep copy, transformers never write to their input.
+func copySamples(samples [][]float64) [][]float64 {
+	out := make([][]float64, len(samples))
+	for i, s := range samples {
+		out[i] = append([]float64(nil), s...)
+	}
+	return out
+}
+
+// columns checks that all samples have the same number of columns and returns it.
+func columns(samples [][]float64) (int, error) {
+	if len(samples) == 0 {
+		return 0, errNoSamples
+	}
+	dim := len(samples[0])
+	for i, s := range samples {
+		if len(s) != dim {
+			return 0, fmt.Errorf("sample %d has %d columns, sample 0 has %d: %w", i, len(s), dim, errDimension)
+		}
+	}
+	return dim, nil
+}
+
+// checkColumns makes sure samples have the dimension a transformer was fitted on.
+func checkColumns(samples [][]float64, fitted int) error {
+	if fitted == 0 {
+		return errNotFitted
+	}
+	for i, s := range samples {
+		if len(s) != fitted {
+			return fmt.Errorf("sample %d has %d columns, fitted on %d: %w", i, len(s), fitted, errDimension)
+		}
+	}
+	return nil
+}
+
+// column returns the non missing values of column c.
+func column(samples [][]float64, c int) []float64 {
+	values := make([]float64, 0, len(samples))
+	for _, s := range samples {
+		if !isMissing(s[c]) {
+			values = append(values, s[c])
+		}
+	}
+	return values
+}
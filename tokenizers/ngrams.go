@@ -8,7 +8,6 @@
 package tokenizers
 
 import (
-	"fmt"
 	stringUtils "github.com/broosaction/gotext/utils/strings"
 	"math"
 	"strings"
@@ -67,7 +66,6 @@ func (ng NGramTokenizer) Tokenize(text string) []string {
 				ngram = string(ngram) + separator + string(words[index + k])
 
 			}
-			fmt.Println(ngram)
 			nGrams = append(nGrams, ngram)
 		}
 	}
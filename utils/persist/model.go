@@ -11,8 +11,8 @@ import (
 	"archive/zip"
 	"bytes"
 	"fmt"
-	"github.com/broosaction/gotext/tokenizers"
 	"io/ioutil"
+	"strings"
 )
 
 
@@ -126,10 +126,8 @@ func Load(filename string) Modeldata {
           if file.Name == "model.joi"{
           	meta.Data = readAll(file)
 		  }else if file.Name == "meta.conf"{
-			  tokenizer := tokenizers.DefaultTokenizer{
-			  	RemoveStopWords: false,
-			  }
-			  str := tokenizer.Tokenize(string(readAll(file)))
+			  //one value per line, the format version itself contains a space
+			  str := strings.Split(string(readAll(file)), "\n")
 			  meta.FormatVersion = str[0]
 			  meta.Name          = str[1]
 			  meta.Version       = str[2]
@@ -0,0 +1,157 @@
+/*
+ * Copyright (c) 2021.  -present, Broos Action, Inc. All rights reserved.
+ *
+ *  This source code is licensed under the MIT license
+ *  found in the LICENSE file in the root directory of this source tree.
+ */
+
+// Package sparse holds feature vectors where nearly every value is 0, such as
+// the term counts of a document over a vocabulary of thousands of words. Only
+// the non zero values are stored, together with their feature index.
+package sparse
+
+import (
+	"math"
+	"sort"
+)
+
+// Vector is a sparse vector, Indices are sorted ascending and unique.
+type Vector struct {
+	Indices []int
+	Values  []float64
+}
+
+// New builds a vector from index to value pairs, zero values are dropped.
+func New(m map[int]float64) Vector {
+	v := Vector{
+		Indices: make([]int, 0, len(m)),
+		Values:  make([]float64, 0, len(m)),
+	}
+	for i, x := range m {
+		if x != 0 {
+			v.Indices = append(v.Indices, i)
+		}
+	}
+	sort.Ints(v.Indices)
+	for _, i := range v.Indices {
+		v.Values = append(v.Values, m[i])
+	}
+	return v
+}
+
+// FromDense keeps the non zero values of a dense vector.
+func FromDense(dense []float64) Vector {
+	var v Vector
+	for i, x := range dense {
+		if x != 0 {
+			v.Indices = append(v.Indices, i)
+			v.Values = append(v.Values, x)
+		}
+	}
+	return v
+}
+
+// Len is the number of stored values.
+func (v Vector) Len() int {
+	return len(v.Indices)
+}
+
+// Get returns the value at index i.
+func (v Vector) Get(i int) float64 {
+	at := sort.SearchInts(v.Indices, i)
+	if at < len(v.Indices) && v.Indices[at] == i {
+		return v.Values[at]
+	}
+	return 0
+}
+
+// Dense expands the vector to dim values, indices from dim on are dropped.
+func (v Vector) Dense(dim int) []float64 {
+	dense := make([]float64, dim)
+	for k, i := range v.Indices {
+		if i < dim {
+			dense[i] = v.Values[k]
+		}
+	}
+	return dense
+}
+
+// Dot product with a dense vector, indices beyond its length count as 0.
+func (v Vector) Dot(dense []float64) float64 {
+	var sum float64
+	for k, i := range v.Indices {
+		if i < len(dense) {
+			sum += v.Values[k] * dense[i]
+		}
+	}
+	return sum
+}
+
+// DotVector is the dot product of two sparse vectors.
+func (v Vector) DotVector(u Vector) float64 {
+	var sum float64
+	for a, b := 0, 0; a < len(v.Indices) && b < len(u.Indices); {
+		switch {
+		case v.Indices[a] == u.Indices[b]:
+			sum += v.Values[a] * u.Values[b]
+			a++
+			b++
+		case v.Indices[a] < u.Indices[b]:
+			a++
+		default:
+			b++
+		}
+	}
+	return sum
+}
+
+// AddTo adds scale * v to a dense vector, which must be long enough.
+func (v Vector) AddTo(dense []float64, scale float64) {
+	for k, i := range v.Indices {
+		dense[i] += scale * v.Values[k]
+	}
+}
+
+// Norm is the Euclidean length of the vector.
+func (v Vector) Norm() float64 {
+	var sum float64
+	for _, x := range v.Values {
+		sum += x * x
+	}
+	return math.Sqrt(sum)
+}
+
+// Scale returns a copy with every value multiplied by s.
+func (v Vector) Scale(s float64) Vector {
+	out := Vector{Indices: v.Indices, Values: make([]float64, len(v.Values))}
+	for k, x := range v.Values {
+		out.Values[k] = s * x
+	}
+	return out
+}
+
+// Normalize returns a copy of unit length, the zero vector stays as it is.
+func (v Vector) Normalize() Vector {
+	norm := v.Norm()
+	if norm == 0 {
+		return v
+	}
+	return v.Scale(1 / norm)
+}
+
+// Cosine similarity of two sparse vectors, 0 when either is the zero vector.
+func Cosine(a, b Vector) float64 {
+	na, nb := a.Norm(), b.Norm()
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return a.DotVector(b) / (na * nb)
+}
+
+// MaxIndex is the largest stored index, -1 for an empty vector.
+func (v Vector) MaxIndex() int {
+	if len(v.Indices) == 0 {
+		return -1
+	}
+	return v.Indices[len(v.Indices)-1]
+}
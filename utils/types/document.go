@@ -10,6 +10,7 @@ package types
 import (
 	"github.com/broosaction/gotext/tokenizers"
 	stringUtils "github.com/broosaction/gotext/utils/strings"
+	"strings"
 )
 
 type Document struct {
@@ -64,8 +65,61 @@ func (d *Document) computeWordFreq() {
 
 }
 
-func (d *Document) Learn() *Document{
+// PrepareSummary sums the document up in the sentences picked by the positions pick returns,
+// such as those of an extractive summarizer. The picked sentences are marked InSummary and
+// the Summary is their texts joined by a space, in text order,
+//
+//	doc.PrepareSummary(summarize.NewSummarizer().Pick)
+func (d *Document) PrepareSummary(pick func(sentences []string) []int) *Document{
+	texts := make([]string, len(d.Sentences))
+	for i, sentence := range d.Sentences {
+		texts[i] = sentence.Text
+		d.Sentences[i].InSummary = false
+	}
+	for _, i := range pick(texts) {
+		if i >= 0 && i < len(d.Sentences) {
+			d.Sentences[i].InSummary = true
+		}
+	}
+	var summary []string
+	for _, sentence := range d.Sentences {
+		if sentence.InSummary {
+			summary = append(summary, strings.TrimSpace(sentence.Text))
+		}
+	}
+	d.Summary = strings.Join(summary, " ")
+	return d
+}
+
+// PrepareMeaning sets the Meaning to the label of the document text, such as the top words
+// of its dominant topic in a fitted model,
+//
+//	doc.PrepareMeaning(func(text string) string { return topics.Label(lda, text, 3) })
+func (d *Document) PrepareMeaning(label func(text string) string) *Document{
+	d.Meaning = label(d.Text)
+	return d
+}
+
+// Lead picks the first n sentences, the summary of texts saying the most important first, such as news.
+func Lead(n int) func(sentences []string) []int {
+	return func(sentences []string) []int {
+		var picked []int
+		for i := 0; i < n && i < len(sentences); i++ {
+			picked = append(picked, i)
+		}
+		return picked
+	}
+}
+
+// Learn prepares the words, sentences and summary of the document. The summary is picked by
+// the summarizer given, such as summarize.NewSummarizer().Pick, or is the first sentence.
+func (d *Document) Learn(pick ...func(sentences []string) []int) *Document{
 	d.computeWordFreq()
 	d.PrepareSentences()
+	summarizer := Lead(1)
+	if len(pick) > 0 && pick[0] != nil {
+		summarizer = pick[0]
+	}
+	d.PrepareSummary(summarizer)
 	return d
 }
@@ -9,6 +9,8 @@ package types
 
 import (
 	"github.com/broosaction/gotext/tokenizers"
+	stringUtils "github.com/broosaction/gotext/utils/strings"
+	"strings"
 )
 
 type Sentence struct {
@@ -18,6 +20,8 @@ type Sentence struct {
 	Words    []Word   `json:"words"`
 	Tokens   []string  `json:"tokens"`
 	Tokenizer string  `json:"tokenizer"`
+	// picked for the summary of its document, see Document.PrepareSummary
+	InSummary bool    `json:"inSummary"`
 }
 
 func  NewSentence(text string) Sentence {
@@ -56,8 +60,29 @@ func (s *Sentence) PrepareMeaning() *Sentence{
 	return s
 }
 
-func (s *Sentence) PrepareSummary() *Sentence{
+// PrepareVectors sets the Vector of every word of the sentence from the embedding, see Word.PrepareVector.
+func (s *Sentence) PrepareVectors(embedding Embedding) *Sentence{
+	for i := range s.Words {
+		s.Words[i].PrepareVector(embedding)
+	}
+	return s
+}
 
+// PrepareSummary sets the Summary to the sentence compressed to its content words, in their
+// order, the stop words and punctuation left out. A sentence of stop words only is its own summary.
+func (s *Sentence) PrepareSummary() *Sentence{
+	var words []string
+	for _, token := range tokenizers.GetTokenizer(s.Tokenizer).Tokenize(s.Text) {
+		clean := stringUtils.Cleanup(token)
+		if strings.TrimSpace(clean) == "" || stringUtils.IsStopword(clean) || stringUtils.IsStopword(strings.ToLower(token)) {
+			continue
+		}
+		words = append(words, token)
+	}
+	s.Summary = strings.Join(words, " ")
+	if s.Summary == "" {
+		s.Summary = strings.TrimSpace(s.Text)
+	}
 	return s
 }
 
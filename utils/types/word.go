@@ -15,11 +15,16 @@ import (
 	"strings"
 )
 
+// Embedding looks up the vector of a word, such as embeddings.Embeddings loaded from a file.
+type Embedding interface {
+	Vector(word string) ([]float32, bool)
+}
+
 type Word struct {
 	Text     string   `json:"text"`
 	PosTag   string   `json:"pos"`
 	Stem     string   `json:"stem"`
-	Vector   float64  `json:"vector"`
+	Vector   []float32 `json:"vector"`
 	Meaning  string   `json:"meaning"`
 	Letters  []string `json:"letters"`
 	Synonyms []string `json:"synonyms"`
@@ -31,7 +36,7 @@ func  NewWord(text string) Word {
 		Text : 		text,
 		PosTag : 	"",
 		Stem : 		"",
-		Vector : 	0,
+		Vector : 	[]float32{},
 		Meaning : 	"",
 		Letters : 	[]string{},
 		Synonyms : 	[]string{},
@@ -66,6 +71,15 @@ func (w *Word) PrepareMeaning() *Word{
 	return w
 }
 
+// PrepareVector sets the Vector to the one of the word in the embedding, empty when it has none.
+func (w *Word) PrepareVector(embedding Embedding) *Word{
+	w.Vector = []float32{}
+	if v, ok := embedding.Vector(w.Text); ok {
+		w.Vector = v
+	}
+	return w
+}
+
 // Applies words with similer meaning
 func (w *Word) PrepareSynonyms() *Word{
 